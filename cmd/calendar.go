@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Adversis/tailsnitch/pkg/auditor"
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/output"
+)
+
+var calendarDays int
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Export upcoming node key and auth key expirations as an iCalendar (.ics) file",
+	Long: `Export one calendar event per node key or auth key that expires in the
+next --days days, or the --days days after --as-of. Each event names the
+device owner and tags, links to the admin console page to renew it, and has
+a one-day reminder.
+
+Event UIDs are stable, so a calendar subscribed to a regularly regenerated
+file updates events in place.`,
+	RunE: runCalendar,
+}
+
+func init() {
+	calendarCmd.Flags().IntVar(&calendarDays, "days", 90, "How many days ahead to include")
+	rootCmd.AddCommand(calendarCmd)
+}
+
+func runCalendar(cmd *cobra.Command, args []string) error {
+	if calendarDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	clock, err := auditClock()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := client.New(tailnet)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	from := clock.Now()
+	forecast, err := auditor.NewExpiryCollector(c).Collect(ctx, from, from.AddDate(0, 0, calendarDays))
+	if err != nil {
+		return fmt.Errorf("expiry collection failed: %w", err)
+	}
+
+	// DTSTAMP is when the file was created, even for an --as-of window
+	return output.ExpiryCalendarICS(os.Stdout, c.Tailnet(), forecast, time.Now())
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Adversis/tailsnitch/pkg/auditor"
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/output"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+var (
+	changesSince         string
+	changesFormat        string
+	changesPolicyEditors string
+)
+
+var changesCmd = &cobra.Command{
+	Use:   "changes",
+	Short: "Flag risky events in the configuration audit log",
+	Long: `Read the configuration audit log for a period and report the events that
+weaken a check: policy file edits by unexpected actors, device or user approval
+switched off, new reusable or pre-authorized auth keys, user role escalations and
+Tailnet Lock changes.
+
+Each row names the check the change affects and carries the CC7.2 control, so
+the export can be kept as change monitoring evidence without a SIEM.
+
+--since accepts a number of days or weeks (7d, 2w), a duration (36h) or a date
+(YYYY-MM-DD). Without --policy-editors every policy edit is reported for review.`,
+	RunE: runChanges,
+}
+
+func init() {
+	changesCmd.Flags().StringVar(&changesSince, "since", "7d", "How far back to read the audit log (7d, 2w, 36h or YYYY-MM-DD)")
+	changesCmd.Flags().StringVar(&changesFormat, "format", "csv", "Output format (csv or json)")
+	changesCmd.Flags().StringVar(&changesPolicyEditors, "policy-editors", "", "Login names or OAuth client IDs expected to edit the policy file (comma-separated)")
+	rootCmd.AddCommand(changesCmd)
+}
+
+func runChanges(cmd *cobra.Command, args []string) error {
+	if changesFormat != "json" && changesFormat != "csv" {
+		return fmt.Errorf("--format must be 'json' or 'csv'")
+	}
+
+	now := time.Now()
+	since, err := types.ParseLookback(changesSince, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+
+	var editors []string
+	for _, editor := range strings.Split(changesPolicyEditors, ",") {
+		if editor = strings.TrimSpace(editor); editor != "" {
+			editors = append(editors, editor)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := client.New(tailnet)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	report, err := auditor.NewChangeCollector(c, editors).Collect(ctx, since, now)
+	if err != nil {
+		return fmt.Errorf("change review failed: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d risky change(s) in %d audit log event(s) since %s\n",
+		len(report.Changes), report.EventCount, since.Format("2006-01-02 15:04"))
+
+	if changesFormat == "json" {
+		return output.ConfigChangesJSON(os.Stdout, report)
+	}
+	return output.ConfigChangesCSV(os.Stdout, report)
+}
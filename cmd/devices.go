@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Adversis/tailsnitch/pkg/auditor"
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/output"
+)
+
+var devicesFormat string
+
+var devicesCmd = &cobra.Command{
+	Use:   "devices",
+	Short: "Export a per-device inventory with failed checks and risk scores",
+	Long: `Export one row per device with owner, tags, OS, client version, last seen,
+key expiry, routes, exit node and external status, plus every device-level
+check that failed for it and a weighted risk score.
+
+Devices are sorted from highest to lowest risk.`,
+	RunE: runDevices,
+}
+
+func init() {
+	devicesCmd.Flags().StringVar(&devicesFormat, "format", "csv", "Output format (csv or json)")
+	rootCmd.AddCommand(devicesCmd)
+}
+
+func runDevices(cmd *cobra.Command, args []string) error {
+	if devicesFormat != "json" && devicesFormat != "csv" {
+		return fmt.Errorf("--format must be 'json' or 'csv'")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := auditor.SetVersionDBPath(versionDB); err != nil {
+		return fmt.Errorf("invalid --version-db: %w", err)
+	}
+
+	if _, err := loadLifecyclePolicy(); err != nil {
+		return err
+	}
+
+	if _, err := loadNamingPolicy(); err != nil {
+		return err
+	}
+
+	if _, err := loadPosturePolicy(); err != nil {
+		return err
+	}
+
+	clock, err := auditClock()
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(tailnet)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	report, err := auditor.NewDeviceInventoryCollector(c, clock).Collect(ctx)
+	if err != nil {
+		return fmt.Errorf("device inventory failed: %w", err)
+	}
+
+	for _, line := range report.PostureUnavailable {
+		fmt.Fprintf(os.Stderr, "Warning: posture unavailable for %s\n", line)
+	}
+
+	if devicesFormat == "json" {
+		return output.DeviceInventoryJSON(os.Stdout, report)
+	}
+	return output.DeviceInventoryCSV(os.Stdout, report)
+}
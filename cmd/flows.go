@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Adversis/tailsnitch/pkg/auditor"
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/output"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+var (
+	flowsSince  string
+	flowsFormat string
+	flowsFiles  []string
+)
+
+var flowsCmd = &cobra.Command{
+	Use:   "flows",
+	Short: "Find unused ACL rules and propose narrower ones from network flow logs",
+	Long: `Map network flow log traffic onto the policy's ACL rules and grants. Rules
+that saw no traffic in the window are marked unused, and rules broader than
+their traffic get a narrowed proposal naming the destination tags (or owners)
+and ports actually used.
+
+The default output is a policy diff: unused rules are removed and broad rules
+replaced. Review it before applying; a rule with no traffic in a short window
+may still be needed for monthly jobs or incident access.
+
+Without --file, flow logs for --since are read from the API, which needs
+network flow logging (LOG-001) turned on. With --file, exported logs are read
+instead (an API response, a JSON array, or one log per line as written by log
+streaming) and the window is taken from the logs. The policy and devices are
+always read from the API.`,
+	RunE: runFlows,
+}
+
+func init() {
+	flowsCmd.Flags().StringVar(&flowsSince, "since", "7d", "How far back to read flow logs from the API (7d, 2w, 36h or YYYY-MM-DD)")
+	flowsCmd.Flags().StringVar(&flowsFormat, "format", "diff", "Output format (diff, csv or json)")
+	flowsCmd.Flags().StringArrayVar(&flowsFiles, "file", nil, "Exported flow log file to read instead of the API (repeatable)")
+	rootCmd.AddCommand(flowsCmd)
+}
+
+func runFlows(cmd *cobra.Command, args []string) error {
+	if flowsFormat != "diff" && flowsFormat != "json" && flowsFormat != "csv" {
+		return fmt.Errorf("--format must be 'diff', 'json' or 'csv'")
+	}
+
+	now := time.Now()
+	since, err := types.ParseLookback(flowsSince, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := client.New(tailnet)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	collector := auditor.NewFlowCollector(c)
+	var report *types.FlowReport
+	if len(flowsFiles) > 0 {
+		logs, err := auditor.LoadFlowLogs(flowsFiles)
+		if err != nil {
+			return fmt.Errorf("failed to read flow logs: %w", err)
+		}
+		report, err = collector.Analyze(ctx, logs)
+		if err != nil {
+			return fmt.Errorf("flow analysis failed: %w", err)
+		}
+		report.Source = strings.Join(flowsFiles, ", ")
+	} else {
+		report, err = collector.Collect(ctx, since, now)
+		if err != nil {
+			return fmt.Errorf("flow analysis failed: %w", err)
+		}
+	}
+
+	used, unused, narrowed := report.Counts()
+	fmt.Fprintf(os.Stderr, "%d flow(s) from %d log(s): %d rule(s) used (%d can be narrowed), %d unused, %d flow(s) matched no rule\n",
+		report.FlowCount, report.LogCount, used, narrowed, unused, len(report.Unmatched))
+	if report.LogCount == 0 {
+		fmt.Fprintln(os.Stderr, "Warning: no flow logs found; check that network flow logging is turned on (LOG-001)")
+	}
+	if len(report.Unresolved) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: selectors not matched against flows: %s\n", strings.Join(report.Unresolved, ", "))
+	}
+
+	switch flowsFormat {
+	case "json":
+		return output.FlowReportJSON(os.Stdout, report)
+	case "csv":
+		return output.FlowReportCSV(os.Stdout, report)
+	}
+	return output.FlowPolicyDiff(os.Stdout, report)
+}
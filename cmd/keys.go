@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Adversis/tailsnitch/pkg/auditor"
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/output"
+)
+
+var keysFormat string
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Export an inventory of auth keys, API access tokens and OAuth clients",
+	Long: `Export one row per key with its type, scopes, tags, creator, creation and
+expiry dates and last use where the API reports it, plus every key-level check
+that failed for it and a weighted risk score.
+
+Creators are resolved through the users API; a creator that no longer exists
+in the tailnet is marked as removed. Keys are sorted from highest to lowest risk.`,
+	RunE: runKeys,
+}
+
+func init() {
+	keysCmd.Flags().StringVar(&keysFormat, "format", "csv", "Output format (csv or json)")
+	rootCmd.AddCommand(keysCmd)
+}
+
+func runKeys(cmd *cobra.Command, args []string) error {
+	if keysFormat != "json" && keysFormat != "csv" {
+		return fmt.Errorf("--format must be 'json' or 'csv'")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := loadLifecyclePolicy(); err != nil {
+		return err
+	}
+
+	clock, err := auditClock()
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(tailnet)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	report, err := auditor.NewKeyInventoryCollector(c, clock).Collect(ctx)
+	if err != nil {
+		return fmt.Errorf("key inventory failed: %w", err)
+	}
+
+	if report.UsersError != "" {
+		fmt.Fprintf(os.Stderr, "Warning: creators not resolved: %s\n", report.UsersError)
+	}
+	for _, key := range report.SkippedKeys {
+		fmt.Fprintf(os.Stderr, "Warning: skipped key %s\n", key)
+	}
+
+	if keysFormat == "json" {
+		return output.KeyInventoryJSON(os.Stdout, report)
+	}
+	return output.KeyInventoryCSV(os.Stdout, report)
+}
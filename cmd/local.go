@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Adversis/tailsnitch/pkg/auditor"
+	"github.com/Adversis/tailsnitch/pkg/output"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+var (
+	localJSON    bool
+	localVerbose bool
+	localSocket  string
+)
+
+var localCmd = &cobra.Command{
+	Use:   "local",
+	Short: "Audit this node's Tailscale settings via the local tailscaled",
+	Long: `Audit the node tailsnitch runs on by querying the local tailscaled over its
+LocalAPI socket. Checks cover accept-routes on servers, shields-up on user
+devices, the Tailscale SSH server, auto-update, exit node use, the operator
+user, and the Serve and Funnel endpoints actually in effect.
+
+No API key is needed. Run it on each server as a host-level companion to the
+tailnet-wide audit.`,
+	RunE: runLocal,
+}
+
+func init() {
+	localCmd.Flags().BoolVar(&localJSON, "json", false, "Output results as JSON")
+	localCmd.Flags().BoolVar(&localVerbose, "verbose", false, "Show passing checks too")
+	localCmd.Flags().StringVar(&localSocket, "socket", "", "Path to the tailscaled socket (default: platform default)")
+	rootCmd.AddCommand(localCmd)
+}
+
+func runLocal(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := loadNamingPolicy(); err != nil {
+		return err
+	}
+
+	report, err := auditor.NewLocalAuditor(localSocket).Run(ctx)
+	if err != nil {
+		return fmt.Errorf("local audit failed: %w", err)
+	}
+
+	if !localVerbose {
+		report.Suggestions = types.FilterFailed(report.Suggestions)
+		report.CalculateSummary()
+	}
+
+	if localJSON {
+		return output.JSON(os.Stdout, report)
+	}
+	output.PrintBanner(os.Stdout, report.Tailnet, Version, BuildID)
+	return output.Text(os.Stdout, report, localVerbose)
+}
@@ -36,9 +36,17 @@ var (
 	checks        string
 	listChecks    bool
 	soc2Format    string
+	sshAccess     string
 	tailscalePath string
+	versionDB     string
+	domains       string
 	ignoreFile    string
 	noIgnore      bool
+	lifecycleFile string
+	asOfDate      string
+	namingFile    string
+	postureFile   string
+	serveDirAudit string
 )
 
 var rootCmd = &cobra.Command{
@@ -61,7 +69,7 @@ func init() {
 	rootCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output results as JSON")
 	rootCmd.Flags().StringVar(&severity, "severity", "", "Filter by minimum severity (critical, high, medium, low, info)")
 	rootCmd.Flags().StringVar(&category, "category", "", "Filter by category")
-	rootCmd.Flags().StringVar(&tailnet, "tailnet", "", "Tailnet to audit (default: from API key)")
+	rootCmd.PersistentFlags().StringVar(&tailnet, "tailnet", "", "Tailnet to audit (default: from API key)")
 	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "Show passing checks too")
 	rootCmd.Flags().BoolVar(&fixMode, "fix", false, "Enable fix mode with remediation actions")
 	rootCmd.Flags().BoolVar(&autoFix, "auto", false, "Auto-select safe fixes (still requires confirmation)")
@@ -70,15 +78,99 @@ func init() {
 	rootCmd.Flags().StringVar(&checks, "checks", "", "Run only specific checks (comma-separated IDs or slugs)")
 	rootCmd.Flags().BoolVar(&listChecks, "list-checks", false, "List all available checks and exit")
 	rootCmd.Flags().StringVar(&soc2Format, "soc2", "", "Export SOC2 evidence (json or csv)")
+	rootCmd.Flags().StringVar(&sshAccess, "ssh-access", "", "Export effective SSH access matrix (json or csv)")
 	rootCmd.Flags().StringVar(&tailscalePath, "tailscale-path", "", "Path to tailscale CLI binary (for Tailnet Lock checks)")
+	rootCmd.PersistentFlags().StringVar(&versionDB, "version-db", "", "Path to Tailscale release and security bulletin data (default: bundled)")
+	rootCmd.Flags().StringVar(&domains, "domains", "", "Domains your organization controls (comma-separated, for DERP hostname and external admin checks)")
 	rootCmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "Path to ignore file (default: .tailsnitch-ignore)")
 	rootCmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "Disable ignore file processing")
+	rootCmd.Flags().StringVar(&serveDirAudit, "serve-dir", "", "Directory of Serve snapshots to add actual endpoints to NET-001 and NET-006")
+	rootCmd.PersistentFlags().StringVar(&lifecycleFile, "lifecycle-file", "", "Path to lifecycle threshold policy (default: .tailsnitch-lifecycle)")
+	rootCmd.PersistentFlags().StringVar(&namingFile, "naming-file", "", "Path to device naming policy (default: .tailsnitch-naming)")
+	rootCmd.PersistentFlags().StringVar(&postureFile, "posture-file", "", "Path to device posture policy (default: .tailsnitch-posture)")
+	rootCmd.PersistentFlags().StringVar(&asOfDate, "as-of", "", "Evaluate time-based checks as of a future date (YYYY-MM-DD) and forecast expirations until then")
 }
 
 func Execute() error {
 	return rootCmd.Execute()
 }
 
+// loadLifecyclePolicy loads the --lifecycle-file policy, or one from the
+// default locations, and hands it to the auditors. Returns the path used.
+func loadLifecyclePolicy() (string, error) {
+	if lifecycleFile != "" {
+		policy, err := types.LoadLifecycleFile(lifecycleFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to load lifecycle file %s: %w", lifecycleFile, err)
+		}
+		auditor.SetLifecyclePolicy(policy)
+		return lifecycleFile, nil
+	}
+
+	policy, path, err := types.LoadLifecycleFiles()
+	if err != nil {
+		return "", fmt.Errorf("failed to load lifecycle file %s: %w", path, err)
+	}
+	auditor.SetLifecyclePolicy(policy)
+	return path, nil
+}
+
+// loadNamingPolicy loads the --naming-file policy, or one from the default
+// locations, and hands it to the auditors. Returns the path used.
+func loadNamingPolicy() (string, error) {
+	if namingFile != "" {
+		policy, err := types.LoadNamingFile(namingFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to load naming file %s: %w", namingFile, err)
+		}
+		auditor.SetNamingPolicy(policy)
+		return namingFile, nil
+	}
+
+	policy, path, err := types.LoadNamingFiles()
+	if err != nil {
+		return "", fmt.Errorf("failed to load naming file %s: %w", path, err)
+	}
+	auditor.SetNamingPolicy(policy)
+	return path, nil
+}
+
+// loadPosturePolicy loads the --posture-file policy, or one from the default
+// locations, and hands it to the auditors. Returns the path used.
+func loadPosturePolicy() (string, error) {
+	if postureFile != "" {
+		policy, err := types.LoadPostureFile(postureFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to load posture file %s: %w", postureFile, err)
+		}
+		auditor.SetPosturePolicy(policy)
+		return postureFile, nil
+	}
+
+	policy, path, err := types.LoadPostureFiles()
+	if err != nil {
+		return "", fmt.Errorf("failed to load posture file %s: %w", path, err)
+	}
+	auditor.SetPosturePolicy(policy)
+	return path, nil
+}
+
+// auditClock parses the --as-of date into the clock time-based checks
+// evaluate against, or the current time if it isn't set
+func auditClock() (auditor.Clock, error) {
+	if asOfDate == "" {
+		return auditor.Clock{}, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", asOfDate, time.Local)
+	if err != nil {
+		return auditor.Clock{}, fmt.Errorf("invalid --as-of: use YYYY-MM-DD")
+	}
+	if !t.After(time.Now()) {
+		return auditor.Clock{}, fmt.Errorf("invalid --as-of: %s is not in the future", asOfDate)
+	}
+	return auditor.AsOfClock(t), nil
+}
+
 func runAudit(cmd *cobra.Command, args []string) error {
 	// Handle --list-checks before anything else
 	if listChecks {
@@ -113,6 +205,16 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--fix and --json cannot be used together")
 	}
 
+	// Fixes act on the tailnet as it is today, not on a forecast
+	if fixMode && asOfDate != "" {
+		return fmt.Errorf("--fix and --as-of cannot be used together")
+	}
+
+	clock, err := auditClock()
+	if err != nil {
+		return err
+	}
+
 	// Validate --soc2 flag
 	if soc2Format != "" && soc2Format != "json" && soc2Format != "csv" {
 		return fmt.Errorf("--soc2 must be 'json' or 'csv'")
@@ -122,6 +224,15 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--soc2 cannot be combined with --fix or --json")
 	}
 
+	// Validate --ssh-access flag
+	if sshAccess != "" && sshAccess != "json" && sshAccess != "csv" {
+		return fmt.Errorf("--ssh-access must be 'json' or 'csv'")
+	}
+
+	if sshAccess != "" && (fixMode || jsonOutput || soc2Format != "") {
+		return fmt.Errorf("--ssh-access cannot be combined with --fix, --json or --soc2")
+	}
+
 	// Create Tailscale client
 	c, err := client.New(tailnet)
 	if err != nil {
@@ -135,9 +246,44 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := auditor.SetVersionDBPath(versionDB); err != nil {
+		return fmt.Errorf("invalid --version-db: %w", err)
+	}
+
+	lifecyclePath, err := loadLifecyclePolicy()
+	if err != nil {
+		return err
+	}
+
+	namingPath, err := loadNamingPolicy()
+	if err != nil {
+		return err
+	}
+
+	posturePath, err := loadPosturePolicy()
+	if err != nil {
+		return err
+	}
+
+	if domains != "" {
+		auditor.SetTrustedDomains(strings.Split(domains, ","))
+	}
+
+	var serveInventory *types.ServeInventoryReport
+	if serveDirAudit != "" {
+		serveInventory, err = auditor.LoadServeSnapshots(serveDirAudit)
+		if err != nil {
+			return fmt.Errorf("failed to load Serve snapshots from %s: %w", serveDirAudit, err)
+		}
+		for _, e := range serveInventory.Errors {
+			fmt.Fprintf(os.Stderr, "Warning: skipped %s\n", e)
+		}
+		auditor.SetServeInventory(serveInventory)
+	}
+
 	// Handle SOC2 export mode
 	if soc2Format != "" {
-		collector := auditor.NewSOC2Collector(c)
+		collector := auditor.NewSOC2Collector(c, clock)
 		soc2Report, err := collector.Collect(ctx)
 		if err != nil {
 			return fmt.Errorf("SOC2 collection failed: %w", err)
@@ -148,13 +294,41 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		return output.SOC2JSON(os.Stdout, soc2Report)
 	}
 
+	// Handle SSH access matrix export mode
+	if sshAccess != "" {
+		collector := auditor.NewSSHAccessCollector(c)
+		accessReport, err := collector.Collect(ctx)
+		if err != nil {
+			return fmt.Errorf("SSH access collection failed: %w", err)
+		}
+		if sshAccess == "csv" {
+			return output.SSHAccessCSV(os.Stdout, accessReport)
+		}
+		return output.SSHAccessJSON(os.Stdout, accessReport)
+	}
+
 	// Print banner immediately (unless JSON output)
 	if !jsonOutput {
 		output.PrintBanner(os.Stdout, c.Tailnet(), Version, BuildID)
+		if lifecyclePath != "" {
+			fmt.Printf("  Using lifecycle policy: %s\n\n", lifecyclePath)
+		}
+		if namingPath != "" {
+			fmt.Printf("  Using naming policy: %s\n\n", namingPath)
+		}
+		if posturePath != "" {
+			fmt.Printf("  Using posture policy: %s\n\n", posturePath)
+		}
+		if asOfDate != "" {
+			fmt.Printf("  Evaluating as of: %s\n\n", asOfDate)
+		}
+		if serveInventory != nil {
+			fmt.Printf("  Using Serve snapshots: %s (%d nodes, %d skipped)\n\n", serveDirAudit, serveInventory.Summary.Nodes, len(serveInventory.Errors))
+		}
 	}
 
 	// Run the audit
-	a := auditor.New(c)
+	a := auditor.New(c, clock)
 	report, err := a.Run(ctx)
 	if err != nil {
 		return fmt.Errorf("audit failed: %w", err)
@@ -275,6 +449,8 @@ func parseCategory(s string) types.Category {
 		return types.LoggingAdmin
 	case strings.Contains(lower, "device"):
 		return types.DeviceSecurity
+	case strings.Contains(lower, "local"):
+		return types.LocalNode
 	default:
 		return ""
 	}
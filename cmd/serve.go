@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Adversis/tailsnitch/pkg/auditor"
+	"github.com/Adversis/tailsnitch/pkg/output"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+var (
+	serveFormat   string
+	serveDir      string
+	serveSocket   string
+	serveSnapshot bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Inventory the Serve and Funnel endpoints actually configured on nodes",
+	Long: `List every port and path nodes expose with Tailscale Serve, which of them
+are public on the internet via Funnel, and which proxy to plaintext backends on
+other hosts or to admin and data services.
+
+Without --dir, the local node is read over the tailscaled LocalAPI socket.
+With --dir, every .json file in the directory is read: either a snapshot
+written by 'tailsnitch serve --snapshot' on a node, or the output of
+'tailscale serve status --json' named after its node.
+
+Pass the same directory to the audit with --serve-dir to add the endpoints
+to NET-001 and NET-006.`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveFormat, "format", "csv", "Output format (csv or json)")
+	serveCmd.Flags().StringVar(&serveDir, "dir", "", "Directory of Serve snapshots to read instead of the local node")
+	serveCmd.Flags().StringVar(&serveSocket, "socket", "", "Path to the tailscaled socket (default: platform default)")
+	serveCmd.Flags().BoolVar(&serveSnapshot, "snapshot", false, "Write the local node's Serve snapshot as JSON, for collecting with --dir")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if serveFormat != "json" && serveFormat != "csv" {
+		return fmt.Errorf("--format must be 'json' or 'csv'")
+	}
+	if serveSnapshot && serveDir != "" {
+		return fmt.Errorf("--snapshot reads the local node and cannot be combined with --dir")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if serveSnapshot {
+		snap, err := auditor.NewLocalAuditor(serveSocket).Snapshot(ctx)
+		if err != nil {
+			return fmt.Errorf("serve snapshot failed: %w", err)
+		}
+		return output.ServeSnapshotJSON(os.Stdout, snap)
+	}
+
+	var report *types.ServeInventoryReport
+	var err error
+	if serveDir != "" {
+		report, err = auditor.LoadServeSnapshots(serveDir)
+	} else {
+		report, err = auditor.NewLocalAuditor(serveSocket).ServeInventory(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("serve inventory failed: %w", err)
+	}
+
+	for _, e := range report.Errors {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %s\n", e)
+	}
+
+	if serveFormat == "json" {
+		return output.ServeInventoryJSON(os.Stdout, report)
+	}
+	return output.ServeInventoryCSV(os.Stdout, report)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/netip"
+	"sort"
 	"strings"
 
 	"github.com/tailscale/hujson"
@@ -31,8 +33,16 @@ type ACLPolicy struct {
 	Hosts         map[string]string   `json:"hosts"`
 	Tests         []ACLTest           `json:"tests"`
 	SSH           []SSHRule           `json:"ssh"`
+	SSHTests      []SSHTest           `json:"sshTests"`
 	NodeAttrs     []NodeAttr          `json:"nodeAttrs"`
 	AutoApprovers *AutoApprovers      `json:"autoApprovers"`
+	DERPMap       *DERPMap            `json:"derpMap"`
+	IPSets        map[string][]string `json:"ipsets"`
+
+	// Tailnet-wide network settings
+	DisableIPv4         bool   `json:"disableIPv4"`
+	RandomizeClientPort bool   `json:"randomizeClientPort"`
+	OneCGNATRoute       string `json:"OneCGNATRoute"`
 }
 
 // Grant represents a grant-based access rule (newer format)
@@ -71,6 +81,15 @@ type ACLTest struct {
 	Deny   []string `json:"deny"`
 }
 
+// SSHTest asserts which local users a source may SSH in as on each destination
+type SSHTest struct {
+	Src    string   `json:"src"`
+	Dst    []string `json:"dst"`
+	Accept []string `json:"accept"`
+	Check  []string `json:"check"`
+	Deny   []string `json:"deny"`
+}
+
 type SSHRule struct {
 	Action          string   `json:"action"`
 	Src             []string `json:"src"`
@@ -79,11 +98,50 @@ type SSHRule struct {
 	CheckPeriod     string   `json:"checkPeriod"`
 	Recorder        []string `json:"recorder"`
 	EnforceRecorder bool     `json:"enforceRecorder"`
+	AcceptEnv       []string `json:"acceptEnv,omitempty"`
 }
 
 type NodeAttr struct {
-	Target []string `json:"target"`
-	Attr   []string `json:"attr"`
+	Target []string     `json:"target"`
+	Attr   []string     `json:"attr"`
+	App    *NodeAttrApp `json:"app"`
+}
+
+// NodeAttrApp represents app capabilities granted through nodeAttrs
+type NodeAttrApp struct {
+	AppConnectors []AppConnector `json:"tailscale.com/app-connectors"`
+}
+
+// AppConnector is an entry in the tailscale.com/app-connectors node attribute
+type AppConnector struct {
+	Name       string   `json:"name"`
+	Connectors []string `json:"connectors"`
+	Domains    []string `json:"domains"`
+}
+
+// DERPMap is the custom DERP configuration embedded in the policy file
+type DERPMap struct {
+	OmitDefaultRegions bool                   `json:"OmitDefaultRegions"`
+	Regions            map[string]*DERPRegion `json:"Regions"`
+}
+
+type DERPRegion struct {
+	RegionID   int        `json:"RegionID"`
+	RegionCode string     `json:"RegionCode"`
+	RegionName string     `json:"RegionName"`
+	Nodes      []DERPNode `json:"Nodes"`
+}
+
+type DERPNode struct {
+	Name             string `json:"Name"`
+	RegionID         int    `json:"RegionID"`
+	HostName         string `json:"HostName"`
+	IPv4             string `json:"IPv4"`
+	IPv6             string `json:"IPv6"`
+	STUNPort         int    `json:"STUNPort"`
+	STUNOnly         bool   `json:"STUNOnly"`
+	DERPPort         int    `json:"DERPPort"`
+	InsecureForTests bool   `json:"InsecureForTests"`
 }
 
 type AutoApprovers struct {
@@ -154,6 +212,12 @@ func (a *ACLAuditor) Audit(ctx context.Context) ([]types.Suggestion, error) {
 	// ACL-010: Check Taildrop configuration
 	findings = append(findings, a.checkTaildropConfig(policy))
 
+	// ACL-011: Check tailnet-wide network settings
+	findings = append(findings, a.checkNetworkSettings(policy))
+
+	// ACL-012: Check IP sets for broad ranges and undefined references
+	findings = append(findings, a.checkIPSets(policy))
+
 	return findings, nil
 }
 
@@ -736,3 +800,117 @@ func (a *ACLAuditor) checkTaildropConfig(policy ACLPolicy) types.Suggestion {
 
 	return finding
 }
+
+func (a *ACLAuditor) checkNetworkSettings(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "ACL-011",
+		Title:       "Tailnet-wide network settings changed (Network Settings)",
+		Severity:    types.Informational,
+		Category:    types.AccessControl,
+		Description: "disableIPv4, randomizeClientPort and OneCGNATRoute change how every device in the tailnet addresses and routes traffic.",
+		Remediation: "Confirm each non-default setting is intentional and documented. Review firewall rules that depend on client ports or the CGNAT range.",
+		Source:      "https://tailscale.com/kb/1337/policy-syntax",
+		Pass:        true,
+	}
+
+	var settings []string
+	if policy.DisableIPv4 {
+		settings = append(settings, "disableIPv4: devices get no 100.x IPv4 addresses; clients and services without IPv6 support lose connectivity")
+	}
+	if policy.RandomizeClientPort {
+		settings = append(settings, "randomizeClientPort: clients use random UDP ports; firewall allowlists on port 41641 no longer apply")
+	}
+	if policy.OneCGNATRoute != "" {
+		settings = append(settings, fmt.Sprintf("OneCGNATRoute=%q: changes whether clients route the whole 100.64.0.0/10 range over Tailscale", policy.OneCGNATRoute))
+	}
+
+	if len(settings) > 0 {
+		finding.Pass = false
+		finding.Details = settings
+		finding.Description = fmt.Sprintf("Found %d non-default tailnet-wide network setting(s) in the policy file.", len(settings))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Review tailnet-wide settings in the policy file",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1337/policy-syntax",
+		}
+	}
+
+	return finding
+}
+
+// ipSetEntry splits an ipsets entry into its operation ("add" or "remove") and value
+func ipSetEntry(entry string) (string, string) {
+	fields := strings.Fields(entry)
+	if len(fields) == 2 && (fields[0] == "add" || fields[0] == "remove") {
+		return fields[0], fields[1]
+	}
+	return "add", strings.TrimSpace(entry)
+}
+
+// isBroadPrefix returns true for prefixes covering the whole internet or a
+// large share of it: an IPv4 /8 or larger, or an IPv6 /16 or larger. Regional
+// registries allocate IPv6 blocks down to /32 and /29 to single organizations,
+// so those are not broad.
+func isBroadPrefix(value string) bool {
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return false
+	}
+	if prefix.Addr().Is4() {
+		return prefix.Bits() <= 8
+	}
+	return prefix.Bits() <= 16
+}
+
+func (a *ACLAuditor) checkIPSets(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "ACL-012",
+		Title:       "IP sets contain broad ranges or undefined references (IP Sets)",
+		Severity:    types.Medium,
+		Category:    types.AccessControl,
+		Description: "IP sets group addresses for use in rules. A broad prefix or a reference to a missing set silently changes what every rule using the set allows.",
+		Remediation: "Keep IP sets to the specific ranges they describe. Fix references to IP sets or hosts that are not defined.",
+		Source:      "https://tailscale.com/kb/1387/ipsets",
+		Pass:        true,
+	}
+
+	var names []string
+	for name := range policy.IPSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []string
+	for _, name := range names {
+		for _, entry := range policy.IPSets[name] {
+			op, value := ipSetEntry(entry)
+			switch {
+			case strings.HasPrefix(value, "ipset:"):
+				if _, ok := policy.IPSets[value]; !ok {
+					issues = append(issues, fmt.Sprintf("%s: references undefined %s", name, value))
+				}
+			case strings.HasPrefix(value, "host:"):
+				if _, ok := policy.Hosts[strings.TrimPrefix(value, "host:")]; !ok {
+					issues = append(issues, fmt.Sprintf("%s: references undefined %s", name, value))
+				}
+			case op == "add" && isBroadPrefix(value):
+				issues = append(issues, fmt.Sprintf("%s: includes broad range %s", name, value))
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		finding.Pass = false
+		finding.Details = issues
+		finding.Description = fmt.Sprintf("Found %d issue(s) in IP set definitions.", len(issues))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Narrow broad ranges and fix undefined references in the ipsets section",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1387/ipsets",
+		}
+	}
+
+	return finding
+}
@@ -477,3 +477,114 @@ func TestCheckGroupsExist(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckNetworkSettings(t *testing.T) {
+	a := &ACLAuditor{}
+
+	if f := a.checkNetworkSettings(ACLPolicy{}); !f.Pass {
+		t.Errorf("default settings: Pass = false, want true")
+	}
+
+	f := a.checkNetworkSettings(ACLPolicy{DisableIPv4: true, RandomizeClientPort: true, OneCGNATRoute: "mac-always"})
+	if f.Pass {
+		t.Fatalf("non-default settings: Pass = true, want false")
+	}
+	if details, ok := f.Details.([]string); !ok || len(details) != 3 {
+		t.Errorf("Details = %v, want 3 entries", f.Details)
+	}
+}
+
+func TestCheckIPSets(t *testing.T) {
+	a := &ACLAuditor{}
+
+	tests := []struct {
+		name      string
+		policy    ACLPolicy
+		wantPass  bool
+		wantCount int
+	}{
+		{
+			name:     "no ipsets",
+			policy:   ACLPolicy{},
+			wantPass: true,
+		},
+		{
+			name: "narrow ranges and valid references",
+			policy: ACLPolicy{
+				Hosts: map[string]string{"db": "10.0.0.5"},
+				IPSets: map[string][]string{
+					"ipset:office": {"10.1.0.0/16", "remove 10.1.5.0/24"},
+					"ipset:prod":   {"ipset:office", "host:db"},
+				},
+			},
+			wantPass: true,
+		},
+		{
+			name: "removing a broad range is fine",
+			policy: ACLPolicy{IPSets: map[string][]string{
+				"ipset:internal": {"192.168.0.0/16", "remove 10.0.0.0/8"},
+			}},
+			wantPass: true,
+		},
+		{
+			name: "broad ranges and undefined references",
+			policy: ACLPolicy{IPSets: map[string][]string{
+				"ipset:all":    {"0.0.0.0/0", "add 10.0.0.0/8"},
+				"ipset:broken": {"ipset:missing", "host:nope"},
+			}},
+			wantPass:  false,
+			wantCount: 4,
+		},
+		{
+			name: "allocated IPv6 blocks are not broad",
+			policy: ACLPolicy{IPSets: map[string][]string{
+				"ipset:v6": {"2001:db8::/32", "2a01:4f8::/29", "fd7a:115c:a1e0::/48"},
+			}},
+			wantPass: true,
+		},
+		{
+			name: "broad IPv6 ranges",
+			policy: ACLPolicy{IPSets: map[string][]string{
+				"ipset:v6all": {"::/0", "2000::/3"},
+			}},
+			wantPass:  false,
+			wantCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := a.checkIPSets(tt.policy)
+			if f.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (details: %v)", f.Pass, tt.wantPass, f.Details)
+			}
+			if !tt.wantPass {
+				if details, ok := f.Details.([]string); !ok || len(details) != tt.wantCount {
+					t.Errorf("Details = %v, want %d entries", f.Details, tt.wantCount)
+				}
+			}
+		})
+	}
+}
+
+func TestIsBroadPrefix(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"0.0.0.0/0", true},
+		{"10.0.0.0/8", true},
+		{"10.1.0.0/16", false},
+		{"::/0", true},
+		{"2000::/3", true},
+		{"2001::/16", true},
+		{"2a01:4f8::/29", false},
+		{"2001:db8::/32", false},
+		{"not-a-prefix", false},
+	}
+	for _, tt := range tests {
+		if got := isBroadPrefix(tt.value); got != tt.want {
+			t.Errorf("isBroadPrefix(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -41,14 +41,51 @@ func isAuthError(err error) bool {
 		strings.Contains(errStr, "Forbidden")
 }
 
+// TailnetData holds API responses that several auditors need, fetched once
+// per run like the ACL policy. Each error is kept so the checks that need
+// the data can fall back to manual review.
+type TailnetData struct {
+	Devices     []*client.Device
+	DevicesErr  error
+	Users       []client.User
+	UsersErr    error
+	Settings    *client.TailnetSettings
+	SettingsErr error
+	Keys        []client.KeyEntry
+	KeysSkipped []string // Listed keys whose details couldn't be fetched
+	KeysErr     error
+}
+
+// FetchTailnetData fetches the data shared between the auditors and the
+// expiry forecast
+func FetchTailnetData(ctx context.Context, c *client.Client) *TailnetData {
+	data := &TailnetData{}
+	data.Devices, data.DevicesErr = c.GetDevices(ctx)
+	data.Users, data.UsersErr = c.GetUsers(ctx)
+	data.Settings, data.SettingsErr = c.GetSettings(ctx)
+	data.Keys, data.KeysSkipped, data.KeysErr = c.GetAllKeys(ctx)
+	return data
+}
+
+// manualCheck turns a check's base finding into an informational failure
+// for when the data it needs couldn't be read, with steps to check by hand
+func manualCheck(base types.Suggestion, description, steps string) types.Suggestion {
+	base.Severity = types.Informational
+	base.Description = description
+	base.Details = "MANUAL CHECK REQUIRED: " + steps
+	base.Pass = false
+	return base
+}
+
 // Auditor orchestrates all security audits
 type Auditor struct {
 	client *client.Client
+	clock  Clock
 }
 
-// New creates a new auditor
-func New(c *client.Client) *Auditor {
-	return &Auditor{client: c}
+// New creates a new auditor that evaluates time-based checks against clock
+func New(c *client.Client, clock Clock) *Auditor {
+	return &Auditor{client: c, clock: clock}
 }
 
 // Run executes all audit checks and returns a report
@@ -100,6 +137,9 @@ func (a *Auditor) Run(ctx context.Context) (*types.AuditReport, error) {
 		}
 	}
 
+	// Devices, users, settings and keys are each read by several auditors
+	data := FetchTailnetData(ctx, a.client)
+
 	// Run all auditors in parallel using errgroup
 	var (
 		results []auditorResult
@@ -124,44 +164,52 @@ func (a *Auditor) Run(ctx context.Context) (*types.AuditReport, error) {
 
 	// Auth auditor
 	g.Go(func() error {
-		auditor := NewAuthAuditor(a.client)
-		findings, err := auditor.Audit(gctx)
+		auditor := NewAuthAuditor(a.client, a.clock)
+		findings, err := auditor.Audit(gctx, data)
 		appendResult(auditorResult{name: "Auth", findings: findings, err: err})
 		return nil
 	})
 
 	// Device auditor
 	g.Go(func() error {
-		auditor := NewDeviceAuditor(a.client)
-		findings, err := auditor.Audit(gctx)
+		auditor := NewDeviceAuditor(a.client, a.clock)
+		findings, err := auditor.Audit(gctx, data)
 		appendResult(auditorResult{name: "Device", findings: findings, err: err})
 		return nil
 	})
 
 	// Network auditor (uses pre-fetched ACL policy)
 	g.Go(func() error {
-		auditor := NewNetworkAuditor(a.client)
-		findings, err := auditor.Audit(gctx, policy)
+		auditor := NewNetworkAuditor(a.client, a.clock)
+		findings, err := auditor.Audit(gctx, policy, data)
 		appendResult(auditorResult{name: "Network", findings: findings, err: err})
 		return nil
 	})
 
 	// SSH auditor (uses pre-fetched ACL policy)
 	g.Go(func() error {
-		auditor := NewSSHAuditor(a.client)
-		findings, err := auditor.Audit(gctx, policy)
+		auditor := NewSSHAuditor(a.client, a.clock)
+		findings, err := auditor.Audit(gctx, policy, data)
 		appendResult(auditorResult{name: "SSH", findings: findings, err: err})
 		return nil
 	})
 
 	// Logging auditor
 	g.Go(func() error {
-		auditor := NewLoggingAuditor(a.client)
-		findings, err := auditor.Audit(gctx)
+		auditor := NewLoggingAuditor(a.client, a.clock)
+		findings, err := auditor.Audit(gctx, data)
 		appendResult(auditorResult{name: "Logging", findings: findings, err: err})
 		return nil
 	})
 
+	// User auditor
+	g.Go(func() error {
+		auditor := NewUserAuditor(a.client, a.clock)
+		findings, err := auditor.Audit(gctx, data)
+		appendResult(auditorResult{name: "User", findings: findings, err: err})
+		return nil
+	})
+
 	// DNS auditor
 	g.Go(func() error {
 		auditor := NewDNSAuditor(a.client)
@@ -186,6 +234,7 @@ func (a *Auditor) Run(ctx context.Context) (*types.AuditReport, error) {
 		"Network": {"NET-ERR", types.NetworkExposure},
 		"SSH":     {"SSH-ERR", types.SSHSecurity},
 		"Logging": {"LOG-ERR", types.LoggingAdmin},
+		"User":    {"USER-ERR", types.LoggingAdmin},
 		"DNS":     {"DNS-ERR", types.DNSConfiguration},
 	}
 
@@ -205,6 +254,25 @@ func (a *Auditor) Run(ctx context.Context) (*types.AuditReport, error) {
 		}
 	}
 
+	// Forecast lifecycle events up to the --as-of date
+	if asOf := a.clock.AsOf(); !asOf.IsZero() {
+		report.AsOf = a.clock.asOfPtr()
+		forecast, err := collectForecast(ctx, a.client, data, report.Timestamp, asOf)
+		if err != nil {
+			report.Suggestions = append(report.Suggestions, types.Suggestion{
+				ID:          "SYS-003",
+				Title:       "Could not forecast expirations",
+				Severity:    types.Low,
+				Category:    types.DeviceSecurity,
+				Description: fmt.Sprintf("Failed to forecast expirations up to %s: %v. The report has no forecast.", asOf.Format("2006-01-02"), err),
+				Remediation: "Verify API key has sufficient permissions to read devices and keys.",
+				Pass:        false,
+			})
+		} else {
+			report.Forecast = forecast
+		}
+	}
+
 	// Calculate summary
 	report.CalculateSummary()
 
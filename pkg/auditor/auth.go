@@ -12,11 +12,12 @@ import (
 // AuthAuditor checks for authentication and key management issues
 type AuthAuditor struct {
 	client *client.Client
+	clock  Clock
 }
 
 // NewAuthAuditor creates a new auth auditor
-func NewAuthAuditor(c *client.Client) *AuthAuditor {
-	return &AuthAuditor{client: c}
+func NewAuthAuditor(c *client.Client, clock Clock) *AuthAuditor {
+	return &AuthAuditor{client: c, clock: clock}
 }
 
 // keyInfo holds parsed auth key information for auditing
@@ -29,58 +30,103 @@ type keyInfo struct {
 	DaysToExpiry  int
 	Created       time.Time
 	Expires       time.Time
+	UserID        string // The creator, when read from the full key listing
 }
 
-// Audit performs authentication-related security checks
-func (a *AuthAuditor) Audit(ctx context.Context) ([]types.Suggestion, error) {
-	var findings []types.Suggestion
+// newKeyInfo parses an auth key's dates and capabilities for auditing
+func newKeyInfo(id string, created, expires time.Time, caps client.KeyCapabilities, now time.Time) keyInfo {
+	info := keyInfo{
+		ID:      id,
+		Created: created,
+		Expires: expires,
+	}
 
-	// Get all auth keys
-	keyIDs, err := a.client.GetKeys(ctx)
-	if err != nil {
-		// Auth keys might not be accessible with all API keys
-		findings = append(findings, types.Suggestion{
-			ID:          "AUTH-ERR",
-			Title:       "Could not retrieve auth keys",
-			Severity:    types.Informational,
-			Category:    types.Authentication,
-			Description: fmt.Sprintf("Unable to retrieve auth keys: %v. This may require additional API permissions.", err),
-			Pass:        true,
-		})
-		return findings, nil
+	// Calculate days to expiry
+	if !expires.IsZero() {
+		info.DaysToExpiry = int(expires.Sub(now).Hours() / 24)
+	}
+
+	// Extract capabilities
+	if caps.Devices.Create.Reusable {
+		info.Reusable = true
 	}
+	if caps.Devices.Create.Preauthorized {
+		info.Preauthorized = true
+	}
+	if caps.Devices.Create.Ephemeral {
+		info.Ephemeral = true
+	}
+	info.Tags = caps.Devices.Create.Tags
 
+	return info
+}
+
+// fetchKeyInfo fetches each auth key and parses it for auditing. Keys that
+// can't be fetched are skipped.
+func fetchKeyInfo(ctx context.Context, c *client.Client, keyIDs []string, now time.Time) []keyInfo {
 	var keys []keyInfo
 	for _, id := range keyIDs {
-		key, err := a.client.GetKey(ctx, id)
+		key, err := c.GetKey(ctx, id)
 		if err != nil {
 			continue // Skip keys we can't fetch
 		}
+		keys = append(keys, newKeyInfo(key.ID, key.Created, key.Expires, key.Capabilities, now))
+	}
+	return keys
+}
 
-		info := keyInfo{
-			ID:      key.ID,
-			Created: key.Created,
-			Expires: key.Expires,
+// authKeyInfo parses the auth keys in a full key listing for auditing,
+// leaving out API access tokens, OAuth clients and inactive keys
+func authKeyInfo(entries []client.KeyEntry, now time.Time) []keyInfo {
+	var keys []keyInfo
+	for _, entry := range entries {
+		if entry.Type() == client.KeyTypeAuth && keyActive(entry, now) {
+			info := newKeyInfo(entry.ID, entry.Created, entry.Expires, entry.Capabilities, now)
+			info.UserID = entry.UserID
+			keys = append(keys, info)
 		}
+	}
+	return keys
+}
 
-		// Calculate days to expiry
-		if !key.Expires.IsZero() {
-			info.DaysToExpiry = int(time.Until(key.Expires).Hours() / 24)
-		}
+// Audit performs authentication-related security checks
+func (a *AuthAuditor) Audit(ctx context.Context, data *TailnetData) ([]types.Suggestion, error) {
+	var findings []types.Suggestion
 
-		// Extract capabilities
-		if key.Capabilities.Devices.Create.Reusable {
-			info.Reusable = true
-		}
-		if key.Capabilities.Devices.Create.Preauthorized {
-			info.Preauthorized = true
-		}
-		if key.Capabilities.Devices.Create.Ephemeral {
-			info.Ephemeral = true
+	// The full key listing covers other users' keys, API access tokens and
+	// OAuth clients. Without it, only the caller's auth keys are audited.
+	var keys []keyInfo
+	if data.KeysErr == nil {
+		keys = authKeyInfo(data.Keys, a.clock.Now())
+	} else {
+		keyIDs, err := a.client.GetKeys(ctx)
+		if err != nil {
+			// Auth keys might not be accessible with all API keys
+			findings = append(findings, types.Suggestion{
+				ID:          "AUTH-ERR",
+				Title:       "Could not retrieve auth keys",
+				Severity:    types.Informational,
+				Category:    types.Authentication,
+				Description: fmt.Sprintf("Unable to retrieve auth keys: %v. This may require additional API permissions.", err),
+				Pass:        true,
+			})
+			return findings, nil
 		}
-		info.Tags = key.Capabilities.Devices.Create.Tags
+		keys = fetchKeyInfo(ctx, a.client, keyIDs, a.clock.Now())
+	}
 
-		keys = append(keys, info)
+	// Keys whose details couldn't be fetched aren't covered by the checks
+	if len(data.KeysSkipped) > 0 {
+		findings = append(findings, types.Suggestion{
+			ID:          "AUTH-ERR",
+			Title:       "Some keys could not be read",
+			Severity:    types.Informational,
+			Category:    types.Authentication,
+			Description: fmt.Sprintf("%d key(s) were listed but their details could not be fetched, so the key checks don't cover them.", len(data.KeysSkipped)),
+			Remediation: "Review these keys on the Keys page of the admin console.",
+			Details:     data.KeysSkipped,
+			Pass:        false,
+		})
 	}
 
 	// AUTH-001: Check for reusable auth keys
@@ -95,6 +141,24 @@ func (a *AuthAuditor) Audit(ctx context.Context) ([]types.Suggestion, error) {
 	// AUTH-004: Informational - ephemeral key usage
 	findings = append(findings, a.checkEphemeralKeyUsage(keys))
 
+	// AUTH-005: Correlate devices with the keys that enrolled them
+	if data.DevicesErr != nil {
+		findings = append(findings, manualCheck(keyEnrollmentFinding(),
+			fmt.Sprintf("Cannot correlate devices with auth keys: %v", data.DevicesErr),
+			"Compare device tags on the Machines page with the tags granted by active auth keys."))
+	} else {
+		findings = append(findings, a.checkKeyEnrollments(keys, data.Devices, data.KeysErr == nil))
+	}
+
+	// AUTH-006: API access tokens with long lifetimes or removed creators
+	if data.KeysErr != nil {
+		findings = append(findings, manualCheck(apiTokenFinding(),
+			fmt.Sprintf("Cannot list API access tokens: %v", data.KeysErr),
+			"Review API access tokens on the Keys page for long expiry and tokens created by people who have left."))
+	} else {
+		findings = append(findings, a.checkAPITokens(data.Keys, newKeyCreators(data.Users, data.UsersErr), a.clock.Now()))
+	}
+
 	return findings, nil
 }
 
@@ -157,9 +221,17 @@ func (a *AuthAuditor) checkLongExpiryKeys(keys []keyInfo) types.Suggestion {
 
 	var longExpiryKeys []string
 	var fixableItems []types.FixableItem
+	customRules := false
 	for _, key := range keys {
-		if key.DaysToExpiry > 90 {
-			longExpiryKeys = append(longExpiryKeys, fmt.Sprintf("Key %s: %d days until expiry", key.ID, key.DaysToExpiry))
+		// Tagged keys may have a tighter limit from a lifecycle rule
+		rule := lifecyclePolicy.AuthKeyRule(key.Tags)
+		if key.DaysToExpiry > rule.AuthKeyThresholdDays() {
+			line := fmt.Sprintf("Key %s: %d days until expiry", key.ID, key.DaysToExpiry)
+			if !rule.IsDefault() {
+				customRules = true
+				line += fmt.Sprintf(" (rule %q: ≤%d days)", rule.Name, rule.AuthKeyThresholdDays())
+			}
+			longExpiryKeys = append(longExpiryKeys, line)
 			fixableItems = append(fixableItems, types.FixableItem{
 				ID:          key.ID,
 				Name:        key.ID,
@@ -171,7 +243,11 @@ func (a *AuthAuditor) checkLongExpiryKeys(keys []keyInfo) types.Suggestion {
 	if len(longExpiryKeys) > 0 {
 		finding.Pass = false
 		finding.Details = longExpiryKeys
-		finding.Description = fmt.Sprintf("Found %d auth key(s) with >90 days until expiry.", len(longExpiryKeys))
+		if customRules {
+			finding.Description = fmt.Sprintf("Found %d auth key(s) exceeding their lifecycle expiry limit (default %d days).", len(longExpiryKeys), types.DefaultAuthKeyExpiryDays)
+		} else {
+			finding.Description = fmt.Sprintf("Found %d auth key(s) with >%d days until expiry.", len(longExpiryKeys), types.DefaultAuthKeyExpiryDays)
+		}
 		finding.Fix = &types.FixInfo{
 			Type:        types.FixTypeAPI,
 			Description: "Delete long-expiry keys and recreate with shorter expiry",
@@ -1,9 +1,11 @@
 package auditor
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Adversis/tailsnitch/pkg/client"
 	"github.com/Adversis/tailsnitch/pkg/types"
 )
 
@@ -278,3 +280,141 @@ func TestKeyInfoDaysToExpiry(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckLongExpiryKeysLifecyclePolicy(t *testing.T) {
+	defer SetLifecyclePolicy(nil)
+	SetLifecyclePolicy(&types.LifecyclePolicy{Rules: []types.LifecycleRule{
+		{Name: "ci", Tags: []string{"tag:ci"}, AuthKeyExpiryDays: 1},
+	}})
+
+	a := &AuthAuditor{}
+	result := a.checkLongExpiryKeys([]keyInfo{
+		{ID: "ci-key", Tags: []string{"tag:ci"}, DaysToExpiry: 7},
+		{ID: "other", DaysToExpiry: 30},
+	})
+
+	if result.Pass {
+		t.Fatal("expected ci key to exceed its 1 day rule")
+	}
+	details, _ := result.Details.([]string)
+	if len(details) != 1 || !strings.Contains(details[0], `rule "ci"`) {
+		t.Errorf("expected only ci-key with rule name, got %v", details)
+	}
+}
+
+func TestEnrollingKey(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := []keyInfo{
+		{ID: "old", Tags: []string{"tag:ci"}, Created: base, Expires: base.AddDate(0, 0, 90)},
+		{ID: "new", Tags: []string{"tag:ci"}, Created: base.AddDate(0, 0, 30), Expires: base.AddDate(0, 0, 120)},
+		{ID: "server", Tags: []string{"tag:server", "tag:prod"}, Created: base, Expires: base.AddDate(1, 0, 0)},
+	}
+
+	tests := []struct {
+		name    string
+		dev     *client.Device
+		wantKey string
+	}{
+		{"before newer key", &client.Device{Tags: []string{"tag:ci"}, Created: base.AddDate(0, 0, 10).Format(time.RFC3339)}, "old"},
+		{"most recent valid key wins", &client.Device{Tags: []string{"tag:ci"}, Created: base.AddDate(0, 0, 40).Format(time.RFC3339)}, "new"},
+		{"tag order ignored", &client.Device{Tags: []string{"tag:prod", "tag:server"}, Created: base.AddDate(0, 1, 0).Format(time.RFC3339)}, "server"},
+		{"after every key expired", &client.Device{Tags: []string{"tag:ci"}, Created: base.AddDate(1, 0, 0).Format(time.RFC3339)}, ""},
+		{"before key created", &client.Device{Tags: []string{"tag:ci"}, Created: base.AddDate(-1, 0, 0).Format(time.RFC3339)}, ""},
+		{"tag subset does not match", &client.Device{Tags: []string{"tag:server"}, Created: base.AddDate(0, 1, 0).Format(time.RFC3339)}, ""},
+		{"untagged device", &client.Device{Created: base.AddDate(0, 1, 0).Format(time.RFC3339)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := enrollingKey(tt.dev, keys)
+			if tt.wantKey == "" {
+				if ok {
+					t.Errorf("got key %s, want none", key.ID)
+				}
+				return
+			}
+			if !ok || key.ID != tt.wantKey {
+				t.Errorf("got key %q (found=%v), want %s", key.ID, ok, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestCheckKeyEnrollments(t *testing.T) {
+	a := &AuthAuditor{}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := base.AddDate(0, 0, 1).Format(time.RFC3339)
+
+	tests := []struct {
+		name      string
+		keys      []keyInfo
+		devices   []*client.Device
+		partial   bool
+		wantPass  bool
+		wantLines []string
+	}{
+		{
+			name:     "no devices",
+			keys:     []keyInfo{{ID: "k1", Reusable: true, Tags: []string{"tag:ci"}, Created: base}},
+			wantPass: true,
+		},
+		{
+			name: "one-off key enrollment only",
+			keys: []keyInfo{{ID: "k1", Tags: []string{"tag:ci"}, Created: base}},
+			devices: []*client.Device{
+				{Name: "runner", Tags: []string{"tag:ci"}, Created: created},
+			},
+			wantPass: true,
+		},
+		{
+			name: "reusable key blast radius",
+			keys: []keyInfo{{ID: "k1", Reusable: true, Preauthorized: true, Tags: []string{"tag:ci"}, Created: base}},
+			devices: []*client.Device{
+				{Name: "runner-1", Tags: []string{"tag:ci"}, Created: created},
+				{Name: "runner-2", Tags: []string{"tag:ci"}, Created: created},
+				{Name: "laptop", User: "alice@example.com", Created: created},
+			},
+			wantPass:  false,
+			wantLines: []string{"Key k1 (reusable, pre-authorized", "2 device(s): runner-1, runner-2"},
+		},
+		{
+			name: "tag not granted by any key",
+			keys: []keyInfo{{ID: "k1", Tags: []string{"tag:ci"}, Created: base}},
+			devices: []*client.Device{
+				{Name: "db", Hostname: "db-1", Tags: []string{"tag:db", "tag:ci"}, Created: created},
+				{Name: "shared", Tags: []string{"tag:other"}, IsExternal: true, Created: created},
+			},
+			wantPass:  false,
+			wantLines: []string{"db (db-1): tag:db"},
+		},
+		{
+			// Other admins' keys may grant tag:db
+			name:    "only the caller's keys listed",
+			keys:    []keyInfo{{ID: "k1", Tags: []string{"tag:ci"}, Created: base}},
+			partial: true,
+			devices: []*client.Device{
+				{Name: "db", Hostname: "db-1", Tags: []string{"tag:db"}, Created: created},
+			},
+			wantPass: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := a.checkKeyEnrollments(tt.keys, tt.devices, !tt.partial)
+			if result.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v", result.Pass, tt.wantPass)
+			}
+			if tt.partial && !strings.Contains(result.Description, "Only your own auth keys") {
+				t.Errorf("Description = %q, want a note that only the caller's keys were compared", result.Description)
+			}
+			details, _ := result.Details.([]string)
+			joined := strings.Join(details, "\n")
+			for _, want := range tt.wantLines {
+				if !strings.Contains(joined, want) {
+					t.Errorf("Details missing %q:\n%s", want, joined)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// maxChangeValueLen is how many characters of an old or new value a change keeps
+const maxChangeValueLen = 200
+
+// roleRank orders user roles by how much of the tailnet they control
+var roleRank = map[string]int{
+	"member":        0,
+	"billing-admin": 1,
+	"auditor":       1,
+	"it-admin":      2,
+	"network-admin": 2,
+	"admin":         3,
+	"owner":         4,
+}
+
+// ChangeCollector reads the configuration audit log and picks out changes
+// that weaken the checks tailsnitch runs
+type ChangeCollector struct {
+	client *client.Client
+
+	// policyEditors are the login names or actor IDs expected to edit the
+	// policy file, such as a GitOps OAuth client. Empty means every policy
+	// edit is reported for review.
+	policyEditors []string
+}
+
+// NewChangeCollector creates a new configuration change collector
+func NewChangeCollector(c *client.Client, policyEditors []string) *ChangeCollector {
+	return &ChangeCollector{client: c, policyEditors: policyEditors}
+}
+
+// Collect fetches configuration audit log events between since and until
+// and returns the risky ones, oldest first
+func (c *ChangeCollector) Collect(ctx context.Context, since, until time.Time) (*types.ConfigChangeReport, error) {
+	events, err := c.client.GetConfigurationLog(ctx, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration audit log: %w", err)
+	}
+
+	report := classifyChanges(events, c.policyEditors)
+	report.Tailnet = c.client.Tailnet()
+	report.GeneratedAt = time.Now()
+	report.Since = since
+	report.Until = until
+	return report, nil
+}
+
+// classifyChanges builds a change report from audit log events
+func classifyChanges(events []client.ConfigLogEvent, policyEditors []string) *types.ConfigChangeReport {
+	report := &types.ConfigChangeReport{
+		EventCount: len(events),
+		Changes:    []types.ConfigChange{},
+	}
+	for _, event := range events {
+		report.Changes = append(report.Changes, classifyChange(event, policyEditors)...)
+	}
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Time.Before(report.Changes[j].Time)
+	})
+	return report
+}
+
+// classifyChange returns a change for each way an event weakens a check.
+// Most events return none.
+func classifyChange(event client.ConfigLogEvent, policyEditors []string) []types.ConfigChange {
+	targetType := strings.ToUpper(event.Target.Type)
+	property := strings.ToUpper(event.Target.Property)
+	verb := strings.ToUpper(event.Verb())
+	details := strings.ToUpper(event.ActionDetails)
+
+	actor := event.Actor.LoginName
+	if actor == "" {
+		actor = event.Actor.ID
+	}
+
+	newChange := func(checkID string, severity types.Severity, reason string) types.ConfigChange {
+		change := types.ConfigChange{
+			Time:       event.EventTime,
+			Actor:      actor,
+			ActorType:  event.Actor.Type,
+			Origin:     event.Origin,
+			Action:     event.Verb(),
+			TargetType: event.Target.Type,
+			Target:     event.Target.Name,
+			Property:   event.Target.Property,
+			Old:        changeValue(event.Old),
+			New:        changeValue(event.New),
+			Reason:     reason,
+			Severity:   severity,
+			CheckID:    checkID,
+			CCCodes:    []string{types.ChangeCCCode},
+		}
+		if change.Target == "" {
+			change.Target = event.Target.ID
+		}
+		for _, check := range types.DefaultRegistry.All() {
+			if check.ID == checkID {
+				change.CheckTitle = check.Title
+				break
+			}
+		}
+		return change
+	}
+
+	switch {
+	// Tailnet Lock changes: enabling, disabling, and signing key changes
+	case containsAny(targetType+" "+property+" "+details, "TAILNET_LOCK", "TAILNET LOCK", "TKA"):
+		if strings.Contains(verb, "DISABLE") || strings.Contains(details, "DISABLE") || isDisabledValue(event.New) {
+			return []types.ConfigChange{newChange("DEV-010", types.High, fmt.Sprintf("Tailnet Lock disabled by %s", actor))}
+		}
+		return []types.ConfigChange{newChange("DEV-010", types.Medium, fmt.Sprintf("Tailnet Lock changed by %s: %s", actor, strings.ToLower(verb)))}
+
+	// Policy file edits
+	case targetType == "POLICY" || targetType == "ACL" || property == "ACL" || property == "POLICY" || property == "POLICY_FILE" || property == "ACLS":
+		if len(policyEditors) == 0 {
+			return []types.ConfigChange{newChange("ACL-001", types.Low, fmt.Sprintf("Policy file edited by %s (no expected editors configured)", actor))}
+		}
+		for _, editor := range policyEditors {
+			if strings.EqualFold(editor, event.Actor.LoginName) || strings.EqualFold(editor, event.Actor.ID) {
+				return nil
+			}
+		}
+		return []types.ConfigChange{newChange("ACL-001", types.Medium, fmt.Sprintf("Policy file edited by unexpected actor %s", actor))}
+
+	// Device and user approval switched off
+	case strings.Contains(property, "APPROVAL") && isDisabledValue(event.New):
+		if strings.Contains(property, "USER") {
+			return []types.ConfigChange{newChange("DEV-009", types.Medium, fmt.Sprintf("User approval disabled by %s", actor))}
+		}
+		return []types.ConfigChange{newChange("DEV-009", types.High, fmt.Sprintf("Device approval disabled by %s", actor))}
+
+	// New auth keys that are reusable or pre-authorized
+	case strings.Contains(targetType, "KEY") && strings.Contains(verb, "CREATE"):
+		var changes []types.ConfigChange
+		if hasTrueField(event.New, "reusable") {
+			changes = append(changes, newChange("AUTH-001", types.Medium, fmt.Sprintf("Reusable auth key created by %s", actor)))
+		}
+		if hasTrueField(event.New, "preauthorized") {
+			changes = append(changes, newChange("AUTH-003", types.Medium, fmt.Sprintf("Pre-authorized auth key created by %s", actor)))
+		}
+		return changes
+
+	// Role escalations
+	case targetType == "USER" && strings.Contains(property, "ROLE"):
+		oldRole, newRole := roleValue(event.Old), roleValue(event.New)
+		if !privilegedRoles[newRole] || roleRank[newRole] <= roleRank[oldRole] {
+			return nil
+		}
+		severity := types.Medium
+		if newRole == "owner" || newRole == "admin" {
+			severity = types.High
+		}
+		from := oldRole
+		if from == "" {
+			from = "no role"
+		}
+		return []types.ConfigChange{newChange("USER-001", severity, fmt.Sprintf("%s raised from %s to %s by %s", event.Target.Name, from, newRole, actor))}
+	}
+
+	return nil
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDisabledValue reports whether a logged value switches a setting off
+func isDisabledValue(raw json.RawMessage) bool {
+	var v interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil {
+		return false
+	}
+	switch val := v.(type) {
+	case bool:
+		return !val
+	case string:
+		switch strings.ToLower(val) {
+		case "false", "off", "disabled", "disable":
+			return true
+		}
+	}
+	return false
+}
+
+// hasTrueField reports whether a logged value sets field to true at any depth,
+// such as capabilities.devices.create.reusable on a new key
+func hasTrueField(raw json.RawMessage, field string) bool {
+	var v interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil {
+		return false
+	}
+	var walk func(interface{}) bool
+	walk = func(v interface{}) bool {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			for k, child := range val {
+				if strings.EqualFold(k, field) {
+					if b, ok := child.(bool); ok && b {
+						return true
+					}
+				}
+				if walk(child) {
+					return true
+				}
+			}
+		case []interface{}:
+			for _, child := range val {
+				if walk(child) {
+					return true
+				}
+			}
+		case string:
+			// Some log versions record the new value as a JSON string
+			if strings.HasPrefix(strings.TrimSpace(val), "{") {
+				return hasTrueField(json.RawMessage(val), field)
+			}
+		}
+		return false
+	}
+	return walk(v)
+}
+
+// roleValue extracts a role from a logged value, either a string or an
+// object with a role field
+func roleValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return strings.ToLower(s)
+	}
+	var obj struct {
+		Role string `json:"role"`
+	}
+	if json.Unmarshal(raw, &obj) == nil {
+		return strings.ToLower(obj.Role)
+	}
+	return ""
+}
+
+// changeValue renders a logged value for the report
+func changeValue(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	value := string(raw)
+	if json.Unmarshal(raw, &s) == nil {
+		value = s
+	}
+	// Truncate by rune so multi-byte characters aren't split
+	if runes := []rune(value); len(runes) > maxChangeValueLen {
+		value = string(runes[:maxChangeValueLen]) + "..."
+	}
+	return value
+}
@@ -0,0 +1,149 @@
+package auditor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func changeEvent(minute int, actor, targetType, property, action, oldValue, newValue string) client.ConfigLogEvent {
+	event := client.ConfigLogEvent{
+		Actor:     client.ConfigLogActor{ID: "id-" + actor, LoginName: actor, Type: "USER"},
+		Target:    client.ConfigLogTarget{ID: "t1", Name: "target", Type: targetType, Property: property},
+		Action:    action,
+		EventTime: time.Date(2026, 10, 18, 12, minute, 0, 0, time.UTC),
+	}
+	if oldValue != "" {
+		event.Old = json.RawMessage(oldValue)
+	}
+	if newValue != "" {
+		event.New = json.RawMessage(newValue)
+	}
+	return event
+}
+
+func TestClassifyChanges(t *testing.T) {
+	events := []client.ConfigLogEvent{
+		changeEvent(9, "carol@example.com", "TAILNET_LOCK", "", "DISABLE", "", ""),
+		changeEvent(1, "alice@example.com", "POLICY", "", "UPDATE", `"{}"`, `"{\"acls\":[]}"`),
+		changeEvent(2, "mallory@example.com", "POLICY", "", "UPDATE", "", ""),
+		changeEvent(3, "alice@example.com", "TAILNET", "DEVICE_APPROVAL", "UPDATE", "true", "false"),
+		changeEvent(4, "alice@example.com", "TAILNET", "USER_APPROVAL", "UPDATE", "true", "false"),
+		changeEvent(5, "alice@example.com", "TAILNET", "DEVICE_APPROVAL", "UPDATE", "false", "true"),
+		changeEvent(6, "bob@example.com", "AUTH_KEY", "", "CREATE", "", `{"capabilities":{"devices":{"create":{"reusable":true,"preauthorized":true}}}}`),
+		changeEvent(7, "bob@example.com", "AUTH_KEY", "", "CREATE", "", `{"capabilities":{"devices":{"create":{"reusable":false}}}}`),
+		changeEvent(8, "alice@example.com", "USER", "ROLE", "UPDATE", `"member"`, `"admin"`),
+		changeEvent(10, "alice@example.com", "USER", "ROLE", "UPDATE", `"admin"`, `"it-admin"`),
+		changeEvent(11, "alice@example.com", "USER", "ROLE", "UPDATE", `{"role":"member"}`, `{"role":"network-admin"}`),
+		changeEvent(12, "alice@example.com", "NODE", "NAME", "UPDATE", `"a"`, `"b"`),
+	}
+
+	report := classifyChanges(events, []string{"alice@example.com"})
+	if report.EventCount != len(events) {
+		t.Errorf("EventCount = %d, want %d", report.EventCount, len(events))
+	}
+
+	want := []struct {
+		CheckID  string
+		Severity types.Severity
+		Actor    string
+	}{
+		{"ACL-001", types.Medium, "mallory@example.com"},
+		{"DEV-009", types.High, "alice@example.com"},
+		{"DEV-009", types.Medium, "alice@example.com"},
+		{"AUTH-001", types.Medium, "bob@example.com"},
+		{"AUTH-003", types.Medium, "bob@example.com"},
+		{"USER-001", types.High, "alice@example.com"},
+		{"DEV-010", types.High, "carol@example.com"},
+		{"USER-001", types.Medium, "alice@example.com"},
+	}
+	if len(report.Changes) != len(want) {
+		for _, c := range report.Changes {
+			t.Logf("%s %s %s: %s", c.Time.Format("15:04"), c.CheckID, c.Severity, c.Reason)
+		}
+		t.Fatalf("got %d changes, want %d", len(report.Changes), len(want))
+	}
+	for i, w := range want {
+		c := report.Changes[i]
+		if c.CheckID != w.CheckID || c.Severity != w.Severity || c.Actor != w.Actor {
+			t.Errorf("change %d = %s %s by %s, want %s %s by %s", i, c.CheckID, c.Severity, c.Actor, w.CheckID, w.Severity, w.Actor)
+		}
+		if c.CheckTitle == "" || len(c.CCCodes) != 1 || c.CCCodes[0] != types.ChangeCCCode {
+			t.Errorf("change %d CheckTitle = %q, CCCodes = %v", i, c.CheckTitle, c.CCCodes)
+		}
+		if i > 0 && c.Time.Before(report.Changes[i-1].Time) {
+			t.Errorf("change %d is out of order", i)
+		}
+	}
+	if report.Changes[5].Old != "member" || report.Changes[5].New != "admin" {
+		t.Errorf("role change Old = %q, New = %q", report.Changes[5].Old, report.Changes[5].New)
+	}
+}
+
+func TestClassifyChangesWithoutPolicyEditors(t *testing.T) {
+	events := []client.ConfigLogEvent{
+		changeEvent(1, "alice@example.com", "POLICY", "", "UPDATE", "", ""),
+		changeEvent(2, "", "TKA", "", "ADD_KEY", "", ""),
+	}
+	events[1].Actor.ID = "k123CNTRL"
+
+	report := classifyChanges(events, nil)
+	if len(report.Changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(report.Changes))
+	}
+	if c := report.Changes[0]; c.CheckID != "ACL-001" || c.Severity != types.Low {
+		t.Errorf("policy edit without editors = %s %s", c.CheckID, c.Severity)
+	}
+	if c := report.Changes[1]; c.CheckID != "DEV-010" || c.Severity != types.Medium || c.Actor != "k123CNTRL" {
+		t.Errorf("Tailnet Lock key change = %s %s by %q", c.CheckID, c.Severity, c.Actor)
+	}
+}
+
+// TestClassifyDocumentedEvent decodes an event in the configuration audit
+// log format (https://tailscale.com/kb/1203/audit-logging): actor.loginName
+// and actor.id name the actor, target.type and target.property name the
+// setting, and old and new hold its values.
+func TestClassifyDocumentedEvent(t *testing.T) {
+	raw := `{
+		"eventGroupID": "8e1b7c0c6c6f4a1e9d3f2a1b0c9d8e7f",
+		"origin": "ADMIN_CONSOLE",
+		"actor": {"id": "uAbCdEf1CNTRL", "type": "USER", "loginName": "mallory@example.com", "displayName": "Mallory"},
+		"target": {"id": "example.com", "name": "example.com", "type": "TAILNET", "property": "ACL"},
+		"type": "UPDATE",
+		"eventTime": "2026-10-12T09:00:00Z",
+		"old": "{\n  \"acls\": []\n}",
+		"new": "{\n  \"acls\": [{\"action\": \"accept\", \"src\": [\"*\"], \"dst\": [\"*:*\"]}]\n}"
+	}`
+	var event client.ConfigLogEvent
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatalf("decoding event: %v", err)
+	}
+
+	report := classifyChanges([]client.ConfigLogEvent{event}, []string{"alice@example.com"})
+	if len(report.Changes) != 1 {
+		t.Fatalf("got %d changes, want 1", len(report.Changes))
+	}
+	c := report.Changes[0]
+	if c.CheckID != "ACL-001" || c.Severity != types.Medium || c.Actor != "mallory@example.com" || c.Origin != "ADMIN_CONSOLE" {
+		t.Errorf("change = %s %s by %q from %q", c.CheckID, c.Severity, c.Actor, c.Origin)
+	}
+	if c.Target != "example.com" || c.Action != "UPDATE" || !strings.Contains(c.New, `"dst": ["*:*"]`) {
+		t.Errorf("change Target = %q, Action = %q, New = %q", c.Target, c.Action, c.New)
+	}
+}
+
+func TestChangeValueTruncatesByRune(t *testing.T) {
+	long, _ := json.Marshal(strings.Repeat("é", maxChangeValueLen+10))
+	value := changeValue(long)
+	if !utf8.ValidString(value) {
+		t.Errorf("changeValue() split a multi-byte character: %q", value)
+	}
+	if want := strings.Repeat("é", maxChangeValueLen) + "..."; value != want {
+		t.Errorf("changeValue() kept %d runes, want %d", utf8.RuneCountInString(value)-3, maxChangeValueLen)
+	}
+}
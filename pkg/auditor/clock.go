@@ -0,0 +1,39 @@
+package auditor
+
+import "time"
+
+// Clock is the time that time-based checks evaluate against. The zero Clock
+// is the current time; AsOfClock evaluates the tailnet as it will look on a
+// future date.
+type Clock struct {
+	asOf time.Time
+}
+
+// AsOfClock returns a clock fixed at t: time-based checks treat t as the
+// current time, and the audit report includes a forecast of expirations
+// between now and t.
+func AsOfClock(t time.Time) Clock {
+	return Clock{asOf: t}
+}
+
+// Now returns the as-of date, or the current time if none is set
+func (c Clock) Now() time.Time {
+	if c.asOf.IsZero() {
+		return time.Now()
+	}
+	return c.asOf
+}
+
+// AsOf returns the as-of date, or zero when evaluating the present
+func (c Clock) AsOf() time.Time {
+	return c.asOf
+}
+
+// asOfPtr returns the as-of date for a report's AsOf field, or nil
+func (c Clock) asOfPtr() *time.Time {
+	if c.asOf.IsZero() {
+		return nil
+	}
+	at := c.asOf
+	return &at
+}
@@ -58,6 +58,26 @@ func SetTailscaleBinaryPath(path string) error {
 	return nil
 }
 
+// lifecyclePolicy sets per-tag, OS and owner-domain thresholds for DEV-004,
+// DEV-008 and AUTH-002. Nil means the built-in defaults.
+var lifecyclePolicy *types.LifecyclePolicy
+
+// SetLifecyclePolicy configures the lifecycle thresholds used by stale device
+// and expiry checks. Pass nil to restore the defaults.
+func SetLifecyclePolicy(policy *types.LifecyclePolicy) {
+	lifecyclePolicy = policy
+}
+
+// namingPolicy sets device naming rules for DEV-007 and extra user device
+// hostname patterns. Nil means the built-in sensitive name patterns only.
+var namingPolicy *types.NamingPolicy
+
+// SetNamingPolicy configures the naming policy used by the machine name
+// checks. Pass nil to restore the defaults.
+func SetNamingPolicy(policy *types.NamingPolicy) {
+	namingPolicy = policy
+}
+
 // findTailscaleBinary locates the tailscale binary using known safe paths.
 // This prevents PATH hijacking attacks by checking specific directories.
 // If a custom path was set via SetTailscaleBinaryPath, that is used instead.
@@ -115,7 +135,7 @@ func findTailscaleBinary() (string, error) {
 // Per Tailscale docs, auto-updates take ~7 days to roll out, so we apply a grace period
 // and only consider releases older than 7 days as the "expected" version.
 // Returns the full version string (e.g., "v1.76.6") and parsed major/minor for comparison.
-func getLatestTailscaleVersion(ctx context.Context) (versionStr string, major, minor int, ok bool) {
+func getLatestTailscaleVersion(ctx context.Context, now time.Time) (versionStr string, major, minor int, ok bool) {
 	// Fetch recent releases (not just latest) so we can apply grace period
 	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/repos/tailscale/tailscale/releases?per_page=10", nil)
 	if err != nil {
@@ -142,8 +162,7 @@ func getLatestTailscaleVersion(ctx context.Context) (versionStr string, major, m
 		return "", 0, 0, false
 	}
 
-	versionRegex := regexp.MustCompile(`v?(\d+)\.(\d+)`)
-	gracePeriod := 7 * 24 * time.Hour
+	gracePeriod := autoUpdateGracePeriod
 
 	// Find the first non-prerelease that's older than the grace period
 	for _, release := range releases {
@@ -157,7 +176,7 @@ func getLatestTailscaleVersion(ctx context.Context) (versionStr string, major, m
 		}
 
 		// Check if release is older than grace period
-		if time.Since(publishedAt) >= gracePeriod {
+		if now.Sub(publishedAt) >= gracePeriod {
 			major, minor, ok = parseVersion(release.TagName, versionRegex)
 			if ok {
 				return release.TagName, major, minor, true
@@ -168,6 +187,10 @@ func getLatestTailscaleVersion(ctx context.Context) (versionStr string, major, m
 	return "", 0, 0, false
 }
 
+// versionRegex matches the major and minor parts of a Tailscale version,
+// with or without a leading "v"
+var versionRegex = regexp.MustCompile(`v?(\d+)\.(\d+)`)
+
 // parseVersion extracts major and minor version numbers from a version string
 func parseVersion(versionStr string, versionRegex *regexp.Regexp) (major, minor int, ok bool) {
 	matches := versionRegex.FindStringSubmatch(versionStr)
@@ -189,20 +212,21 @@ func parseVersion(versionStr string, versionRegex *regexp.Regexp) (major, minor
 // DeviceAuditor checks for device security issues
 type DeviceAuditor struct {
 	client *client.Client
+	clock  Clock
 }
 
 // NewDeviceAuditor creates a new device auditor
-func NewDeviceAuditor(c *client.Client) *DeviceAuditor {
-	return &DeviceAuditor{client: c}
+func NewDeviceAuditor(c *client.Client, clock Clock) *DeviceAuditor {
+	return &DeviceAuditor{client: c, clock: clock}
 }
 
 // Audit performs device-related security checks
-func (d *DeviceAuditor) Audit(ctx context.Context) ([]types.Suggestion, error) {
+func (d *DeviceAuditor) Audit(ctx context.Context, data *TailnetData) ([]types.Suggestion, error) {
 	var findings []types.Suggestion
 
-	devices, err := d.client.GetDevices(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get devices: %w", err)
+	devices := data.Devices
+	if data.DevicesErr != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", data.DevicesErr)
 	}
 
 	// Fetch DNS config for checks that need it (DEV-007)
@@ -233,21 +257,42 @@ func (d *DeviceAuditor) Audit(ctx context.Context) ([]types.Suggestion, error) {
 	// DEV-008: Long key expiry (default 180 days)
 	findings = append(findings, d.checkLongKeyExpiry(devices))
 
-	// DEV-009: Device approval configuration
-	findings = append(findings, d.checkDeviceApproval(devices))
+	// DEV-009: Device and user approval settings; on error approval is
+	// inferred from pending devices
+	findings = append(findings, d.checkDeviceApproval(data.Settings, data.SettingsErr, devices))
+
+	// DEV-010 and DEV-012 read Tailnet Lock state from the local node, which
+	// applies only if that node is a device in the audited tailnet
+	lock, lockErr := readLocalLock(ctx)
 
 	// DEV-010: Tailnet Lock status
-	findings = append(findings, d.checkTailnetLock(ctx))
+	findings = append(findings, d.checkTailnetLock(lock, lockErr, devices))
 
 	// DEV-011: Unique users in tailnet
 	findings = append(findings, d.checkUniqueUsers(devices))
 
 	// DEV-012: Nodes awaiting Tailnet Lock signature
-	findings = append(findings, d.checkTailnetLockPending(ctx))
+	findings = append(findings, d.checkTailnetLockPending(lock, lockErr, devices))
 
 	// DEV-013: User devices with key expiry disabled
 	findings = append(findings, d.checkUserDevicesKeyExpiryDisabled(devices))
 
+	// DEV-014 to DEV-017 read device posture attributes. Devices whose
+	// attributes can't be read are listed in each finding as unchecked.
+	posture, unavailable := fetchDevicePosture(ctx, d.client, devices)
+
+	// DEV-014: End-of-life operating systems
+	findings = append(findings, notePostureUnavailable(d.checkEOLPlatforms(devices, osVersionsFrom(posture), d.clock.Now()), unavailable))
+
+	// DEV-015: Devices missing expected posture attributes
+	findings = append(findings, notePostureUnavailable(d.checkMissingPosture(devices, posture), unavailable))
+
+	// DEV-016: Stale device posture data
+	findings = append(findings, notePostureUnavailable(d.checkStalePosture(devices, posture, d.clock.Now()), unavailable))
+
+	// DEV-017: Devices failing posture conditions
+	findings = append(findings, notePostureUnavailable(d.checkFailedPosture(devices, posture), unavailable))
+
 	return findings, nil
 }
 
@@ -392,6 +437,68 @@ func (d *DeviceAuditor) checkUserDevicesWithTags(devices []*client.Device) types
 	return finding
 }
 
+// expectedClientVersion returns the version devices are expected to run: the
+// latest stable release past its auto-update grace period, taken from GitHub,
+// then the bundled release data when offline, then the newest version seen in
+// the tailnet.
+func expectedClientVersion(ctx context.Context, devices []*client.Device, now time.Time) (versionStr string, major, minor int) {
+	// Try to get the stable version from GitHub releases (with 7-day grace period for auto-update rollout)
+	versionStr, major, minor, gotLatest := getLatestTailscaleVersion(ctx, now)
+	if gotLatest {
+		return versionStr, major, minor
+	}
+
+	// Offline: use the release data shipped with (or passed to) tailsnitch
+	db := loadVersionDB()
+	if release, v, ok := db.expectedRelease(now); ok {
+		return fmt.Sprintf("v%s (release data %s)", release.Version, db.Updated), v[0], v[1]
+	}
+
+	// Fallback: find the latest version among all devices
+	for _, dev := range devices {
+		if dev.ClientVersion == "" {
+			continue
+		}
+		if devMajor, devMinor, ok := parseVersion(dev.ClientVersion, versionRegex); ok {
+			if devMajor > major || (devMajor == major && devMinor > minor) {
+				major = devMajor
+				minor = devMinor
+				versionStr = dev.ClientVersion
+			}
+		}
+	}
+	if versionStr != "" {
+		versionStr = versionStr + " (from tailnet)"
+	}
+	return versionStr, major, minor
+}
+
+// minorVersionsBehind returns how many minor versions a device trails the
+// expected version, and whether that counts as outdated (more than 2 behind)
+func minorVersionsBehind(dev *client.Device, latestMajor, latestMinor int) (int, bool) {
+	if dev.ClientVersion == "" {
+		return 0, false
+	}
+
+	major, minor, ok := parseVersion(dev.ClientVersion, versionRegex)
+	if !ok {
+		return 0, false
+	}
+
+	// Skip devices that are at or ahead of the expected version
+	if major > latestMajor || (major == latestMajor && minor >= latestMinor) {
+		return 0, false
+	}
+
+	versionsBehind := latestMinor - minor
+	if major < latestMajor {
+		versionsBehind = latestMinor + (latestMajor-major)*100 - minor // rough estimate for major version diff
+	}
+
+	// Flag if more than 2 minor versions behind the expected version
+	return versionsBehind, major < latestMajor || latestMinor-minor > 2
+}
+
 func (d *DeviceAuditor) checkOutdatedClients(ctx context.Context, devices []*client.Device) types.Suggestion {
 	finding := types.Suggestion{
 		ID:          "DEV-003",
@@ -404,52 +511,12 @@ func (d *DeviceAuditor) checkOutdatedClients(ctx context.Context, devices []*cli
 		Pass:        true,
 	}
 
-	versionRegex := regexp.MustCompile(`(\d+)\.(\d+)`)
-
-	// Try to get the stable version from GitHub releases (with 7-day grace period for auto-update rollout)
-	latestVersionStr, latestMajor, latestMinor, gotLatest := getLatestTailscaleVersion(ctx)
-	if !gotLatest {
-		// Fallback: find the latest version among all devices
-		for _, dev := range devices {
-			if dev.ClientVersion == "" {
-				continue
-			}
-			if major, minor, ok := parseVersion(dev.ClientVersion, versionRegex); ok {
-				if major > latestMajor || (major == latestMajor && minor > latestMinor) {
-					latestMajor = major
-					latestMinor = minor
-					latestVersionStr = dev.ClientVersion
-				}
-			}
-		}
-		if latestVersionStr != "" {
-			latestVersionStr = latestVersionStr + " (from tailnet)"
-		}
-	}
+	latestVersionStr, latestMajor, latestMinor := expectedClientVersion(ctx, devices, d.clock.Now())
 
 	// Check for devices significantly older than the expected version
 	var outdatedDevices []string
 	for _, dev := range devices {
-		if dev.ClientVersion == "" {
-			continue
-		}
-
-		major, minor, ok := parseVersion(dev.ClientVersion, versionRegex)
-		if !ok {
-			continue
-		}
-
-		// Skip devices that are at or ahead of the expected version
-		if major > latestMajor || (major == latestMajor && minor >= latestMinor) {
-			continue
-		}
-
-		// Flag if more than 2 minor versions behind the expected version
-		if major < latestMajor || (major == latestMajor && latestMinor-minor > 2) {
-			versionsBehind := latestMinor - minor
-			if major < latestMajor {
-				versionsBehind = latestMinor + (latestMajor-major)*100 - minor // rough estimate for major version diff
-			}
+		if versionsBehind, outdated := minorVersionsBehind(dev, latestMajor, latestMinor); outdated {
 			outdatedDevices = append(outdatedDevices, fmt.Sprintf("%s (%s): %s (%d minor versions behind)", dev.Name, dev.Hostname, dev.ClientVersion, versionsBehind))
 		}
 	}
@@ -491,6 +558,40 @@ func (d *DeviceAuditor) checkOutdatedClients(ctx context.Context, devices []*cli
 		finding.Description += fmt.Sprintf(" Additionally, %d device(s) are running clients older than v1.34 and cannot generate network flow logs.", len(oldClientsNoFlowLogs))
 	}
 
+	// Match client versions against known security bulletins
+	hits, affected := bulletinHits(devices)
+	if len(hits) > 0 {
+		finding.Pass = false
+		if severity := bulletinSeverity(hits); severity.Order() < finding.Severity.Order() {
+			finding.Severity = severity
+		}
+		details, _ := finding.Details.([]string)
+		for _, b := range hits {
+			details = append(details, fmt.Sprintf("%s [%s] %s (fixed in %s): %s",
+				b.ID, b.Severity, b.Title, b.Fixed, strings.Join(affected[b.ID], ", ")))
+		}
+		finding.Details = details
+		finding.Description = strings.TrimSpace(finding.Description + fmt.Sprintf(" %d known security bulletin(s) affect devices in this tailnet.", len(hits)))
+		if finding.Fix == nil {
+			finding.Fix = &types.FixInfo{
+				Type:        types.FixTypeManual,
+				Description: "Update affected clients to at least the fixed version listed for each bulletin",
+				AdminURL:    "https://login.tailscale.com/admin/machines",
+				DocURL:      "https://tailscale.com/security-bulletins",
+			}
+		}
+	}
+
+	// State how current the bulletin data is, so a pass isn't read as
+	// covering advisories the data doesn't include
+	db := loadVersionDB()
+	if through, stale := db.bulletinCoverage(); through != "" {
+		finding.Description += fmt.Sprintf(" Security bulletin data (updated %s) covers bulletins published through %s.", db.Updated, through)
+		if stale {
+			finding.Description += " Releases after that date are listed, so newer bulletins may be missing; pass current data with --version-db."
+		}
+	}
+
 	return finding
 }
 
@@ -500,14 +601,17 @@ func (d *DeviceAuditor) checkStaleDevices(devices []*client.Device) types.Sugges
 		Title:       "Stale devices not seen recently",
 		Severity:    types.Medium,
 		Category:    types.DeviceSecurity,
-		Description: "Devices not seen in over 60 days may be unused and should be reviewed for removal.",
+		Description: "Devices not seen within their lifecycle stale threshold may be unused and should be reviewed for removal.",
 		Remediation: "Review and remove unused devices. Implement device lifecycle policies.",
 		Source:      "https://tailscale.com/kb/1068/tags",
 		Pass:        true,
 	}
 
-	staleThreshold := time.Now().AddDate(0, 0, -60)
+	now := d.clock.Now()
 	var staleDevices []string
+	var fixableItems []types.FixableItem
+	customRules := false
+	autoFixSafe := true
 
 	for _, dev := range devices {
 		if dev.LastSeen == "" {
@@ -519,42 +623,47 @@ func (d *DeviceAuditor) checkStaleDevices(devices []*client.Device) types.Sugges
 			continue
 		}
 
-		if lastSeen.Before(staleThreshold) {
-			daysSince := int(time.Since(lastSeen).Hours() / 24)
-			staleDevices = append(staleDevices, fmt.Sprintf("%s (%s): last seen %d days ago", dev.Name, dev.Hostname, daysSince))
+		// Each device is held to the threshold of its lifecycle rule
+		rule := lifecyclePolicy.DeviceRule(dev.Tags, dev.OS, dev.User)
+		thresholdDays := rule.StaleThresholdDays()
+		if !lastSeen.Before(now.AddDate(0, 0, -thresholdDays)) {
+			continue
 		}
+
+		daysSince := int(now.Sub(lastSeen).Hours() / 24)
+		line := fmt.Sprintf("%s (%s): last seen %d days ago", dev.Name, dev.Hostname, daysSince)
+		if !rule.IsDefault() {
+			customRules = true
+			line += fmt.Sprintf(" (rule %q: >%d days)", rule.Name, thresholdDays)
+		}
+		staleDevices = append(staleDevices, line)
+
+		// Only devices past the default threshold are safe to remove unattended
+		if daysSince <= types.DefaultStaleDays {
+			autoFixSafe = false
+		}
+		fixableItems = append(fixableItems, types.FixableItem{
+			ID:          dev.DeviceID,
+			Name:        dev.Name,
+			Description: fmt.Sprintf("%s - last seen %d days ago", dev.Hostname, daysSince),
+		})
 	}
 
 	if len(staleDevices) > 0 {
 		finding.Pass = false
 		finding.Details = staleDevices
-		finding.Description = fmt.Sprintf("Found %d device(s) not seen in over 60 days.", len(staleDevices))
-
-		// Build fixable items
-		var fixableItems []types.FixableItem
-		for _, dev := range devices {
-			if dev.LastSeen == "" {
-				continue
-			}
-			lastSeen, err := time.Parse(time.RFC3339, dev.LastSeen)
-			if err != nil {
-				continue
-			}
-			if lastSeen.Before(staleThreshold) {
-				daysSince := int(time.Since(lastSeen).Hours() / 24)
-				fixableItems = append(fixableItems, types.FixableItem{
-					ID:          dev.DeviceID,
-					Name:        dev.Name,
-					Description: fmt.Sprintf("%s - last seen %d days ago", dev.Hostname, daysSince),
-				})
-			}
+		if customRules {
+			finding.Description = fmt.Sprintf("Found %d device(s) not seen within their lifecycle threshold (default %d days).", len(staleDevices), types.DefaultStaleDays)
+		} else {
+			finding.Description = fmt.Sprintf("Found %d device(s) not seen in over %d days.", len(staleDevices), types.DefaultStaleDays)
 		}
+
 		finding.Fix = &types.FixInfo{
 			Type:        types.FixTypeAPI,
 			Description: "Remove stale devices that are no longer in use",
 			AdminURL:    "https://login.tailscale.com/admin/machines",
 			Items:       fixableItems,
-			AutoFixSafe: true, // Safe to remove devices not seen in 60+ days
+			AutoFixSafe: autoFixSafe, // Safe only if every device is past the default stale threshold, not just a shorter lifecycle rule
 		}
 	}
 
@@ -656,33 +765,21 @@ func (d *DeviceAuditor) checkSensitiveMachineNames(devices []*client.Device, dns
 		Pass:        true,
 	}
 
-	// This check only applies when MagicDNS is enabled, since that's when
-	// machine names appear in HTTPS certificates and CT logs
-	if dnsConfig == nil || !dnsConfig.MagicDNS {
+	// Without a naming policy this check only applies when MagicDNS is
+	// enabled, since that's when machine names appear in HTTPS certificates
+	// and CT logs. A configured policy is enforced either way.
+	magicDNS := dnsConfig != nil && dnsConfig.MagicDNS
+	if !magicDNS && namingPolicy == nil {
 		finding.Pass = true
 		finding.Description = "MagicDNS is disabled. Machine names are not exposed in HTTPS certificates or CT logs."
 		finding.Details = "This check is skipped because MagicDNS is not enabled."
 		return finding
 	}
 
-	// Patterns that might indicate sensitive info in machine names
-	sensitivePatterns := []*regexp.Regexp{
-		regexp.MustCompile(`(?i)(password|passwd|pwd|secret|token|key|cred)`),
-		regexp.MustCompile(`(?i)(prod|production|staging|dev)[-_]?(db|database|mysql|postgres|mongo|redis)`),
-		regexp.MustCompile(`(?i)(internal|private|confidential)`),
-		regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`), // IP addresses
-		regexp.MustCompile(`(?i)(admin|root|superuser)`),
-		regexp.MustCompile(`(?i)(api[-_]?key|access[-_]?key|auth[-_]?key)`),
-		regexp.MustCompile(`(?i)(ssn|social[-_]?security|credit[-_]?card)`),
-	}
-
 	var sensitiveNames []string
 	for _, dev := range devices {
-		for _, pattern := range sensitivePatterns {
-			if pattern.MatchString(dev.Name) || pattern.MatchString(dev.Hostname) {
-				sensitiveNames = append(sensitiveNames, fmt.Sprintf("%s (%s)", dev.Name, dev.Hostname))
-				break
-			}
+		if reasons := namingPolicy.Violations(dev.Name, dev.Hostname, dev.Tags); len(reasons) > 0 {
+			sensitiveNames = append(sensitiveNames, fmt.Sprintf("%s (%s): %s", dev.Name, dev.Hostname, strings.Join(reasons, "; ")))
 		}
 	}
 
@@ -690,9 +787,15 @@ func (d *DeviceAuditor) checkSensitiveMachineNames(devices []*client.Device, dns
 		finding.Pass = false
 		finding.Details = sensitiveNames
 		finding.Description = fmt.Sprintf("Found %d device(s) with potentially sensitive information in names. These may be exposed in CT logs when HTTPS certificates are requested.", len(sensitiveNames))
+		if namingPolicy != nil {
+			finding.Description = fmt.Sprintf("Found %d device(s) with names that violate the naming policy or contain potentially sensitive information.", len(sensitiveNames))
+			if magicDNS {
+				finding.Description += " These may be exposed in CT logs when HTTPS certificates are requested."
+			}
+		}
 		finding.Fix = &types.FixInfo{
 			Type:        types.FixTypeManual,
-			Description: "Rename devices to remove sensitive information",
+			Description: "Rename devices to remove sensitive information and match the naming policy",
 			AdminURL:    "https://login.tailscale.com/admin/machines",
 			DocURL:      "https://tailscale.com/kb/1153/enabling-https",
 		}
@@ -736,7 +839,8 @@ func isDevDevice(dev *client.Device) bool {
 		}
 	}
 
-	return false
+	// Organization-specific hostname conventions from the naming policy
+	return namingPolicy.IsUserDeviceHostname(dev.Hostname)
 }
 
 func (d *DeviceAuditor) checkLongKeyExpiry(devices []*client.Device) types.Suggestion {
@@ -752,13 +856,14 @@ func (d *DeviceAuditor) checkLongKeyExpiry(devices []*client.Device) types.Sugge
 	}
 
 	const (
-		devDeviceMaxDays = 90  // Dev devices should expire within
-		serverMaxDays    = 180 // Servers can have up to
+		devDeviceMaxDays = types.DefaultDevKeyExpiryDays // Dev devices should expire within
+		serverMaxDays    = types.DefaultKeyExpiryDays    // Servers can have up to
 	)
 
 	var devDeviceLongExpiry []string
 	var serverLongExpiry []string
-	now := time.Now()
+	var ruleLongExpiry []string
+	now := d.clock.Now()
 
 	for _, dev := range devices {
 		if dev.KeyExpiryDisabled {
@@ -776,6 +881,16 @@ func (d *DeviceAuditor) checkLongKeyExpiry(devices []*client.Device) types.Sugge
 
 		daysUntilExpiry := int(expires.Sub(now).Hours() / 24)
 
+		// A lifecycle rule with its own limit replaces the dev/server split
+		if rule := lifecyclePolicy.DeviceRule(dev.Tags, dev.OS, dev.User); rule.KeyExpiryDays > 0 {
+			if daysUntilExpiry > rule.KeyExpiryDays {
+				ruleLongExpiry = append(ruleLongExpiry,
+					fmt.Sprintf("%s (%s): expires in %d days (rule %q: ≤%d)",
+						dev.Name, dev.Hostname, daysUntilExpiry, rule.Name, rule.KeyExpiryDays))
+			}
+			continue
+		}
+
 		if isDevDevice(dev) {
 			if daysUntilExpiry > devDeviceMaxDays {
 				devDeviceLongExpiry = append(devDeviceLongExpiry,
@@ -803,13 +918,25 @@ func (d *DeviceAuditor) checkLongKeyExpiry(devices []*client.Device) types.Sugge
 		allLongExpiry = append(allLongExpiry, "Servers (should be longer :")
 		allLongExpiry = append(allLongExpiry, serverLongExpiry...)
 	}
+	if len(ruleLongExpiry) > 0 {
+		if len(allLongExpiry) > 0 {
+			allLongExpiry = append(allLongExpiry, "")
+		}
+		allLongExpiry = append(allLongExpiry, "Lifecycle rules:")
+		allLongExpiry = append(allLongExpiry, ruleLongExpiry...)
+	}
 
 	if len(allLongExpiry) > 0 {
 		finding.Pass = false
 		finding.Details = allLongExpiry
 
-		// Dev devices with long expiry are higher severity
-		if len(devDeviceLongExpiry) > 0 {
+		// Dev devices with long expiry are higher severity, as are devices
+		// exceeding a limit the organization set explicitly
+		if len(ruleLongExpiry) > 0 {
+			finding.Severity = types.Medium
+			finding.Description = fmt.Sprintf("Found %d device(s) exceeding their lifecycle rule's key expiry, %d dev device(s) with key expiry >%d days and %d server(s) with expiry >%d days.",
+				len(ruleLongExpiry), len(devDeviceLongExpiry), devDeviceMaxDays, len(serverLongExpiry), serverMaxDays)
+		} else if len(devDeviceLongExpiry) > 0 {
 			finding.Severity = types.Medium
 			finding.Description = fmt.Sprintf("Found %d dev device(s) with key expiry >%d days and %d server(s) with expiry >%d days.",
 				len(devDeviceLongExpiry), devDeviceMaxDays, len(serverLongExpiry), serverMaxDays)
@@ -829,7 +956,7 @@ func (d *DeviceAuditor) checkLongKeyExpiry(devices []*client.Device) types.Sugge
 	return finding
 }
 
-func (d *DeviceAuditor) checkDeviceApproval(devices []*client.Device) types.Suggestion {
+func (d *DeviceAuditor) checkDeviceApproval(settings *client.TailnetSettings, settingsErr error, devices []*client.Device) types.Suggestion {
 	finding := types.Suggestion{
 		ID:          "DEV-009",
 		Title:       "Device approval configuration",
@@ -841,7 +968,8 @@ func (d *DeviceAuditor) checkDeviceApproval(devices []*client.Device) types.Sugg
 		Pass:        true,
 	}
 
-	// Count unauthorized vs authorized devices to infer if device approval is enabled
+	// Count unauthorized vs authorized devices as evidence, or to infer the
+	// setting when it can't be read
 	authorized := 0
 	unauthorized := 0
 	for _, dev := range devices {
@@ -852,29 +980,70 @@ func (d *DeviceAuditor) checkDeviceApproval(devices []*client.Device) types.Sugg
 		}
 	}
 
-	// If all devices are authorized and there are many devices, device approval might not be enabled
-	// This is a heuristic - we can't directly check the setting via API
-	if unauthorized == 0 && authorized > 5 {
+	if settingsErr != nil || settings == nil {
+		// If all devices are authorized and there are many devices, device approval might not be enabled
+		if unauthorized == 0 && authorized > 5 {
+			finding.Pass = false
+			finding.Severity = types.Informational
+			finding.Description = fmt.Sprintf("All %d devices are authorized. Verify device approval is enabled in admin console - if not, new devices join automatically without review.", authorized)
+			finding.Details = []string{
+				"MANUAL CHECK REQUIRED: Verify device approval is enabled in Device management settings.",
+				fmt.Sprintf("Cannot read tailnet settings: %v", settingsErr),
+				"Grant the API key or OAuth client the feature_settings:read scope for a definitive result.",
+			}
+			finding.Fix = &types.FixInfo{
+				Type:        types.FixTypeManual,
+				Description: "Enable device approval in Device management settings",
+				AdminURL:    "https://login.tailscale.com/admin/settings/device-management",
+				DocURL:      "https://tailscale.com/kb/1099/device-authorization",
+			}
+		} else if unauthorized > 0 {
+			// Device approval is working - there are pending devices
+			finding.Description = fmt.Sprintf("Device approval appears active: %d authorized, %d pending approval.", authorized, unauthorized)
+		}
+		return finding
+	}
+
+	onOff := map[bool]string{true: "on", false: "off"}
+	finding.Details = []string{
+		fmt.Sprintf("Device approval (devicesApprovalOn): %s", onOff[settings.DevicesApprovalOn]),
+		fmt.Sprintf("User approval (usersApprovalOn): %s", onOff[settings.UsersApprovalOn]),
+		fmt.Sprintf("Default node key expiry (devicesKeyDurationDays): %d days", settings.DevicesKeyDurationDays),
+		fmt.Sprintf("Devices: %d authorized, %d pending approval", authorized, unauthorized),
+	}
+
+	switch {
+	case !settings.DevicesApprovalOn:
 		finding.Pass = false
-		finding.Severity = types.Informational
-		finding.Description = fmt.Sprintf("All %d devices are authorized. Verify device approval is enabled in admin console - if not, new devices join automatically without review.", authorized)
-		finding.Details = "MANUAL CHECK REQUIRED: Verify device approval is enabled in Device management settings."
+		finding.Description = "Device approval is disabled. New devices join the tailnet as soon as a user logs in, without admin review."
 		finding.Fix = &types.FixInfo{
 			Type:        types.FixTypeManual,
 			Description: "Enable device approval in Device management settings",
 			AdminURL:    "https://login.tailscale.com/admin/settings/device-management",
 			DocURL:      "https://tailscale.com/kb/1099/device-authorization",
 		}
-	} else if unauthorized > 0 {
-		// Device approval is working - there are pending devices
-		finding.Pass = true
-		finding.Description = fmt.Sprintf("Device approval appears active: %d authorized, %d pending approval.", authorized, unauthorized)
+	case !settings.UsersApprovalOn:
+		finding.Pass = false
+		finding.Severity = types.Low
+		finding.Description = "Device approval is enabled, but user approval is disabled. Anyone who can sign in with the identity provider joins the tailnet as a user without admin review."
+		finding.Remediation = "Enable user approval in User management settings so new users are reviewed before they can add devices."
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Enable user approval in User management settings",
+			AdminURL:    "https://login.tailscale.com/admin/settings/user-management",
+			DocURL:      "https://tailscale.com/kb/1239/user-approval",
+		}
+	default:
+		finding.Description = fmt.Sprintf("Device and user approval are enabled: %d devices authorized, %d pending approval.", authorized, unauthorized)
+	}
+	if settings.DevicesKeyDurationDays > 0 {
+		finding.Description += fmt.Sprintf(" New node keys expire after %d days by default.", settings.DevicesKeyDurationDays)
 	}
 
 	return finding
 }
 
-func (d *DeviceAuditor) checkTailnetLock(ctx context.Context) types.Suggestion {
+func (d *DeviceAuditor) checkTailnetLock(lock *localLock, lockErr error, devices []*client.Device) types.Suggestion {
 	finding := types.Suggestion{
 		ID:          "DEV-010",
 		Title:       "Tailnet Lock not enabled",
@@ -886,166 +1055,110 @@ func (d *DeviceAuditor) checkTailnetLock(ctx context.Context) types.Suggestion {
 		Pass:        true,
 	}
 
-	// Find tailscale binary using secure path resolution
-	tsBinary, err := findTailscaleBinary()
-	if err != nil {
+	if lockErr != nil {
 		finding.Pass = false
 		finding.Severity = types.Informational
-		finding.Description = "Cannot check Tailnet Lock status: " + err.Error()
+		finding.Description = "Cannot check Tailnet Lock status: " + lockErr.Error()
 		finding.Details = []string{
-			"The tailscale CLI binary could not be located securely.",
-			"",
-			"NOTE: This check runs on the LOCAL machine and may not reflect",
-			"the status of the tailnet being audited via --tailnet flag.",
+			"Tailnet Lock status is read from the local tailscale CLI or tailscaled LocalAPI.",
 			"",
-			"To check Tailnet Lock status manually:",
-			"  1. Install Tailscale CLI: https://tailscale.com/download",
-			"  2. Run: tailscale lock status",
+			"Possible causes:",
+			"  - Tailscale not installed (https://tailscale.com/download)",
+			"  - Tailscale daemon not running (start with: sudo tailscaled)",
+			"  - Insufficient permissions (try running as root/admin)",
+			"  - CLI in a non-standard location (use --tailscale-path)",
 			"",
-			"See: https://tailscale.com/kb/1226/tailnet-lock",
+			"To check manually, run on a node in the tailnet: tailscale lock status",
 		}
 		finding.Fix = &types.FixInfo{
 			Type:        types.FixTypeExternal,
-			Description: "Install tailscale CLI and run 'tailscale lock init'",
+			Description: "Verify tailscale daemon is running and check 'tailscale lock status'",
 			DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
 		}
 		return finding
 	}
 
-	// Try to run tailscale lock status
-	cmd := exec.CommandContext(ctx, tsBinary, "lock", "status")
-	output, err := cmd.CombinedOutput()
-
-	if err != nil {
-		// Check if it's a "command not found" error
-		if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
-			finding.Pass = false
-			finding.Severity = types.Informational
-			finding.Description = "Cannot check Tailnet Lock status: tailscale CLI not found."
-			finding.Details = []string{
-				"The tailscale CLI binary was not found in PATH.",
-				"",
-				"To check Tailnet Lock status manually:",
-				"  1. Install Tailscale CLI: https://tailscale.com/download",
-				"  2. Run: tailscale lock status",
-				"",
-				"To enable Tailnet Lock:",
-				"  1. Ensure tailscale CLI is installed on a trusted node",
-				"  2. Run: tailscale lock init",
-				"  3. Add signing keys from other trusted nodes",
-				"",
-				"See: https://tailscale.com/kb/1226/tailnet-lock",
-			}
-			finding.Fix = &types.FixInfo{
-				Type:        types.FixTypeExternal,
-				Description: "Install tailscale CLI and run 'tailscale lock init'",
-				DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
-			}
-			return finding
+	self := localDevice(lock.Status, devices)
+	if self == nil {
+		finding.Pass = false
+		finding.Severity = types.Informational
+		finding.Description = "Cannot check Tailnet Lock status: the local node is not part of the audited tailnet."
+		finding.Details = foreignNodeDetails(lock)
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeExternal,
+			Description: "Check 'tailscale lock status' on a node in the audited tailnet",
+			DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
 		}
+		return finding
+	}
 
-		// Check for exit status indicating lock is not enabled
-		outputStr := string(output)
-
-		// "tailscale lock status" returns exit code 1 with specific message when not enabled
-		if strings.Contains(outputStr, "disabled") ||
-			strings.Contains(outputStr, "not enabled") ||
-			strings.Contains(outputStr, "Tailnet lock is NOT enabled") {
-			finding.Pass = false
-			finding.Description = "Tailnet Lock is not enabled. Attackers with stolen auth keys can add unauthorized devices."
-			finding.Details = []string{
-				"Tailnet Lock prevents unauthorized device additions even if auth keys are compromised.",
-				"",
-				"Current status: DISABLED",
-				"",
-				"To enable Tailnet Lock:",
-				"  1. On a trusted node, run: tailscale lock init",
-				"  2. This generates a signing key for that node",
-				"  3. Add signing keys from additional trusted nodes: tailscale lock add <nodekey>",
-				"  4. Once enabled, new devices require signatures from existing trusted nodes",
-				"",
-				"WARNING: Enabling Tailnet Lock is a significant security change.",
-				"Ensure you understand the key rotation and recovery procedures.",
-			}
-			finding.Fix = &types.FixInfo{
-				Type:        types.FixTypeExternal,
-				Description: "Enable Tailnet Lock by running 'tailscale lock init' on a trusted node",
-				DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
-			}
-			return finding
-		}
+	st := lock.Status
+	details := lockIdentityDetails(lock, self)
 
-		// Other errors - connection issues, permission denied, etc.
+	if !st.Enabled {
 		finding.Pass = false
-		finding.Severity = types.Informational
-		finding.Description = "Cannot determine Tailnet Lock status due to an error."
-		finding.Details = []string{
-			fmt.Sprintf("Error running 'tailscale lock status': %v", err),
-			fmt.Sprintf("Output: %s", strings.TrimSpace(outputStr)),
+		finding.Description = "Tailnet Lock is not enabled. Attackers with stolen auth keys can add unauthorized devices."
+		finding.Details = append(details,
 			"",
-			"Possible causes:",
-			"  - Tailscale daemon not running (start with: sudo tailscaled)",
-			"  - Insufficient permissions (try running as root/admin)",
-			"  - Network connectivity issues",
+			"Current status: DISABLED",
 			"",
-			"To check manually, run: tailscale lock status",
-		}
+			"To enable Tailnet Lock:",
+			"  1. On a trusted node, run: tailscale lock init",
+			"  2. This generates a signing key for that node",
+			"  3. Add signing keys from additional trusted nodes: tailscale lock add <nodekey>",
+			"  4. Once enabled, new devices require signatures from existing trusted nodes",
+			"",
+			"WARNING: Enabling Tailnet Lock is a significant security change.",
+			"Ensure you understand the key rotation and recovery procedures.",
+		)
 		finding.Fix = &types.FixInfo{
 			Type:        types.FixTypeExternal,
-			Description: "Verify tailscale daemon is running and check 'tailscale lock status'",
+			Description: "Enable Tailnet Lock by running 'tailscale lock init' on a trusted node",
 			DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
 		}
 		return finding
 	}
 
-	// Command succeeded - parse output
-	outputStr := string(output)
-
-	// Check if lock is enabled
-	if strings.Contains(outputStr, "enabled") ||
-		strings.Contains(outputStr, "Tailnet lock is enabled") {
-		finding.Pass = true
-		finding.Description = "Tailnet Lock is enabled (local check). Devices require cryptographic signing from trusted nodes."
-
-		// Extract some useful info if available
-		var details []string
-		details = append(details, "Status: ENABLED (checked via local tailscale CLI)")
-		details = append(details, "")
-		details = append(details, "NOTE: This check runs on the LOCAL machine. If auditing a remote")
-		details = append(details, "tailnet via --tailnet, verify lock status on that tailnet directly.")
-
-		// Try to extract key count or other info
-		lines := strings.Split(outputStr, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if strings.Contains(line, "key") || strings.Contains(line, "signing") {
-				details = append(details, line)
-			}
-		}
-
-		if len(details) > 1 {
-			finding.Details = details
+	nodeKeys, preauthKeys := signingKeys(st)
+	details = append(details, "", fmt.Sprintf("Trusted signing keys (%d):", len(nodeKeys)))
+	for _, k := range nodeKeys {
+		line := fmt.Sprintf("  %s  votes: %d", k.Key.CLIString(), k.Votes)
+		if k.Key == st.PublicKey {
+			line += "  (self)"
 		}
-		return finding
+		details = append(details, line)
+	}
+	if len(preauthKeys) > 0 {
+		details = append(details, fmt.Sprintf("Keys for wrapped pre-auth keys: %d", len(preauthKeys)))
 	}
 
-	// Output doesn't clearly indicate enabled/disabled - report what we got
-	finding.Pass = false
-	finding.Severity = types.Informational
-	finding.Description = "Tailnet Lock status unclear. Manual verification recommended."
-	finding.Details = []string{
-		"Output from 'tailscale lock status':",
-		strings.TrimSpace(outputStr),
-		"",
-		"Please verify Tailnet Lock status manually.",
-		"To enable: tailscale lock init",
+	switch {
+	case lock.DisablementValues < 0:
+		details = append(details, fmt.Sprintf("Disablement values: unknown (no checkpoint in the last %d lock log entries)", lockLogLimit))
+	case lock.DisablementValues == 0:
+		details = append(details, "Disablement values: none (Tailnet Lock cannot be disabled without Tailscale support)")
+	default:
+		details = append(details,
+			fmt.Sprintf("Disablement values: %d", lock.DisablementValues),
+			"  Anyone holding a disablement secret can turn off Tailnet Lock; keep the secrets offline.",
+		)
 	}
-	finding.Fix = &types.FixInfo{
-		Type:        types.FixTypeExternal,
-		Description: "Verify Tailnet Lock status and enable if needed",
-		DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
+
+	if len(nodeKeys) == 1 {
+		finding.Pass = false
+		finding.Severity = types.Medium
+		finding.Description = "Tailnet Lock is enabled but trusts a single signing key. Losing that node blocks signing new devices, and compromising it defeats the lock."
+		finding.Details = details
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeExternal,
+			Description: "Add signing keys from other trusted nodes with 'tailscale lock add <key>'",
+			DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
+		}
+		return finding
 	}
 
+	finding.Description = fmt.Sprintf("Tailnet Lock is enabled with %d trusted signing keys. Devices require cryptographic signing from trusted nodes.", len(nodeKeys))
+	finding.Details = details
 	return finding
 }
 
@@ -1101,7 +1214,7 @@ func (d *DeviceAuditor) checkUniqueUsers(devices []*client.Device) types.Suggest
 	return finding
 }
 
-func (d *DeviceAuditor) checkTailnetLockPending(ctx context.Context) types.Suggestion {
+func (d *DeviceAuditor) checkTailnetLockPending(lock *localLock, lockErr error, devices []*client.Device) types.Suggestion {
 	finding := types.Suggestion{
 		ID:          "DEV-012",
 		Title:       "Nodes awaiting Tailnet Lock signature",
@@ -1113,83 +1226,61 @@ func (d *DeviceAuditor) checkTailnetLockPending(ctx context.Context) types.Sugge
 		Pass:        true,
 	}
 
-	// Find tailscale binary using secure path resolution
-	tsBinary, err := findTailscaleBinary()
-	if err != nil {
-		// Can't find binary, skip this check
-		finding.Pass = true
-		finding.Description = "Tailnet Lock pending check skipped (CLI unavailable)."
-		finding.Details = []string{
-			"This check only applies when Tailnet Lock is enabled.",
-			"NOTE: This check runs on the LOCAL machine.",
-		}
+	if lockErr != nil {
+		// Can't read lock status, skip this check (DEV-010 reports why)
+		finding.Description = "Tailnet Lock pending check skipped (status unavailable)."
+		finding.Details = "This check only applies when Tailnet Lock is enabled. See DEV-010."
 		return finding
 	}
 
-	// Try to run tailscale lock status for detailed info
-	cmd := exec.CommandContext(ctx, tsBinary, "lock", "status")
-	output, err := cmd.CombinedOutput()
-
-	if err != nil {
-		// If lock is not enabled or command fails, skip this check
-		finding.Pass = true
-		finding.Description = "Tailnet Lock status check skipped (lock not enabled or CLI unavailable)."
-		finding.Details = "This check only applies when Tailnet Lock is enabled."
+	self := localDevice(lock.Status, devices)
+	if self == nil {
+		finding.Description = "Tailnet Lock pending check skipped (local node is not part of the audited tailnet)."
+		finding.Details = foreignNodeDetails(lock)
 		return finding
 	}
 
-	outputStr := string(output)
-
-	// Check if there are pending signatures
-	if strings.Contains(outputStr, "awaiting") ||
-		strings.Contains(outputStr, "pending") ||
-		strings.Contains(outputStr, "needs signature") {
-
-		finding.Pass = false
-		finding.Description = "There are nodes awaiting Tailnet Lock signatures. Review and sign legitimate nodes."
-
-		// Extract relevant lines
-		var pendingLines []string
-		lines := strings.Split(outputStr, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if strings.Contains(line, "await") || strings.Contains(line, "pending") || strings.Contains(line, "needs") {
-				pendingLines = append(pendingLines, line)
-			}
-		}
-
-		if len(pendingLines) > 0 {
-			finding.Details = pendingLines
-		} else {
-			finding.Details = []string{
-				"Nodes are awaiting signatures.",
-				"Run 'tailscale lock status' for details.",
-				"Sign with: tailscale lock sign <nodekey>",
-			}
-		}
+	st := lock.Status
+	if !st.Enabled {
+		finding.Description = "Tailnet Lock is not enabled. Enable it to require device signing."
+		finding.Details = "This check only reports pending signatures when Tailnet Lock is active."
+		return finding
+	}
 
-		finding.Fix = &types.FixInfo{
-			Type:        types.FixTypeExternal,
-			Description: "Review pending nodes with 'tailscale lock status' and sign legitimate ones",
-			DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
+	var pending []string
+	if !st.NodeKeySigned {
+		pending = append(pending,
+			fmt.Sprintf("%s (local node)", self.Name),
+			fmt.Sprintf("  Sign with: tailscale lock sign %s %s", st.NodeKey, st.PublicKey.CLIString()),
+		)
+	}
+	for _, p := range st.FilteredPeers {
+		var ips []string
+		for _, ip := range p.TailscaleIPs {
+			ips = append(ips, ip.String())
 		}
-		return finding
+		pending = append(pending,
+			fmt.Sprintf("%s  %s  %s", p.Name, strings.Join(ips, ","), p.StableID),
+			fmt.Sprintf("  Sign with: tailscale lock sign %s", p.NodeKey),
+		)
 	}
 
-	// Check if lock is enabled but no pending nodes
-	if strings.Contains(outputStr, "enabled") {
-		finding.Pass = true
-		finding.Description = "Tailnet Lock is enabled with no nodes awaiting signatures (local check)."
-		finding.Details = "NOTE: This check runs on the LOCAL machine. If auditing a remote tailnet, verify directly."
+	if len(pending) == 0 {
+		finding.Description = fmt.Sprintf("Tailnet Lock is enabled with no nodes awaiting signatures (checked from %s).", self.Name)
 		return finding
 	}
 
-	// Lock not enabled - skip this check
-	finding.Pass = true
-	finding.Description = "Tailnet Lock is not enabled. Enable it to require device signing."
-	finding.Details = []string{
-		"This check only reports pending signatures when Tailnet Lock is active.",
-		"NOTE: This check runs on the LOCAL machine.",
+	count := len(st.FilteredPeers)
+	if !st.NodeKeySigned {
+		count++
+	}
+	finding.Pass = false
+	finding.Description = fmt.Sprintf("Found %d node(s) locked out by Tailnet Lock awaiting a signature. Review and sign legitimate nodes.", count)
+	finding.Details = append([]string{"Read from: " + lock.Source, ""}, pending...)
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeExternal,
+		Description: "Review pending nodes with 'tailscale lock status' and sign legitimate ones",
+		DocURL:      "https://tailscale.com/kb/1226/tailnet-lock",
 	}
 	return finding
 }
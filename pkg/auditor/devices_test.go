@@ -1,13 +1,18 @@
 package auditor
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"tailscale.com/ipn/ipnstate"
+	"tailscale.com/tka"
+
 	"github.com/Adversis/tailsnitch/pkg/client"
 	"github.com/Adversis/tailsnitch/pkg/types"
 )
@@ -533,8 +538,6 @@ func TestCheckUniqueUsers(t *testing.T) {
 }
 
 func TestParseVersion(t *testing.T) {
-	versionRegex := regexp.MustCompile(`v?(\d+)\.(\d+)`)
-
 	tests := []struct {
 		name      string
 		version   string
@@ -954,3 +957,300 @@ func TestFindTailscaleBinarySecurityChecks(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckStaleDevicesLifecyclePolicy(t *testing.T) {
+	defer SetLifecyclePolicy(nil)
+	SetLifecyclePolicy(&types.LifecyclePolicy{Rules: []types.LifecycleRule{
+		{Name: "ci", Tags: []string{"tag:ci"}, StaleDays: 1},
+		{Name: "laptops", OS: []string{"macOS"}, StaleDays: 90},
+	}})
+
+	d := &DeviceAuditor{}
+	now := time.Now()
+	devices := []*client.Device{
+		{DeviceID: "1", Name: "runner", Tags: []string{"tag:ci"}, LastSeen: now.AddDate(0, 0, -2).Format(time.RFC3339)},
+		{DeviceID: "2", Name: "laptop", OS: "macOS", LastSeen: now.AddDate(0, 0, -70).Format(time.RFC3339)},
+		{DeviceID: "3", Name: "server", OS: "linux", LastSeen: now.AddDate(0, 0, -70).Format(time.RFC3339)},
+	}
+
+	result := d.checkStaleDevices(devices)
+	if result.Pass {
+		t.Fatal("expected stale devices")
+	}
+
+	details, _ := result.Details.([]string)
+	if len(details) != 2 {
+		t.Fatalf("expected runner and server to be stale, got %v", details)
+	}
+	if !strings.Contains(details[0], `rule "ci"`) {
+		t.Errorf("expected rule name in details, got %q", details[0])
+	}
+	if result.Fix == nil || result.Fix.AutoFixSafe {
+		t.Error("removing a device seen 2 days ago should not be auto-fix safe")
+	}
+}
+
+func TestCheckLongKeyExpiryLifecyclePolicy(t *testing.T) {
+	defer SetLifecyclePolicy(nil)
+	SetLifecyclePolicy(&types.LifecyclePolicy{Rules: []types.LifecycleRule{
+		{Name: "servers", Tags: []string{"tag:server"}, KeyExpiryDays: 30},
+	}})
+
+	d := &DeviceAuditor{}
+	expires := time.Now().AddDate(0, 0, 60).Format(time.RFC3339)
+	devices := []*client.Device{
+		{DeviceID: "1", Name: "db", Tags: []string{"tag:server"}, OS: "linux", Expires: expires},
+	}
+
+	result := d.checkLongKeyExpiry(devices)
+	if result.Pass {
+		t.Fatal("expected server to exceed its 30 day rule")
+	}
+	if result.Severity != types.Medium {
+		t.Errorf("Severity = %v, want MEDIUM", result.Severity)
+	}
+	details, _ := result.Details.([]string)
+	if len(details) != 2 || !strings.Contains(details[1], `rule "servers"`) {
+		t.Errorf("expected rule name in details, got %v", details)
+	}
+}
+
+func loadTestNamingPolicy(t *testing.T, content string) *types.NamingPolicy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "naming")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := types.LoadNamingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func TestCheckSensitiveMachineNamesNamingPolicy(t *testing.T) {
+	defer SetNamingPolicy(nil)
+	SetNamingPolicy(loadTestNamingPolicy(t, `{
+  "rules": [{"name": "servers", "tags": ["tag:server"], "pattern": "^srv-[a-z]+-\\d{2}$"}],
+  "allow": ["^keycloak-admin"],
+}`))
+
+	d := &DeviceAuditor{}
+	devices := []*client.Device{
+		{Name: "srv-ams-01.example.ts.net", Hostname: "srv-ams-01", Tags: []string{"tag:server"}},
+		{Name: "web7.example.ts.net", Hostname: "web7", Tags: []string{"tag:server"}},
+		{Name: "keycloak-admin.example.ts.net", Hostname: "keycloak-admin"},
+	}
+
+	// The policy is enforced even without MagicDNS
+	result := d.checkSensitiveMachineNames(devices, &client.DNSConfig{MagicDNS: false})
+	if result.Pass {
+		t.Fatal("expected naming policy violation")
+	}
+	details, _ := result.Details.([]string)
+	if len(details) != 1 || !strings.Contains(details[0], "web7") || !strings.Contains(details[0], `rule "servers"`) {
+		t.Errorf("Details = %v, want only web7 with the servers rule", details)
+	}
+}
+
+func TestIsDevDeviceNamingPolicy(t *testing.T) {
+	defer SetNamingPolicy(nil)
+
+	dev := &client.Device{Hostname: "lt-alice", OS: "linux"}
+	if isDevDevice(dev) {
+		t.Fatal("expected linux host without policy to be a server")
+	}
+
+	SetNamingPolicy(loadTestNamingPolicy(t, `{"userDevicePatterns": ["^lt-"]}`))
+	if !isDevDevice(dev) {
+		t.Error("expected userDevicePatterns to mark lt-alice as a user device")
+	}
+}
+
+// Keys used by the Tailnet Lock fixtures
+var (
+	testNodeKey  = "nodekey:" + strings.Repeat("11", 32)
+	testPeerKey  = "nodekey:" + strings.Repeat("22", 32)
+	testLockKey1 = "nlpub:" + strings.Repeat("aa", 32)
+	testLockKey2 = "nlpub:" + strings.Repeat("bb", 32)
+	testLockKey3 = "nlpub:" + strings.Repeat("cc", 32)
+)
+
+func lockFixture(t *testing.T, status string, disablementValues int) *localLock {
+	t.Helper()
+	var st ipnstate.NetworkLockStatus
+	if err := json.Unmarshal([]byte(status), &st); err != nil {
+		t.Fatal(err)
+	}
+	return &localLock{Status: &st, DisablementValues: disablementValues, Source: "test"}
+}
+
+func TestCheckTailnetLock(t *testing.T) {
+	d := &DeviceAuditor{}
+	devices := []*client.Device{
+		{Name: "signer.example.ts.net", NodeKey: testNodeKey},
+	}
+
+	disabled := `{"Enabled": false, "NodeKey": "` + testNodeKey + `"}`
+	singleKey := `{
+  "Enabled": true,
+  "NodeKey": "` + testNodeKey + `",
+  "NodeKeySigned": true,
+  "PublicKey": "` + testLockKey1 + `",
+  "TrustedKeys": [
+    {"Key": "` + testLockKey1 + `", "Votes": 1},
+    {"Key": "` + testLockKey3 + `", "Votes": 1, "Metadata": {"purpose": "pre-auth key"}}
+  ]
+}`
+	twoKeys := `{
+  "Enabled": true,
+  "NodeKey": "` + testNodeKey + `",
+  "NodeKeySigned": true,
+  "PublicKey": "` + testLockKey1 + `",
+  "TrustedKeys": [
+    {"Key": "` + testLockKey1 + `", "Votes": 1},
+    {"Key": "` + testLockKey2 + `", "Votes": 1}
+  ]
+}`
+	otherTailnet := `{"Enabled": true, "NodeKey": "` + testPeerKey + `"}`
+
+	tests := []struct {
+		name         string
+		lock         *localLock
+		lockErr      error
+		wantPass     bool
+		wantSeverity types.Severity
+		wantDetail   string
+	}{
+		{"status unavailable", nil, errors.New("tailscaled not running"), false, types.Informational, "tailscale lock status"},
+		{"local node in other tailnet", lockFixture(t, otherTailnet, -1), nil, false, types.Informational, "not a device in the audited tailnet"},
+		{"disabled", lockFixture(t, disabled, -1), nil, false, types.High, "Current status: DISABLED"},
+		{"single signing key", lockFixture(t, singleKey, 1), nil, false, types.Medium, "Keys for wrapped pre-auth keys: 1"},
+		{"two signing keys", lockFixture(t, twoKeys, 2), nil, true, types.High, "Disablement values: 2"},
+		{"disablement values unknown", lockFixture(t, twoKeys, -1), nil, true, types.High, "Disablement values: unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := d.checkTailnetLock(tt.lock, tt.lockErr, devices)
+			if result.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (%s)", result.Pass, tt.wantPass, result.Description)
+			}
+			if result.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %v, want %v", result.Severity, tt.wantSeverity)
+			}
+			details, _ := result.Details.([]string)
+			if !strings.Contains(strings.Join(details, "\n"), tt.wantDetail) {
+				t.Errorf("Details = %v, want them to contain %q", details, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestCheckDeviceApproval(t *testing.T) {
+	d := &DeviceAuditor{}
+	devices := []*client.Device{{Authorized: true}, {Authorized: true}, {Authorized: false}}
+	var manyAuthorized []*client.Device
+	for i := 0; i < 6; i++ {
+		manyAuthorized = append(manyAuthorized, &client.Device{Authorized: true})
+	}
+
+	tests := []struct {
+		name         string
+		settings     *client.TailnetSettings
+		settingsErr  error
+		devices      []*client.Device
+		wantPass     bool
+		wantSeverity types.Severity
+		wantDetail   string
+	}{
+		{"device approval off", &client.TailnetSettings{UsersApprovalOn: true, DevicesKeyDurationDays: 180}, nil, devices, false, types.Medium, "Device approval (devicesApprovalOn): off"},
+		{"user approval off", &client.TailnetSettings{DevicesApprovalOn: true, DevicesKeyDurationDays: 90}, nil, devices, false, types.Low, "User approval (usersApprovalOn): off"},
+		{"both on", &client.TailnetSettings{DevicesApprovalOn: true, UsersApprovalOn: true, DevicesKeyDurationDays: 90}, nil, devices, true, types.Medium, "Default node key expiry (devicesKeyDurationDays): 90 days"},
+		{"settings unavailable", nil, errors.New("403 Forbidden"), manyAuthorized, false, types.Informational, "feature_settings:read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := d.checkDeviceApproval(tt.settings, tt.settingsErr, tt.devices)
+			if result.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (%s)", result.Pass, tt.wantPass, result.Description)
+			}
+			if result.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %v, want %v", result.Severity, tt.wantSeverity)
+			}
+			details, _ := result.Details.([]string)
+			if !strings.Contains(strings.Join(details, "\n"), tt.wantDetail) {
+				t.Errorf("Details = %v, want them to contain %q", details, tt.wantDetail)
+			}
+			if tt.settings != nil && !strings.Contains(result.Description, fmt.Sprintf("expire after %d days", tt.settings.DevicesKeyDurationDays)) {
+				t.Errorf("Description = %q, want the default node key expiry", result.Description)
+			}
+		})
+	}
+}
+
+func TestCheckTailnetLockPending(t *testing.T) {
+	d := &DeviceAuditor{}
+	devices := []*client.Device{
+		{Name: "signer.example.ts.net", NodeKey: testNodeKey},
+	}
+
+	noneFiltered := `{"Enabled": true, "NodeKey": "` + testNodeKey + `", "NodeKeySigned": true}`
+	filtered := `{
+  "Enabled": true,
+  "NodeKey": "` + testNodeKey + `",
+  "NodeKeySigned": true,
+  "FilteredPeers": [
+    {"Name": "intruder.example.ts.net.", "StableID": "nXYZ", "TailscaleIPs": ["100.64.0.9"], "NodeKey": "` + testPeerKey + `"}
+  ]
+}`
+	selfUnsigned := `{"Enabled": true, "NodeKey": "` + testNodeKey + `", "NodeKeySigned": false, "PublicKey": "` + testLockKey1 + `"}`
+
+	tests := []struct {
+		name       string
+		lock       *localLock
+		lockErr    error
+		wantPass   bool
+		wantDetail string
+	}{
+		{"status unavailable", nil, errors.New("tailscaled not running"), true, ""},
+		{"disabled", lockFixture(t, `{"Enabled": false, "NodeKey": "`+testNodeKey+`"}`, -1), nil, true, ""},
+		{"no filtered peers", lockFixture(t, noneFiltered, -1), nil, true, ""},
+		{"filtered peer", lockFixture(t, filtered, -1), nil, false, "tailscale lock sign " + testPeerKey},
+		{"local node unsigned", lockFixture(t, selfUnsigned, -1), nil, false, "signer.example.ts.net (local node)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := d.checkTailnetLockPending(tt.lock, tt.lockErr, devices)
+			if result.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (%s)", result.Pass, tt.wantPass, result.Description)
+			}
+			details, _ := result.Details.([]string)
+			if !strings.Contains(strings.Join(details, "\n"), tt.wantDetail) {
+				t.Errorf("Details = %v, want them to contain %q", details, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestDisablementValues(t *testing.T) {
+	checkpoint := tka.AUM{
+		MessageKind: tka.AUMCheckpoint,
+		State: &tka.State{
+			DisablementSecrets: [][]byte{tka.DisablementKDF([]byte{1}), tka.DisablementKDF([]byte{2})},
+		},
+	}
+
+	updates := []ipnstate.NetworkLockUpdate{
+		{Change: tka.AUMAddKey.String()},
+		{Change: tka.AUMCheckpoint.String(), Raw: checkpoint.Serialize()},
+	}
+	if got := disablementValues(updates); got != 2 {
+		t.Errorf("disablementValues() = %d, want 2", got)
+	}
+	if got := disablementValues(updates[:1]); got != -1 {
+		t.Errorf("disablementValues() without checkpoint = %d, want -1", got)
+	}
+}
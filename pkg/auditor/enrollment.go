@@ -0,0 +1,172 @@
+package auditor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// keyEnrollment is the set of devices an auth key most likely enrolled
+type keyEnrollment struct {
+	Key     keyInfo
+	Devices []*client.Device
+}
+
+// enrollingKey estimates which key enrolled a device. A tagged key gives
+// the devices it enrolls exactly its tags, so a candidate key must carry
+// the device's tag set and have been valid when the device was created.
+// When several keys qualify, the most recently created one wins. Untagged
+// devices can't be attributed: they may have joined with an untagged key or
+// an interactive login.
+func enrollingKey(dev *client.Device, keys []keyInfo) (keyInfo, bool) {
+	if len(dev.Tags) == 0 || dev.IsExternal {
+		return keyInfo{}, false
+	}
+	created, err := time.Parse(time.RFC3339, dev.Created)
+	if err != nil {
+		return keyInfo{}, false
+	}
+
+	var best keyInfo
+	found := false
+	for _, key := range keys {
+		if !sameTags(key.Tags, dev.Tags) || created.Before(key.Created) {
+			continue
+		}
+		if !key.Expires.IsZero() && created.After(key.Expires) {
+			continue
+		}
+		if !found || key.Created.After(best.Created) {
+			best, found = key, true
+		}
+	}
+	return best, found
+}
+
+// correlateKeyEnrollments groups devices by the key that most likely
+// enrolled them, and returns devices carrying tags that no active key grants
+// mapped to those tags. Enrollments are sorted by key ID.
+func correlateKeyEnrollments(keys []keyInfo, devices []*client.Device) ([]keyEnrollment, map[*client.Device][]string) {
+	byKey := make(map[string]*keyEnrollment)
+	granted := make(map[string]bool)
+	for _, key := range keys {
+		for _, tag := range key.Tags {
+			granted[tag] = true
+		}
+	}
+
+	untraced := make(map[*client.Device][]string)
+	for _, dev := range devices {
+		if dev.IsExternal {
+			continue
+		}
+		if key, ok := enrollingKey(dev, keys); ok {
+			if byKey[key.ID] == nil {
+				byKey[key.ID] = &keyEnrollment{Key: key}
+			}
+			byKey[key.ID].Devices = append(byKey[key.ID].Devices, dev)
+		}
+		for _, tag := range dev.Tags {
+			if !granted[tag] {
+				untraced[dev] = append(untraced[dev], tag)
+			}
+		}
+	}
+
+	var enrollments []keyEnrollment
+	for _, e := range byKey {
+		enrollments = append(enrollments, *e)
+	}
+	sort.Slice(enrollments, func(i, j int) bool { return enrollments[i].Key.ID < enrollments[j].Key.ID })
+	return enrollments, untraced
+}
+
+// keyEnrollmentFinding is AUTH-005 before enrollments are checked
+func keyEnrollmentFinding() types.Suggestion {
+	return types.Suggestion{
+		ID:          "AUTH-005",
+		Title:       "Devices enrolled by auth keys, or their tags, are untraceable",
+		Severity:    types.Medium,
+		Category:    types.Authentication,
+		Description: "A leaked reusable or pre-authorized key can enroll more devices with the same tags as the devices it already enrolled. Devices carrying tags that no active key grants were tagged by a revoked or expired key, or manually, and can't be traced to a current provisioning path.",
+		Remediation: "Review which devices each reusable or pre-authorized key enrolled, and scope keys to the fewest tags needed. Confirm that tagged devices without a matching key were tagged intentionally.",
+		Source:      "https://tailscale.com/kb/1085/auth-keys",
+		Pass:        true,
+	}
+}
+
+// checkKeyEnrollments reports key blast radius and untraceable device tags.
+// allKeys is false when keys holds only the caller's own auth keys; tags
+// granted by other admins' keys would then look untraceable, so device tags
+// aren't compared.
+func (a *AuthAuditor) checkKeyEnrollments(keys []keyInfo, devices []*client.Device, allKeys bool) types.Suggestion {
+	finding := keyEnrollmentFinding()
+
+	enrollments, untraced := correlateKeyEnrollments(keys, devices)
+
+	var blastRadius []string
+	for _, e := range enrollments {
+		if !e.Key.Reusable && !e.Key.Preauthorized {
+			continue
+		}
+		var caps []string
+		if e.Key.Reusable {
+			caps = append(caps, "reusable")
+		}
+		if e.Key.Preauthorized {
+			caps = append(caps, "pre-authorized")
+		}
+		var names []string
+		for _, dev := range e.Devices {
+			names = append(names, dev.Name)
+		}
+		sort.Strings(names)
+		blastRadius = append(blastRadius, fmt.Sprintf("Key %s (%s, tags: %v): likely enrolled %d device(s): %s",
+			e.Key.ID, strings.Join(caps, ", "), e.Key.Tags, len(names), strings.Join(names, ", ")))
+	}
+
+	var untracedDevices []string
+	var partialNote string
+	if allKeys {
+		for dev, tags := range untraced {
+			untracedDevices = append(untracedDevices, fmt.Sprintf("%s (%s): %s", dev.Name, dev.Hostname, strings.Join(tags, ", ")))
+		}
+		sort.Strings(untracedDevices)
+	} else {
+		partialNote = " Only your own auth keys could be listed, so device tags were not compared against other admins' keys."
+	}
+
+	if len(blastRadius) == 0 && len(untracedDevices) == 0 {
+		finding.Description += partialNote
+		return finding
+	}
+
+	var details []string
+	if len(blastRadius) > 0 {
+		details = append(details, "Estimated enrollments by reusable or pre-authorized keys:")
+		details = append(details, blastRadius...)
+	}
+	if len(untracedDevices) > 0 {
+		if len(details) > 0 {
+			details = append(details, "")
+		}
+		details = append(details, "Device tags not granted by any active auth key:")
+		details = append(details, untracedDevices...)
+	}
+
+	finding.Pass = false
+	finding.Details = details
+	finding.Description = fmt.Sprintf("Found %d reusable or pre-authorized key(s) that likely enrolled existing devices, and %d device(s) with tags no active key grants. Enrollment is estimated from device creation times and tags.", len(blastRadius), len(untracedDevices)) + partialNote
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Review key scope against the devices each key enrolled, and verify the owners of untraceable tags",
+		AdminURL:    "https://login.tailscale.com/admin/settings/keys",
+		DocURL:      "https://tailscale.com/kb/1068/tags",
+	}
+
+	return finding
+}
@@ -0,0 +1,141 @@
+package auditor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// eolPlatform describes an OS release that no longer receives security
+// patches. Match is applied to the device's node:osVersion posture attribute,
+// whose format depends on the OS:
+//   - windows: NT version and build, e.g. "10.0.19045.3803"
+//   - macOS, iOS: product version, e.g. "14.4.1"
+//   - android: release number, e.g. "13"
+//   - linux: kernel release, e.g. "4.15.0-213-generic" (distro kernels
+//     identify the release they shipped with)
+type eolPlatform struct {
+	OS    string
+	Match *regexp.Regexp
+	Name  string
+	EOL   string // YYYY-MM-DD, last day of security support
+}
+
+// eolPlatforms is the maintained table of end-of-life platforms. Only
+// versions that are unambiguous from osVersion are listed: Windows Server
+// builds that share a number with an EOL client release (e.g. 14393, 17763)
+// and RHEL 8+ kernels (shared with long-supported RHEL) are left out.
+// Entries only apply once their EOL date has passed.
+var eolPlatforms = []eolPlatform{
+	// Windows: https://learn.microsoft.com/lifecycle/
+	{OS: "windows", Match: regexp.MustCompile(`^6\.0\.`), Name: "Windows Vista / Server 2008", EOL: "2020-01-14"},
+	{OS: "windows", Match: regexp.MustCompile(`^6\.1\.`), Name: "Windows 7 / Server 2008 R2", EOL: "2020-01-14"},
+	{OS: "windows", Match: regexp.MustCompile(`^6\.2\.`), Name: "Windows 8 / Server 2012", EOL: "2023-10-10"},
+	{OS: "windows", Match: regexp.MustCompile(`^6\.3\.`), Name: "Windows 8.1 / Server 2012 R2", EOL: "2023-10-10"},
+	{OS: "windows", Match: regexp.MustCompile(`^10\.0\.(10586|15063|16299|17134|18362|18363|19041|19042|19043)(\.|$)`), Name: "Windows 10 (1511 to 21H1)", EOL: "2023-05-09"},
+	{OS: "windows", Match: regexp.MustCompile(`^10\.0\.19045(\.|$)`), Name: "Windows 10 22H2", EOL: "2025-10-14"},
+	{OS: "windows", Match: regexp.MustCompile(`^10\.0\.22000(\.|$)`), Name: "Windows 11 21H2", EOL: "2024-10-08"},
+	{OS: "windows", Match: regexp.MustCompile(`^10\.0\.22621(\.|$)`), Name: "Windows 11 22H2", EOL: "2025-10-14"},
+
+	// macOS: Apple patches the three most recent major releases
+	{OS: "macOS", Match: regexp.MustCompile(`^10\.`), Name: "macOS 10.x", EOL: "2022-10-24"},
+	{OS: "macOS", Match: regexp.MustCompile(`^11\.`), Name: "macOS 11 Big Sur", EOL: "2023-09-26"},
+	{OS: "macOS", Match: regexp.MustCompile(`^12\.`), Name: "macOS 12 Monterey", EOL: "2024-09-16"},
+	{OS: "macOS", Match: regexp.MustCompile(`^13\.`), Name: "macOS 13 Ventura", EOL: "2025-09-15"},
+
+	// iOS: Apple patches the current and previous major release
+	{OS: "iOS", Match: regexp.MustCompile(`^(\d|1[0-6])\.`), Name: "iOS 16 and earlier", EOL: "2025-09-15"},
+
+	// Android: https://source.android.com/docs/security/bulletin
+	{OS: "android", Match: regexp.MustCompile(`^([1-9]|10)(\.|$)`), Name: "Android 10 and earlier", EOL: "2023-03-06"},
+	{OS: "android", Match: regexp.MustCompile(`^11(\.|$)`), Name: "Android 11", EOL: "2024-02-05"},
+	{OS: "android", Match: regexp.MustCompile(`^12(\.|$)`), Name: "Android 12", EOL: "2025-03-03"},
+
+	// Linux distributions, identified by their stock kernels
+	{OS: "linux", Match: regexp.MustCompile(`\.el6`), Name: "RHEL / CentOS 6", EOL: "2020-11-30"},
+	{OS: "linux", Match: regexp.MustCompile(`\.el7`), Name: "RHEL / CentOS 7", EOL: "2024-06-30"},
+	{OS: "linux", Match: regexp.MustCompile(`^4\.4\.0-\d+-generic`), Name: "Ubuntu 16.04", EOL: "2021-04-30"},
+	{OS: "linux", Match: regexp.MustCompile(`^4\.15\.0-\d+-generic`), Name: "Ubuntu 18.04", EOL: "2023-05-31"},
+	{OS: "linux", Match: regexp.MustCompile(`^5\.4\.0-\d+-generic`), Name: "Ubuntu 20.04", EOL: "2025-05-31"},
+	{OS: "linux", Match: regexp.MustCompile(`^3\.16\.0-\d+-`), Name: "Debian 8", EOL: "2020-06-30"},
+	{OS: "linux", Match: regexp.MustCompile(`^4\.9\.0-\d+-`), Name: "Debian 9", EOL: "2022-06-30"},
+	{OS: "linux", Match: regexp.MustCompile(`^4\.19\.0-\d+-`), Name: "Debian 10", EOL: "2024-06-30"},
+	{OS: "linux", Match: regexp.MustCompile(`^5\.10\.0-\d+-`), Name: "Debian 11", EOL: "2026-08-31"},
+}
+
+// eolPlatformFor returns the EOL table entry matching a device's OS and OS version, if its EOL date has passed
+func eolPlatformFor(osName, osVersion string, now time.Time) (eolPlatform, bool) {
+	if osVersion == "" {
+		return eolPlatform{}, false
+	}
+	for _, p := range eolPlatforms {
+		if !strings.EqualFold(p.OS, osName) || !p.Match.MatchString(osVersion) {
+			continue
+		}
+		eol, err := time.Parse("2006-01-02", p.EOL)
+		if err != nil || now.Before(eol) {
+			continue
+		}
+		return p, true
+	}
+	return eolPlatform{}, false
+}
+
+// checkEOLPlatforms flags devices whose OS version, keyed by device ID,
+// matches an entry in eolPlatforms. The devices listing reports only the
+// platform (os) and the Tailscale client version, so versions come from the
+// node:osVersion posture attribute.
+func (d *DeviceAuditor) checkEOLPlatforms(devices []*client.Device, osVersions map[string]string, now time.Time) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "DEV-014",
+		Title:       "Devices running end-of-life operating systems",
+		Severity:    types.Medium,
+		Category:    types.DeviceSecurity,
+		Description: "Operating systems past end of support no longer receive security patches. A compromised device is a foothold into every resource it can reach over the tailnet.",
+		Remediation: "Upgrade or retire devices on unsupported platforms. Use device posture rules to block unsupported OS versions from sensitive resources.",
+		Source:      "https://tailscale.com/kb/1288/device-posture",
+		Pass:        true,
+	}
+
+	if len(osVersions) == 0 {
+		nonExternal := 0
+		for _, dev := range devices {
+			if !dev.IsExternal {
+				nonExternal++
+			}
+		}
+		if nonExternal > 0 {
+			finding.Pass = false
+			finding.Severity = types.Informational
+			finding.Description = "Cannot check OS versions: no node:osVersion posture attributes were returned."
+			finding.Details = "MANUAL CHECK REQUIRED: Ensure the API key can read device posture attributes, or review OS versions on the Machines page."
+		}
+		return finding
+	}
+
+	var eolDevices []string
+	for _, dev := range devices {
+		osVersion := osVersions[dev.DeviceID]
+		if p, ok := eolPlatformFor(dev.OS, osVersion, now); ok {
+			eolDevices = append(eolDevices, fmt.Sprintf("%s (%s): %s %s - %s, support ended %s", dev.Name, dev.Hostname, dev.OS, osVersion, p.Name, p.EOL))
+		}
+	}
+
+	if len(eolDevices) > 0 {
+		finding.Pass = false
+		finding.Details = eolDevices
+		finding.Description = fmt.Sprintf("Found %d device(s) running operating systems that no longer receive security patches.", len(eolDevices))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Upgrade or remove these devices, or add a posture condition on node:osVersion to restrict their access",
+			AdminURL:    "https://login.tailscale.com/admin/machines",
+			DocURL:      "https://tailscale.com/kb/1288/device-posture",
+		}
+	}
+
+	return finding
+}
@@ -0,0 +1,124 @@
+package auditor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestEOLPlatformFor(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		os        string
+		osVersion string
+		want      string
+	}{
+		{"windows 7", "windows", "6.1.7601", "Windows 7 / Server 2008 R2"},
+		{"windows 10 22H2", "windows", "10.0.19045.3803", "Windows 10 22H2"},
+		{"windows 11 23H2 supported", "windows", "10.0.22631.4317", ""},
+		{"windows server 2019 not flagged", "windows", "10.0.17763.6414", ""},
+		{"macOS case-insensitive", "macos", "12.7.6", "macOS 12 Monterey"},
+		{"macOS 15 supported", "macOS", "15.1", ""},
+		{"iOS 16", "iOS", "16.7.10", "iOS 16 and earlier"},
+		{"iOS 18 supported", "iOS", "18.1", ""},
+		{"android 10", "android", "10", "Android 10 and earlier"},
+		{"android 14 supported", "android", "14", ""},
+		{"centos 7 kernel", "linux", "3.10.0-1160.el7.x86_64", "RHEL / CentOS 7"},
+		{"ubuntu 18.04 kernel", "linux", "4.15.0-213-generic", "Ubuntu 18.04"},
+		{"ubuntu 22.04 kernel supported", "linux", "5.15.0-124-generic", ""},
+		{"debian 11 kernel", "linux", "5.10.0-32-amd64", "Debian 11"},
+		{"OS mismatch", "linux", "6.1.7601", ""},
+		{"no version", "windows", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := eolPlatformFor(tt.os, tt.osVersion, now)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("eolPlatformFor(%q, %q) = %q, want no match", tt.os, tt.osVersion, p.Name)
+				}
+				return
+			}
+			if !ok || p.Name != tt.want {
+				t.Errorf("eolPlatformFor(%q, %q) = %q, %v; want %q", tt.os, tt.osVersion, p.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestEOLPlatformForBeforeEOLDate(t *testing.T) {
+	// Debian 11 LTS ends 2026-08-31
+	if _, ok := eolPlatformFor("linux", "5.10.0-32-amd64", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("Debian 11 should not be flagged before its EOL date")
+	}
+}
+
+func TestEOLPlatformsTable(t *testing.T) {
+	for _, p := range eolPlatforms {
+		if _, err := time.Parse("2006-01-02", p.EOL); err != nil {
+			t.Errorf("%s: invalid EOL date %q", p.Name, p.EOL)
+		}
+	}
+}
+
+func TestCheckEOLPlatforms(t *testing.T) {
+	d := &DeviceAuditor{}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	devices := []*client.Device{
+		{DeviceID: "1", Name: "old-laptop", OS: "windows"},
+		{DeviceID: "2", Name: "new-laptop", OS: "macOS"},
+		{DeviceID: "3", Name: "shared", OS: "linux", IsExternal: true},
+	}
+
+	tests := []struct {
+		name         string
+		osVersions   map[string]string
+		wantPass     bool
+		wantSeverity types.Severity
+		wantCount    int
+	}{
+		{
+			name:         "EOL device",
+			osVersions:   map[string]string{"1": "6.1.7601", "2": "15.1"},
+			wantPass:     false,
+			wantSeverity: types.Medium,
+			wantCount:    1,
+		},
+		{
+			name:         "all supported",
+			osVersions:   map[string]string{"1": "10.0.26100.2033", "2": "15.1"},
+			wantPass:     true,
+			wantSeverity: types.Medium,
+		},
+		{
+			name:         "no OS versions available",
+			osVersions:   nil,
+			wantPass:     false,
+			wantSeverity: types.Informational,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := d.checkEOLPlatforms(devices, tt.osVersions, now)
+			if result.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v", result.Pass, tt.wantPass)
+			}
+			if result.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %v, want %v", result.Severity, tt.wantSeverity)
+			}
+			if tt.wantCount > 0 {
+				details, ok := result.Details.([]string)
+				if !ok || len(details) != tt.wantCount {
+					t.Errorf("Details = %v, want %d entries", result.Details, tt.wantCount)
+				}
+			}
+		})
+	}
+}
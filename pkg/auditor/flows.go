@@ -0,0 +1,889 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tailscale/hujson"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// maxProposedPorts is how many distinct ports a narrowed rule lists for one
+// destination before it keeps the rule's own ports instead
+const maxProposedPorts = 10
+
+// protoNumbers maps ACL and grant protocol names to IANA protocol numbers
+var protoNumbers = map[string]int{
+	"icmp":      1,
+	"igmp":      2,
+	"tcp":       6,
+	"udp":       17,
+	"gre":       47,
+	"esp":       50,
+	"ah":        51,
+	"ipv6-icmp": 58,
+	"sctp":      132,
+}
+
+// tailnetPrefixes are the ranges Tailscale assigns node addresses from.
+// Other addresses are subnet or internet destinations.
+var tailnetPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("fd7a:115c:a1e0::/48"),
+}
+
+// FlowCollector maps network flow logs onto the policy's ACL rules and grants
+type FlowCollector struct {
+	client *client.Client
+}
+
+// NewFlowCollector creates a new flow log collector
+func NewFlowCollector(c *client.Client) *FlowCollector {
+	return &FlowCollector{client: c}
+}
+
+// Collect fetches network flow logs between since and until and maps them
+// onto the current policy
+func (c *FlowCollector) Collect(ctx context.Context, since, until time.Time) (*types.FlowReport, error) {
+	logs, err := c.client.GetNetworkFlowLogs(ctx, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network flow logs: %w", err)
+	}
+
+	report, err := c.Analyze(ctx, logs)
+	if err != nil {
+		return nil, err
+	}
+	report.Source = "api"
+	report.Since = since
+	report.Until = until
+	return report, nil
+}
+
+// Analyze maps flow logs read from elsewhere, such as exported files, onto
+// the current policy. The window is taken from the logs.
+func (c *FlowCollector) Analyze(ctx context.Context, logs []client.NetworkFlowLog) (*types.FlowReport, error) {
+	aclHuJSON, err := c.client.GetACLHuJSON(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ACL: %w", err)
+	}
+
+	devices, err := c.client.GetDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	report, err := buildFlowReport(aclHuJSON.ACL, devices, logs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ACL: %w", err)
+	}
+	report.Tailnet = c.client.Tailnet()
+	report.GeneratedAt = time.Now()
+	return report, nil
+}
+
+// LoadFlowLogs reads exported network flow log files
+func LoadFlowLogs(paths []string) ([]client.NetworkFlowLog, error) {
+	var logs []client.NetworkFlowLog
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		fileLogs, err := client.DecodeNetworkFlowLogs(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		logs = append(logs, fileLogs...)
+	}
+	return logs, nil
+}
+
+// flowKey is traffic from a source address to a destination port, with the
+// source port dropped so repeated connections collapse into one flow
+type flowKey struct {
+	src, dst netip.Addr
+	port     uint16
+	proto    int
+}
+
+// flowTuple is one connection as logged, oriented towards its service port
+type flowTuple struct {
+	src, dst netip.AddrPort
+	proto    int
+}
+
+type flowTotals struct {
+	packets, bytes uint64
+}
+
+// flowRule is an ACL rule or network grant in policy order
+type flowRule struct {
+	kind   string
+	number int
+	raw    map[string]json.RawMessage
+	acl    ACLRule
+	grant  Grant
+}
+
+// selectors returns every src and dst selector the rule uses
+func (r flowRule) selectors() (src, dst []string) {
+	if r.kind == types.FlowRuleGrant {
+		return r.grant.Src, r.grant.Dst
+	}
+	for _, d := range r.acl.Dst {
+		host, _ := splitACLDst(d)
+		dst = append(dst, host)
+	}
+	return r.acl.Src, dst
+}
+
+// flowUniverse resolves policy selectors against device addresses
+type flowUniverse struct {
+	policy     ACLPolicy
+	byAddr     map[netip.Addr]*client.Device
+	tagCounts  map[string]int // How many devices carry each tag
+	unresolved map[string]bool
+}
+
+func newFlowUniverse(policy ACLPolicy, devices []*client.Device) *flowUniverse {
+	u := &flowUniverse{
+		policy:     policy,
+		byAddr:     make(map[netip.Addr]*client.Device),
+		tagCounts:  make(map[string]int),
+		unresolved: make(map[string]bool),
+	}
+	for _, dev := range devices {
+		for _, tag := range dev.Tags {
+			u.tagCounts[tag]++
+		}
+		for _, a := range dev.Addresses {
+			if addr, err := netip.ParseAddr(a); err == nil {
+				u.byAddr[addr] = dev
+			}
+		}
+	}
+	return u
+}
+
+// name returns the device name for an address, or the address itself
+func (u *flowUniverse) name(addr netip.Addr) string {
+	if dev := u.byAddr[addr]; dev != nil {
+		if dev.Name != "" {
+			return dev.Name
+		}
+		return dev.Hostname
+	}
+	return addr.String()
+}
+
+// matches reports whether selector covers addr. peer is the other end of
+// the flow, for autogroup:self.
+func (u *flowUniverse) matches(selector string, addr, peer netip.Addr) bool {
+	dev := u.byAddr[addr]
+	untagged := dev != nil && len(dev.Tags) == 0
+
+	switch {
+	case selector == "*", selector == "autogroup:danger-all":
+		return true
+	case selector == "autogroup:member":
+		return untagged && !dev.IsExternal
+	case selector == "autogroup:tagged":
+		return dev != nil && len(dev.Tags) > 0
+	case selector == "autogroup:self":
+		other := u.byAddr[peer]
+		return untagged && other != nil && len(other.Tags) == 0 && other.User == dev.User
+	case selector == "autogroup:internet":
+		return dev == nil && !inTailnetRange(addr) && !addr.IsPrivate() && !addr.IsLoopback()
+	case strings.HasPrefix(selector, "tag:"):
+		return dev != nil && containsExact(dev.Tags, selector)
+	case strings.HasPrefix(selector, "group:"):
+		members, ok := u.policy.Groups[selector]
+		if !ok {
+			u.unresolved[selector] = true
+			return false
+		}
+		return untagged && containsExact(members, dev.User)
+	case strings.HasPrefix(selector, "ipset:"):
+		return u.ipSetContains(selector, addr, 0)
+	case strings.HasPrefix(selector, "autogroup:"):
+		// Role-based autogroups need the users API
+		u.unresolved[selector] = true
+		return false
+	case strings.Contains(selector, "@"):
+		return untagged && dev.User == selector
+	}
+
+	prefix, ok := u.prefixOf(selector)
+	if !ok {
+		u.unresolved[selector] = true
+		return false
+	}
+	return prefix.Contains(addr)
+}
+
+// prefixOf resolves a hosts alias, address or prefix
+func (u *flowUniverse) prefixOf(selector string) (netip.Prefix, bool) {
+	target := strings.TrimPrefix(selector, "host:")
+	if aliased, ok := u.policy.Hosts[target]; ok {
+		target = aliased
+	}
+	if prefix, err := netip.ParsePrefix(target); err == nil {
+		return prefix.Masked(), true
+	}
+	if addr, err := netip.ParseAddr(target); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+	return netip.Prefix{}, false
+}
+
+// ipSetContains evaluates an ipsets entry list: an address is in the set if
+// an added entry covers it and no removed entry does
+func (u *flowUniverse) ipSetContains(name string, addr netip.Addr, depth int) bool {
+	entries, ok := u.policy.IPSets[name]
+	if !ok || depth > 8 {
+		u.unresolved[name] = true
+		return false
+	}
+	added, removed := false, false
+	for _, entry := range entries {
+		op, value := ipSetEntry(entry)
+		var hit bool
+		if strings.HasPrefix(value, "ipset:") {
+			hit = u.ipSetContains(value, addr, depth+1)
+		} else if prefix, ok := u.prefixOf(value); ok {
+			hit = prefix.Contains(addr)
+		}
+		if hit && op == "remove" {
+			removed = true
+		} else if hit {
+			added = true
+		}
+	}
+	return added && !removed
+}
+
+// resolvable reports whether a selector can be matched against addresses
+func (u *flowUniverse) resolvable(selector string) bool {
+	switch {
+	case selector == "*", selector == "autogroup:danger-all", selector == "autogroup:member",
+		selector == "autogroup:tagged", selector == "autogroup:self", selector == "autogroup:internet",
+		strings.HasPrefix(selector, "tag:"), strings.Contains(selector, "@"):
+		return true
+	case strings.HasPrefix(selector, "group:"):
+		_, ok := u.policy.Groups[selector]
+		return ok
+	case strings.HasPrefix(selector, "ipset:"):
+		_, ok := u.policy.IPSets[selector]
+		return ok
+	case strings.HasPrefix(selector, "autogroup:"):
+		return false
+	}
+	_, ok := u.prefixOf(selector)
+	return ok
+}
+
+// isBroad reports whether a destination selector covers more than the
+// destinations a narrowed rule should name
+func (u *flowUniverse) isBroad(selector string) bool {
+	switch {
+	case selector == "*", selector == "autogroup:danger-all", selector == "autogroup:member",
+		selector == "autogroup:tagged", strings.HasPrefix(selector, "group:"):
+		return true
+	case strings.HasPrefix(selector, "tag:"), strings.HasPrefix(selector, "autogroup:"),
+		strings.HasPrefix(selector, "ipset:"), strings.Contains(selector, "@"):
+		return false
+	}
+	prefix, ok := u.prefixOf(selector)
+	return ok && prefix.Bits() < prefix.Addr().BitLen()
+}
+
+// narrow returns the selector a narrowed rule uses for a flow's destination:
+// the device's tag or owner, or the address, in place of a broad selector
+func (u *flowUniverse) narrow(rule flowRule, selector string, f flowKey) string {
+	if !u.isBroad(selector) {
+		return selector
+	}
+	dev := u.byAddr[f.dst]
+	switch {
+	case dev != nil && len(dev.Tags) > 0:
+		return u.narrowTag(rule, dev.Tags)
+	case dev != nil && dev.User != "":
+		return dev.User
+	case dev == nil && u.matches("autogroup:internet", f.dst, f.src):
+		// Exit node traffic is only granted through autogroup:internet
+		return "autogroup:internet"
+	}
+	if f.dst.Is6() {
+		return "[" + f.dst.String() + "]"
+	}
+	return f.dst.String()
+}
+
+// narrowTag picks which of a device's tags names it in a narrowed rule: a
+// tag the rule's own dst already uses, otherwise the tag on the fewest
+// devices, so the narrowed rule reaches as few other devices as possible
+func (u *flowUniverse) narrowTag(rule flowRule, tags []string) string {
+	_, dst := rule.selectors()
+	for _, tag := range tags {
+		if containsExact(dst, tag) {
+			return tag
+		}
+	}
+	best := tags[0]
+	for _, tag := range tags[1:] {
+		if u.tagCounts[tag] < u.tagCounts[best] || (u.tagCounts[tag] == u.tagCounts[best] && tag < best) {
+			best = tag
+		}
+	}
+	return best
+}
+
+// aclPermits returns the index of the first dst entry of rule that permits f
+func (u *flowUniverse) aclPermits(rule ACLRule, f flowKey) (int, bool) {
+	if rule.Action != "accept" || !protoMatches(rule.Proto, f.proto) {
+		return 0, false
+	}
+	if !u.anyMatches(rule.Src, f.src, f.dst) {
+		return 0, false
+	}
+	for i, d := range rule.Dst {
+		host, ports := splitACLDst(d)
+		if (isICMP(f.proto) || portsMatch(ports, f.port)) && u.matches(host, f.dst, f.src) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// grantPermits returns the index of the first dst selector of grant that permits f
+func (u *flowUniverse) grantPermits(grant Grant, f flowKey) (int, bool) {
+	ipMatch := false
+	for _, entry := range grant.IP {
+		if grantIPMatches(entry, f.proto, f.port) {
+			ipMatch = true
+			break
+		}
+	}
+	if !ipMatch || !u.anyMatches(grant.Src, f.src, f.dst) {
+		return 0, false
+	}
+	for i, d := range grant.Dst {
+		if u.matches(d, f.dst, f.src) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (u *flowUniverse) anyMatches(selectors []string, addr, peer netip.Addr) bool {
+	for _, s := range selectors {
+		if u.matches(s, addr, peer) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *flowUniverse) permits(rule flowRule, f flowKey) (int, bool) {
+	if rule.kind == types.FlowRuleGrant {
+		return u.grantPermits(rule.grant, f)
+	}
+	return u.aclPermits(rule.acl, f)
+}
+
+// splitACLDst splits an ACL dst entry such as "tag:web:80,443" into its
+// selector and ports
+func splitACLDst(dst string) (string, string) {
+	i := strings.LastIndex(dst, ":")
+	if i < 0 {
+		return dst, "*"
+	}
+	return strings.Trim(dst[:i], "[]"), dst[i+1:]
+}
+
+// portsMatch reports whether a port list such as "22,80-90" or "*" covers port
+func portsMatch(spec string, port uint16) bool {
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "*" {
+			return true
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil {
+				continue
+			}
+		}
+		if int(port) >= first && int(port) <= last {
+			return true
+		}
+	}
+	return false
+}
+
+// protoMatches reports whether an ACL proto value covers a protocol number.
+// An empty proto covers every protocol.
+func protoMatches(spec string, proto int) bool {
+	if spec == "" || spec == "*" {
+		return true
+	}
+	if n, err := strconv.Atoi(spec); err == nil {
+		return n == proto
+	}
+	n, ok := protoNumbers[strings.ToLower(spec)]
+	return ok && n == proto
+}
+
+// grantIPMatches reports whether a grant ip entry ("*", "443", "tcp:443",
+// "udp:*" or "icmp") covers a flow
+func grantIPMatches(entry string, proto int, port uint16) bool {
+	if entry == "*" {
+		return true
+	}
+	if protoSpec, ports, ok := strings.Cut(entry, ":"); ok {
+		return protoMatches(protoSpec, proto) && (isICMP(proto) || portsMatch(ports, port))
+	}
+	if _, err := strconv.Atoi(strings.SplitN(entry, "-", 2)[0]); err == nil {
+		return portsMatch(entry, port)
+	}
+	return protoMatches(entry, proto)
+}
+
+// protoName returns the policy name for a protocol number
+func protoName(proto int) string {
+	for name, n := range protoNumbers {
+		if n == proto {
+			return name
+		}
+	}
+	return strconv.Itoa(proto)
+}
+
+func isICMP(proto int) bool {
+	return proto == 1 || proto == 58
+}
+
+func inTailnetRange(addr netip.Addr) bool {
+	for _, p := range tailnetPrefixes {
+		if p.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// portList renders ports as a policy port list, joining runs into ranges
+func portList(ports []int) string {
+	sort.Ints(ports)
+	var parts []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && ports[j+1] == ports[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", ports[i], ports[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(ports[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// ruleJSON renders a policy rule on one line with its usual fields first
+func ruleJSON(raw map[string]json.RawMessage) string {
+	order := []string{"action", "src", "dst", "ip", "proto", "via", "app"}
+	var keys []string
+	for _, k := range order {
+		if _, ok := raw[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	var rest []string
+	for k := range raw {
+		if !containsExact(order, k) {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	var parts []string
+	for _, k := range keys {
+		value, err := json.Marshal(raw[k])
+		if err != nil {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%q: %s", k, value))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// parseFlowRules reads the accept ACL rules and network grants in policy order
+func parseFlowRules(raw string, policy ACLPolicy) ([]flowRule, error) {
+	standardized, err := hujson.Standardize([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	var rawRules struct {
+		ACLs   []map[string]json.RawMessage `json:"acls"`
+		Grants []map[string]json.RawMessage `json:"grants"`
+	}
+	if err := json.Unmarshal(standardized, &rawRules); err != nil {
+		return nil, err
+	}
+
+	var rules []flowRule
+	for i, acl := range policy.ACLs {
+		if acl.Action != "accept" || i >= len(rawRules.ACLs) {
+			continue
+		}
+		rules = append(rules, flowRule{kind: types.FlowRuleACL, number: i + 1, raw: rawRules.ACLs[i], acl: acl})
+	}
+	for i, grant := range policy.Grants {
+		// Capability-only grants carry no network traffic
+		if len(grant.IP) == 0 || i >= len(rawRules.Grants) {
+			continue
+		}
+		rules = append(rules, flowRule{kind: types.FlowRuleGrant, number: i + 1, raw: rawRules.Grants[i], grant: grant})
+	}
+	return rules, nil
+}
+
+// orientFlow orients a logged connection towards its lower port, the usual
+// service port. Each end logs the connection with itself as the source.
+func orientFlow(conn client.FlowConn) (flowTuple, bool) {
+	src, err := netip.ParseAddrPort(conn.Src)
+	if err != nil {
+		return flowTuple{}, false
+	}
+	dst, err := netip.ParseAddrPort(conn.Dst)
+	if err != nil {
+		return flowTuple{}, false
+	}
+	if src.Port() != 0 && src.Port() < dst.Port() {
+		src, dst = dst, src
+	}
+	return flowTuple{src: src, dst: dst, proto: conn.Proto}, true
+}
+
+// buildFlowReport maps flow logs onto the policy's rules. A flow counts
+// towards the first rule in policy order that permits it, so a rule whose
+// traffic is all covered by earlier rules is reported as unused.
+func buildFlowReport(rawPolicy string, devices []*client.Device, logs []client.NetworkFlowLog) (*types.FlowReport, error) {
+	policy, err := parseACLPolicy(rawPolicy)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseFlowRules(rawPolicy, policy)
+	if err != nil {
+		return nil, err
+	}
+	u := newFlowUniverse(policy, devices)
+
+	report := &types.FlowReport{
+		LogCount:   len(logs),
+		Rules:      []types.FlowRuleUsage{},
+		Unmatched:  []types.ObservedFlow{},
+		Unresolved: []string{},
+	}
+
+	tuples := make(map[flowTuple]*flowTotals)
+	var tupleOrder []flowTuple
+	for _, log := range logs {
+		if report.Since.IsZero() || (!log.Start.IsZero() && log.Start.Before(report.Since)) {
+			report.Since = log.Start
+		}
+		if log.End.After(report.Until) {
+			report.Until = log.End
+		}
+		var conns []client.FlowConn
+		conns = append(conns, log.VirtualTraffic...)
+		conns = append(conns, log.SubnetTraffic...)
+		conns = append(conns, log.ExitTraffic...)
+		for _, conn := range conns {
+			t, ok := orientFlow(conn)
+			if !ok {
+				continue
+			}
+			totals, ok := tuples[t]
+			if !ok {
+				totals = &flowTotals{}
+				tuples[t] = totals
+				tupleOrder = append(tupleOrder, t)
+			}
+			totals.packets += conn.TxPkts + conn.RxPkts
+			totals.bytes += conn.TxBytes + conn.RxBytes
+		}
+	}
+
+	perRule := make([]map[flowKey]*flowTotals, len(rules))
+	for i := range perRule {
+		perRule[i] = make(map[flowKey]*flowTotals)
+	}
+	unmatched := make(map[flowKey]*flowTotals)
+	seen := make(map[flowKey]bool)
+	add := func(m map[flowKey]*flowTotals, k flowKey, totals *flowTotals) {
+		if m[k] == nil {
+			m[k] = &flowTotals{}
+		}
+		m[k].packets += totals.packets
+		m[k].bytes += totals.bytes
+	}
+
+	for _, t := range tupleOrder {
+		totals := tuples[t]
+		forward := flowKey{src: t.src.Addr(), dst: t.dst.Addr(), port: t.dst.Port(), proto: t.proto}
+		reverse := flowKey{src: t.dst.Addr(), dst: t.src.Addr(), port: t.src.Port(), proto: t.proto}
+
+		matched := false
+		for _, k := range []flowKey{forward, reverse} {
+			for i, rule := range rules {
+				if _, ok := u.permits(rule, k); ok {
+					add(perRule[i], k, totals)
+					seen[k] = true
+					matched = true
+					break
+				}
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			add(unmatched, forward, totals)
+			seen[forward] = true
+		}
+	}
+	report.FlowCount = len(seen)
+
+	for i, rule := range rules {
+		usage := types.FlowRuleUsage{
+			Kind:   rule.kind,
+			Number: rule.number,
+			Rule:   ruleJSON(rule.raw),
+			Flows:  u.observed(perRule[i]),
+		}
+
+		var unresolvedSrc, unresolvedDst []string
+		src, dst := rule.selectors()
+		for _, s := range src {
+			if !u.resolvable(s) {
+				unresolvedSrc = append(unresolvedSrc, s)
+			}
+		}
+		for _, s := range dst {
+			if !u.resolvable(s) {
+				unresolvedDst = append(unresolvedDst, s)
+			}
+		}
+		unresolved := append(unresolvedSrc, unresolvedDst...)
+
+		switch {
+		case len(logs) == 0:
+			usage.Note = "No flow logs in the window"
+		case len(usage.Flows) == 0 && len(unresolved) > 0:
+			usage.Note = fmt.Sprintf("No traffic matched, but the rule uses selectors flow logs can't resolve: %s", strings.Join(unresolved, ", "))
+		case len(usage.Flows) == 0:
+			usage.Unused = true
+			usage.Note = "No traffic in the window"
+		case len(unresolvedDst) > 0:
+			usage.Note = fmt.Sprintf("Not narrowed: destinations %s can't be resolved", strings.Join(unresolvedDst, ", "))
+		default:
+			usage.Proposed, usage.Note = u.propose(rule, perRule[i])
+		}
+		report.Rules = append(report.Rules, usage)
+	}
+
+	report.Unmatched = u.observed(unmatched)
+	for selector := range u.unresolved {
+		report.Unresolved = append(report.Unresolved, selector)
+	}
+	sort.Strings(report.Unresolved)
+	return report, nil
+}
+
+// observed lists flows with their device names, largest first
+func (u *flowUniverse) observed(flows map[flowKey]*flowTotals) []types.ObservedFlow {
+	list := []types.ObservedFlow{}
+	for k, totals := range flows {
+		list = append(list, types.ObservedFlow{
+			Src:     u.name(k.src),
+			SrcAddr: k.src.String(),
+			Dst:     u.name(k.dst),
+			DstAddr: k.dst.String(),
+			Port:    int(k.port),
+			Proto:   protoName(k.proto),
+			Packets: totals.packets,
+			Bytes:   totals.bytes,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bytes != list[j].Bytes {
+			return list[i].Bytes > list[j].Bytes
+		}
+		a, b := list[i], list[j]
+		return fmt.Sprintf("%s %s %05d %s", a.Src, a.Dst, a.Port, a.Proto) < fmt.Sprintf("%s %s %05d %s", b.Src, b.Dst, b.Port, b.Proto)
+	})
+	return list
+}
+
+// propose narrows a rule to the destinations and ports its flows used.
+// It returns "" when the rule is already no broader than the traffic.
+func (u *flowUniverse) propose(rule flowRule, flows map[flowKey]*flowTotals) (string, string) {
+	type target struct {
+		ports    map[int]bool
+		original string // Ports of the dst entry the traffic matched
+	}
+	targets := make(map[string]*target)
+	protoPorts := make(map[int]map[int]bool)
+
+	for k := range flows {
+		i, ok := u.permits(rule, k)
+		if !ok {
+			continue
+		}
+		var selector, original string
+		if rule.kind == types.FlowRuleGrant {
+			selector, original = rule.grant.Dst[i], "*"
+		} else {
+			selector, original = splitACLDst(rule.acl.Dst[i])
+		}
+		name := u.narrow(rule, selector, k)
+		t, ok := targets[name]
+		if !ok {
+			t = &target{ports: make(map[int]bool), original: original}
+			targets[name] = t
+		}
+		if protoPorts[k.proto] == nil {
+			protoPorts[k.proto] = make(map[int]bool)
+		}
+		// ICMP has no ports, so it doesn't narrow the port list
+		if !isICMP(k.proto) && k.port != 0 {
+			t.ports[int(k.port)] = true
+			protoPorts[k.proto][int(k.port)] = true
+		}
+	}
+
+	var names []string
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	proposed := make(map[string]json.RawMessage, len(rule.raw))
+	for k, v := range rule.raw {
+		proposed[k] = v
+	}
+
+	var kept []string
+	if rule.kind == types.FlowRuleGrant {
+		var ip []string
+		var protos []int
+		for proto := range protoPorts {
+			protos = append(protos, proto)
+		}
+		sort.Ints(protos)
+		for _, proto := range protos {
+			ports := sortedPorts(protoPorts[proto])
+			switch {
+			case len(ports) == 0:
+				ip = append(ip, protoName(proto)+":*")
+			case len(ports) > maxProposedPorts:
+				kept = append(kept, protoName(proto))
+			default:
+				for _, p := range strings.Split(portList(ports), ",") {
+					ip = append(ip, protoName(proto)+":"+p)
+				}
+			}
+		}
+		// Ports are shared by every destination of a grant, so too many
+		// ports on any protocol keeps the grant's own ip list
+		if len(kept) > 0 {
+			ip = rule.grant.IP
+		}
+		proposed["dst"] = rawJSON(names)
+		proposed["ip"] = rawJSON(ip)
+	} else {
+		var dst []string
+		for _, name := range names {
+			t := targets[name]
+			ports := sortedPorts(t.ports)
+			spec := portList(ports)
+			switch {
+			case len(ports) == 0:
+				spec = t.original
+			case len(ports) > maxProposedPorts:
+				spec = t.original
+				kept = append(kept, name)
+			}
+			dst = append(dst, name+":"+spec)
+		}
+		proposed["dst"] = rawJSON(dst)
+	}
+
+	current, narrowed := ruleJSON(rule.raw), ruleJSON(proposed)
+	if narrowed == current || sameSelectors(rule, proposed) {
+		return "", ""
+	}
+	note := fmt.Sprintf("Narrowed to %d flow(s) to %d destination(s)", len(flows), len(names))
+	if len(kept) > 0 {
+		note += fmt.Sprintf("; kept all ports for %s (over %d ports seen)", strings.Join(kept, ", "), maxProposedPorts)
+	}
+	return narrowed, note
+}
+
+// sameSelectors reports whether a proposal only reorders the rule's own entries
+func sameSelectors(rule flowRule, proposed map[string]json.RawMessage) bool {
+	var current, next, ip []string
+	json.Unmarshal(proposed["dst"], &next)
+	if rule.kind == types.FlowRuleGrant {
+		current = append(append(current, rule.grant.Dst...), rule.grant.IP...)
+		json.Unmarshal(proposed["ip"], &ip)
+		next = append(next, ip...)
+	} else {
+		current = append(current, rule.acl.Dst...)
+	}
+	if len(current) != len(next) {
+		return false
+	}
+	sort.Strings(current)
+	sort.Strings(next)
+	for i := range current {
+		if current[i] != next[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedPorts(set map[int]bool) []int {
+	var list []int
+	for k := range set {
+		list = append(list, k)
+	}
+	sort.Ints(list)
+	return list
+}
+
+func rawJSON(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
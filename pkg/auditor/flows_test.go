@@ -0,0 +1,212 @@
+package auditor
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+const flowsTestPolicy = `{
+	"groups": {"group:eng": ["alice@example.com", "bob@example.com"]},
+	"acls": [
+		// Engineers reach everything
+		{"action": "accept", "src": ["group:eng"], "dst": ["*:*"]},
+		{"action": "accept", "src": ["tag:ci"], "dst": ["tag:web:22,443"]},
+		{"action": "accept", "src": ["autogroup:admin"], "dst": ["tag:db:*"]},
+		{"action": "accept", "src": ["bob@example.com"], "dst": ["tag:ci:8080"]},
+	],
+	"grants": [
+		{"src": ["tag:ci"], "dst": ["*"], "ip": ["*"]},
+		{"src": ["group:eng"], "dst": ["tag:web"], "app": {"tailscale.com/cap/tailsql": [{}]}},
+	],
+}`
+
+func flowsTestDevices() []*client.Device {
+	return []*client.Device{
+		{Name: "alice-laptop", User: "alice@example.com", Addresses: []string{"100.64.0.1"}},
+		{Name: "bob-laptop", User: "bob@example.com", Addresses: []string{"100.64.0.2"}},
+		{Name: "web", Tags: []string{"tag:web"}, Addresses: []string{"100.64.0.10"}},
+		{Name: "db", Tags: []string{"tag:db"}, Addresses: []string{"100.64.0.11"}},
+		{Name: "ci", Tags: []string{"tag:ci"}, Addresses: []string{"100.64.0.20"}},
+	}
+}
+
+func flowsTestLogs() []client.NetworkFlowLog {
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	return []client.NetworkFlowLog{
+		{NodeID: "alice", Start: start, End: start.Add(5 * time.Second), VirtualTraffic: []client.FlowConn{
+			{Proto: 6, Src: "100.64.0.1:51000", Dst: "100.64.0.10:443", TxBytes: 1000},
+			{Proto: 6, Src: "100.64.0.1:51001", Dst: "100.64.0.11:5432", TxBytes: 500},
+			{Proto: 1, Src: "100.64.0.1:0", Dst: "100.64.0.10:0", TxBytes: 84},
+		}},
+		// The same connection logged by the web server, from its end
+		{NodeID: "web", Start: start, End: start.Add(5 * time.Second), VirtualTraffic: []client.FlowConn{
+			{Proto: 6, Src: "100.64.0.10:443", Dst: "100.64.0.1:51000", RxBytes: 1000},
+			{Proto: 6, Src: "100.64.0.10:443", Dst: "100.64.0.2:52000", RxBytes: 200},
+			{Proto: 6, Src: "100.64.0.10:8443", Dst: "100.64.0.99:40000", RxBytes: 10},
+		}},
+		{NodeID: "ci", Start: start.Add(time.Hour), End: start.Add(time.Hour + 5*time.Second), VirtualTraffic: []client.FlowConn{
+			{Proto: 6, Src: "100.64.0.20:40000", Dst: "100.64.0.10:22", TxBytes: 300},
+		}, ExitTraffic: []client.FlowConn{
+			{Proto: 17, Src: "100.64.0.20:40001", Dst: "8.8.8.8:53", TxBytes: 60},
+		}},
+	}
+}
+
+func TestBuildFlowReport(t *testing.T) {
+	report, err := buildFlowReport(flowsTestPolicy, flowsTestDevices(), flowsTestLogs())
+	if err != nil {
+		t.Fatalf("buildFlowReport() error: %v", err)
+	}
+
+	if report.LogCount != 3 || report.FlowCount != 7 {
+		t.Errorf("LogCount = %d, FlowCount = %d, want 3 and 7", report.LogCount, report.FlowCount)
+	}
+	if !report.Since.Equal(time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)) || !report.Until.Equal(time.Date(2026, 10, 12, 10, 0, 5, 0, time.UTC)) {
+		t.Errorf("window = %v to %v", report.Since, report.Until)
+	}
+	if len(report.Rules) != 5 {
+		t.Fatalf("got %d rules, want 4 ACL rules and 1 network grant", len(report.Rules))
+	}
+
+	broad := report.Rules[0]
+	if broad.Kind != types.FlowRuleACL || broad.Number != 1 || len(broad.Flows) != 4 || broad.Unused {
+		t.Errorf("ACL rule 1 = %+v", broad)
+	}
+	if !strings.Contains(broad.Proposed, `"dst": ["tag:db:5432","tag:web:443"]`) || !strings.Contains(broad.Proposed, `"src": ["group:eng"]`) {
+		t.Errorf("ACL rule 1 Proposed = %s", broad.Proposed)
+	}
+	if broad.Flows[0].Src != "alice-laptop" || broad.Flows[0].Dst != "web" || broad.Flows[0].Port != 443 || broad.Flows[0].Bytes != 2000 {
+		t.Errorf("connection logged from both ends should be one flow: %+v", broad.Flows[0])
+	}
+
+	if ports := report.Rules[1]; !strings.Contains(ports.Proposed, `"dst": ["tag:web:22"]`) {
+		t.Errorf("ACL rule 2 Proposed = %s", ports.Proposed)
+	}
+	if admin := report.Rules[2]; admin.Unused || admin.Proposed != "" || !strings.Contains(admin.Note, "autogroup:admin") {
+		t.Errorf("ACL rule 3 with unresolved selector = %+v", admin)
+	}
+	if unused := report.Rules[3]; !unused.Unused || unused.Proposed != "" {
+		t.Errorf("ACL rule 4 = %+v, want unused", unused)
+	}
+
+	// ci to web:22 counts towards ACL rule 2, which comes first
+	grant := report.Rules[4]
+	if grant.Kind != types.FlowRuleGrant || grant.Number != 1 || len(grant.Flows) != 1 {
+		t.Errorf("grant 1 = %+v", grant)
+	}
+	if !strings.Contains(grant.Proposed, `"dst": ["autogroup:internet"], "ip": ["udp:53"]`) {
+		t.Errorf("grant 1 Proposed = %s", grant.Proposed)
+	}
+
+	if len(report.Unmatched) != 1 || report.Unmatched[0].SrcAddr != "100.64.0.99" || report.Unmatched[0].Port != 8443 {
+		t.Errorf("Unmatched = %+v", report.Unmatched)
+	}
+	if len(report.Unresolved) != 1 || report.Unresolved[0] != "autogroup:admin" {
+		t.Errorf("Unresolved = %v", report.Unresolved)
+	}
+
+	used, unused, narrowed := report.Counts()
+	if used != 3 || unused != 1 || narrowed != 3 {
+		t.Errorf("Counts() = %d, %d, %d, want 3, 1, 3", used, unused, narrowed)
+	}
+}
+
+func TestBuildFlowReportShadowedRule(t *testing.T) {
+	policy := `{"acls": [
+		{"action": "accept", "src": ["*"], "dst": ["*:*"]},
+		{"action": "accept", "src": ["alice@example.com"], "dst": ["tag:web:443"]},
+	]}`
+	report, err := buildFlowReport(policy, flowsTestDevices(), flowsTestLogs())
+	if err != nil {
+		t.Fatalf("buildFlowReport() error: %v", err)
+	}
+	if len(report.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(report.Rules))
+	}
+	if shadowed := report.Rules[1]; !shadowed.Unused || len(shadowed.Flows) != 0 {
+		t.Errorf("rule shadowed by rule 1 = %+v, want unused", shadowed)
+	}
+}
+
+func TestNarrowTag(t *testing.T) {
+	devices := []*client.Device{
+		{Name: "web", Tags: []string{"tag:all", "tag:prod", "tag:web"}, Addresses: []string{"100.64.0.10"}},
+		{Name: "db", Tags: []string{"tag:all", "tag:prod"}, Addresses: []string{"100.64.0.11"}},
+		{Name: "ci", Tags: []string{"tag:all"}, Addresses: []string{"100.64.0.20"}},
+	}
+	u := newFlowUniverse(ACLPolicy{}, devices)
+	f := flowKey{src: netip.MustParseAddr("100.64.0.1"), dst: netip.MustParseAddr("100.64.0.10"), port: 443, proto: 6}
+
+	// A tag the rule already names wins over a more specific one
+	named := flowRule{kind: types.FlowRuleACL, acl: ACLRule{Action: "accept", Dst: []string{"tag:prod:22", "*:443"}}}
+	if got := u.narrow(named, "*", f); got != "tag:prod" {
+		t.Errorf("narrow() with tag in dst = %q, want tag:prod", got)
+	}
+
+	// Otherwise the tag on the fewest devices, not the first alphabetically
+	broad := flowRule{kind: types.FlowRuleACL, acl: ACLRule{Action: "accept", Dst: []string{"*:*"}}}
+	if got := u.narrow(broad, "*", f); got != "tag:web" {
+		t.Errorf("narrow() = %q, want tag:web", got)
+	}
+}
+
+func TestBuildFlowReportWithoutLogs(t *testing.T) {
+	report, err := buildFlowReport(flowsTestPolicy, flowsTestDevices(), nil)
+	if err != nil {
+		t.Fatalf("buildFlowReport() error: %v", err)
+	}
+	for _, r := range report.Rules {
+		if r.Unused || r.Proposed != "" {
+			t.Errorf("%s %d without logs = %+v, want neither unused nor narrowed", r.Kind, r.Number, r)
+		}
+	}
+}
+
+func TestFlowPortMatching(t *testing.T) {
+	tests := []struct {
+		spec  string
+		port  uint16
+		match bool
+	}{
+		{"*", 22, true},
+		{"22", 22, true},
+		{"80,443", 443, true},
+		{"8000-8100", 8080, true},
+		{"8000-8100", 9000, false},
+		{"22", 2222, false},
+	}
+	for _, tt := range tests {
+		if got := portsMatch(tt.spec, tt.port); got != tt.match {
+			t.Errorf("portsMatch(%q, %d) = %v, want %v", tt.spec, tt.port, got, tt.match)
+		}
+	}
+
+	grants := []struct {
+		entry string
+		proto int
+		port  uint16
+		match bool
+	}{
+		{"*", 17, 53, true},
+		{"tcp:443", 6, 443, true},
+		{"tcp:443", 17, 443, false},
+		{"udp:*", 17, 5353, true},
+		{"443", 6, 443, true},
+		{"icmp", 1, 0, true},
+		{"icmp", 6, 22, false},
+	}
+	for _, tt := range grants {
+		if got := grantIPMatches(tt.entry, tt.proto, tt.port); got != tt.match {
+			t.Errorf("grantIPMatches(%q, %d, %d) = %v, want %v", tt.entry, tt.proto, tt.port, got, tt.match)
+		}
+	}
+
+	if got := portList([]int{443, 22, 80, 81, 82}); got != "22,80-82,443" {
+		t.Errorf("portList() = %q", got)
+	}
+}
@@ -0,0 +1,158 @@
+package auditor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// Admin console pages for renewing node keys and auth keys
+const (
+	machinesAdminURL = "https://login.tailscale.com/admin/machines"
+	keysAdminURL     = "https://login.tailscale.com/admin/settings/keys"
+)
+
+// ExpiryCollector forecasts node key and auth key expirations
+type ExpiryCollector struct {
+	client *client.Client
+}
+
+// NewExpiryCollector creates a new expiry collector
+func NewExpiryCollector(c *client.Client) *ExpiryCollector {
+	return &ExpiryCollector{client: c}
+}
+
+// Collect returns the node keys and auth keys that expire between from and to
+func (e *ExpiryCollector) Collect(ctx context.Context, from, to time.Time) (*types.ExpiryForecast, error) {
+	forecast, err := collectForecast(ctx, e.client, FetchTailnetData(ctx, e.client), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return forecast.Expirations(), nil
+}
+
+// collectForecast forecasts the lifecycle events of the devices and auth
+// keys in data between from and to. Auth keys may not be readable with
+// every API key, so they're left out if they can't be listed. Key owners
+// come from the full key listing; without it, only the caller's keys are
+// forecast and their owner is left blank.
+func collectForecast(ctx context.Context, c *client.Client, data *TailnetData, from, to time.Time) (*types.ExpiryForecast, error) {
+	if data.DevicesErr != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", data.DevicesErr)
+	}
+
+	var keys []keyInfo
+	var creators keyCreators
+	if data.KeysErr == nil {
+		keys = authKeyInfo(data.Keys, from)
+		creators = newKeyCreators(data.Users, data.UsersErr)
+	} else if keyIDs, err := c.GetKeys(ctx); err == nil {
+		keys = fetchKeyInfo(ctx, c, keyIDs, from)
+	}
+
+	return buildExpiryForecast(data.Devices, keys, creators, from, to), nil
+}
+
+// deviceAdminURL links to a device's page in the admin console
+func deviceAdminURL(dev *client.Device) string {
+	if len(dev.Addresses) > 0 {
+		return machinesAdminURL + "/" + dev.Addresses[0]
+	}
+	return machinesAdminURL
+}
+
+// buildExpiryForecast lists node key expiries, auth key expiries and
+// devices crossing their stale threshold in the window (from, to]. Auth key
+// events are owned by the key's creator.
+func buildExpiryForecast(devices []*client.Device, keys []keyInfo, creators keyCreators, from, to time.Time) *types.ExpiryForecast {
+	forecast := &types.ExpiryForecast{From: from, To: to, Events: []types.ForecastEvent{}}
+	inWindow := func(t time.Time) bool {
+		return t.After(from) && !t.After(to)
+	}
+
+	for _, dev := range devices {
+		// Shared-in devices expire and go stale on their home tailnet's terms
+		if dev.IsExternal {
+			continue
+		}
+
+		owner := dev.User
+		if len(dev.Tags) > 0 {
+			owner = strings.Join(dev.Tags, ",")
+		}
+
+		if !dev.KeyExpiryDisabled && dev.Expires != "" {
+			if expires, err := time.Parse(time.RFC3339, dev.Expires); err == nil && inWindow(expires) {
+				forecast.Events = append(forecast.Events, types.ForecastEvent{
+					Date:     expires,
+					Kind:     types.ForecastNodeKeyExpiry,
+					ID:       dev.DeviceID,
+					Name:     dev.Name,
+					Owner:    dev.User,
+					Tags:     dev.Tags,
+					Detail:   fmt.Sprintf("%s, %s", dev.Hostname, owner),
+					AdminURL: deviceAdminURL(dev),
+				})
+			}
+		}
+
+		if dev.LastSeen != "" {
+			if lastSeen, err := time.Parse(time.RFC3339, dev.LastSeen); err == nil {
+				rule := lifecyclePolicy.DeviceRule(dev.Tags, dev.OS, dev.User)
+				staleAt := lastSeen.AddDate(0, 0, rule.StaleThresholdDays())
+				if inWindow(staleAt) {
+					detail := fmt.Sprintf("%s, last seen %s", dev.Hostname, lastSeen.Format("2006-01-02"))
+					if !rule.IsDefault() {
+						detail += fmt.Sprintf(" (rule %q: >%d days)", rule.Name, rule.StaleThresholdDays())
+					}
+					forecast.Events = append(forecast.Events, types.ForecastEvent{
+						Date:     staleAt,
+						Kind:     types.ForecastDeviceStale,
+						ID:       dev.DeviceID,
+						Name:     dev.Name,
+						Owner:    dev.User,
+						Tags:     dev.Tags,
+						Detail:   detail,
+						AdminURL: deviceAdminURL(dev),
+					})
+				}
+			}
+		}
+	}
+
+	for _, key := range keys {
+		if key.Expires.IsZero() || !inWindow(key.Expires) {
+			continue
+		}
+		var caps []string
+		if key.Reusable {
+			caps = append(caps, "reusable")
+		}
+		if key.Preauthorized {
+			caps = append(caps, "pre-authorized")
+		}
+		if key.Ephemeral {
+			caps = append(caps, "ephemeral")
+		}
+		if len(key.Tags) > 0 {
+			caps = append(caps, "tags: "+strings.Join(key.Tags, ","))
+		}
+		forecast.Events = append(forecast.Events, types.ForecastEvent{
+			Date:     key.Expires,
+			Kind:     types.ForecastAuthKeyExpiry,
+			ID:       key.ID,
+			Name:     key.ID,
+			Owner:    creators.name(key.UserID),
+			Tags:     key.Tags,
+			Detail:   strings.Join(caps, ", "),
+			AdminURL: keysAdminURL,
+		})
+	}
+
+	forecast.Sort()
+	return forecast
+}
@@ -0,0 +1,136 @@
+package auditor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestBuildExpiryForecast(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+
+	devices := []*client.Device{
+		// Key expires in the window; seen recently enough to stay fresh
+		{DeviceID: "d1", Name: "laptop", User: "alice@example.com", Expires: day(11, 15).Format(time.RFC3339), LastSeen: day(10, 15).Format(time.RFC3339)},
+		// Goes stale 60 days after 2026-09-01
+		{DeviceID: "d2", Name: "old-server", Tags: []string{"tag:server"}, KeyExpiryDisabled: true, Expires: day(10, 5).Format(time.RFC3339), LastSeen: day(9, 1).Format(time.RFC3339)},
+		// Already stale before the window
+		{DeviceID: "d3", Name: "gone", LastSeen: day(6, 1).Format(time.RFC3339)},
+		// Key expires after the window
+		{DeviceID: "d4", Name: "later", Expires: day(12, 2).Format(time.RFC3339)},
+		// External devices are skipped
+		{DeviceID: "d5", Name: "shared", IsExternal: true, Expires: day(10, 10).Format(time.RFC3339)},
+	}
+	keys := []keyInfo{
+		{ID: "k1", Reusable: true, Tags: []string{"tag:ci"}, Expires: day(10, 20), UserID: "u1"},
+		{ID: "k2", Expires: day(12, 20)},
+	}
+
+	creators := newKeyCreators([]client.User{{ID: "u1", LoginName: "bob@example.com"}}, nil)
+	forecast := buildExpiryForecast(devices, keys, creators, from, to)
+
+	want := []struct {
+		kind types.ForecastEventKind
+		id   string
+		date time.Time
+	}{
+		{types.ForecastAuthKeyExpiry, "k1", day(10, 20)},
+		{types.ForecastDeviceStale, "d2", day(10, 31)},
+		{types.ForecastNodeKeyExpiry, "d1", day(11, 15)},
+	}
+	if len(forecast.Events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(forecast.Events), len(want), forecast.Events)
+	}
+	for i, w := range want {
+		e := forecast.Events[i]
+		if e.Kind != w.kind || e.ID != w.id || !e.Date.Equal(w.date) {
+			t.Errorf("event %d = %s %s %s, want %s %s %s", i, e.Kind, e.ID, e.Date.Format("2006-01-02"), w.kind, w.id, w.date.Format("2006-01-02"))
+		}
+	}
+
+	// Auth key events are owned by the key's creator
+	if owner := forecast.Events[0].Owner; owner != "bob@example.com" {
+		t.Errorf("auth key event owner = %q, want bob@example.com", owner)
+	}
+}
+
+func TestBuildExpiryForecastLifecycleRule(t *testing.T) {
+	defer SetLifecyclePolicy(nil)
+	SetLifecyclePolicy(&types.LifecyclePolicy{Rules: []types.LifecycleRule{
+		{Name: "ci", Tags: []string{"tag:ci"}, StaleDays: 7},
+	}})
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	devices := []*client.Device{
+		{DeviceID: "d1", Name: "runner", Tags: []string{"tag:ci"}, LastSeen: from.AddDate(0, 0, -2).Format(time.RFC3339)},
+	}
+
+	forecast := buildExpiryForecast(devices, nil, keyCreators{}, from, from.AddDate(0, 0, 10))
+	if len(forecast.Events) != 1 || !forecast.Events[0].Date.Equal(from.AddDate(0, 0, 5)) {
+		t.Fatalf("expected stale event on day 5 under the ci rule, got %+v", forecast.Events)
+	}
+}
+
+func TestAsOfClock(t *testing.T) {
+	future := time.Now().AddDate(0, 0, 90)
+	clock := AsOfClock(future)
+	if !clock.Now().Equal(future) || !clock.AsOf().Equal(future) {
+		t.Fatalf("Now = %v, AsOf = %v, want %v", clock.Now(), clock.AsOf(), future)
+	}
+
+	// A device seen yesterday is stale 90 days from now
+	seen := []*client.Device{
+		{Name: "laptop", LastSeen: time.Now().AddDate(0, 0, -1).Format(time.RFC3339)},
+	}
+	d := &DeviceAuditor{clock: clock}
+	if result := d.checkStaleDevices(seen); result.Pass {
+		t.Error("expected device to be stale as of the future date")
+	}
+
+	// The zero clock is the current time
+	d = &DeviceAuditor{}
+	if !d.clock.AsOf().IsZero() {
+		t.Error("zero clock should have no as-of date")
+	}
+	if result := d.checkStaleDevices(seen); !result.Pass {
+		t.Error("expected device to be fresh with the system clock")
+	}
+}
+
+func TestAsOfClockKeepsInfraOnline(t *testing.T) {
+	clock := AsOfClock(time.Now().AddDate(0, 0, 30))
+	recent := time.Now().Add(-5 * time.Minute).Format(time.RFC3339)
+
+	// Recorders online now aren't assumed offline on the as-of date
+	s := &SSHAuditor{clock: clock}
+	policy := ACLPolicy{SSH: []SSHRule{
+		{Action: "accept", Src: []string{"group:ops"}, Dst: []string{"tag:prod"}, Users: []string{"root"}, Recorder: []string{"tag:recorder"}, EnforceRecorder: true},
+	}}
+	recorders := []*client.Device{
+		{Name: "rec1", Tags: []string{"tag:recorder"}, LastSeen: recent},
+		{Name: "rec2", Tags: []string{"tag:recorder"}, LastSeen: recent},
+	}
+	if result := s.checkRecorderHealth(policy, recorders); !result.Pass {
+		t.Errorf("SSH-006 with online recorders = %v, want pass", result.Details)
+	}
+
+	// Neither are subnet routers
+	n := &NetworkAuditor{clock: clock}
+	routers := []*client.Device{
+		{Name: "r1", Tags: []string{"tag:router"}, OS: "linux", LastSeen: recent, AdvertisedRoutes: []string{"10.0.0.0/16"}, EnabledRoutes: []string{"10.0.0.0/16"}},
+		{Name: "r2", Tags: []string{"tag:router"}, OS: "linux", LastSeen: recent, AdvertisedRoutes: []string{"10.0.0.0/16"}, EnabledRoutes: []string{"10.0.0.0/16"}},
+	}
+	if result := n.checkInfraHygiene(routers, ACLPolicy{}); !result.Pass {
+		t.Errorf("NET-012 with online routers = %v, want pass", result.Details)
+	}
+	result := n.checkSubnetRoutes(context.Background(), routers)
+	if details, _ := result.Details.([]string); strings.Contains(strings.Join(details, "\n"), "all offline") {
+		t.Errorf("NET-003 with online routers = %v, want no offline routes", details)
+	}
+}
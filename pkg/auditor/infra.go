@@ -0,0 +1,151 @@
+package auditor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// infraMaxOffline is how long an infrastructure device can go unseen before
+// it's flagged. Routers and connectors that other devices depend on should
+// be online, so this is much shorter than the DEV-004 stale threshold.
+const infraMaxOffline = 7 * 24 * time.Hour
+
+// Infrastructure roles a device can hold
+const (
+	roleExitNode     = "exit node"
+	roleSubnetRouter = "subnet router"
+	roleAppConnector = "app connector"
+	roleWorkstation  = "user workstation"
+)
+
+// appConnectorTags returns the tags designated as app connectors in nodeAttrs
+func appConnectorTags(policy ACLPolicy) []string {
+	var tags []string
+	for _, attr := range policy.NodeAttrs {
+		if attr.App == nil {
+			continue
+		}
+		for _, connector := range attr.App.AppConnectors {
+			for _, tag := range connector.Connectors {
+				if !containsExact(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// deviceRoles returns the infrastructure roles a device holds, followed by
+// roleWorkstation if it also looks like a personal device
+func deviceRoles(dev *client.Device, connectorTags []string) []string {
+	var roles []string
+	exitNode, subnetRouter := false, false
+	for _, route := range dev.AdvertisedRoutes {
+		if route == "0.0.0.0/0" || route == "::/0" {
+			exitNode = true
+		} else {
+			subnetRouter = true
+		}
+	}
+
+	isConnector := false
+	for _, tag := range dev.Tags {
+		if containsExact(connectorTags, tag) {
+			isConnector = true
+			break
+		}
+	}
+
+	if exitNode {
+		roles = append(roles, roleExitNode)
+	}
+	// App connectors advertise the routes of the domains they serve; don't
+	// also count those as subnet routes
+	if subnetRouter && !isConnector {
+		roles = append(roles, roleSubnetRouter)
+	}
+	if isConnector {
+		roles = append(roles, roleAppConnector)
+	}
+	if len(roles) > 0 && isDevDevice(dev) {
+		roles = append(roles, roleWorkstation)
+	}
+	return roles
+}
+
+// checkInfraHygiene judges whether infrastructure is online at the current
+// time rather than the --as-of date, which can't predict availability
+func (n *NetworkAuditor) checkInfraHygiene(devices []*client.Device, policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "NET-012",
+		Title:       "Infrastructure devices fail role hygiene",
+		Severity:    types.Medium,
+		Category:    types.NetworkExposure,
+		Description: "Subnet routers, exit nodes and app connectors carry traffic for other devices. They should be tagged, owned by this tailnet, up to date, online, and dedicated to a single role.",
+		Remediation: "Tag infrastructure devices so they don't depend on a user account. Keep clients updated and monitor availability. Move exit node and subnet router roles off personal workstations and onto dedicated hosts.",
+		Source:      "https://tailscale.com/kb/1019/subnets",
+		Pass:        true,
+	}
+
+	connectorTags := appConnectorTags(policy)
+	now := time.Now()
+
+	var problems []string
+	identityIssue := false
+	for _, dev := range devices {
+		roles := deviceRoles(dev, connectorTags)
+		if len(roles) == 0 {
+			continue
+		}
+
+		var issues []string
+		if len(dev.Tags) == 0 {
+			issues = append(issues, fmt.Sprintf("owned by user %s instead of tagged", dev.User))
+			identityIssue = true
+		}
+		if dev.IsExternal {
+			issues = append(issues, "shared in from an external tailnet")
+			identityIssue = true
+		}
+		if dev.UpdateAvailable {
+			issues = append(issues, fmt.Sprintf("client %s has an update available", dev.ClientVersion))
+		}
+		for _, b := range deviceBulletins(dev) {
+			issues = append(issues, fmt.Sprintf("client %s affected by %s", dev.ClientVersion, b.ID))
+		}
+		if lastSeen, err := time.Parse(time.RFC3339, dev.LastSeen); err == nil && now.Sub(lastSeen) > infraMaxOffline {
+			issues = append(issues, fmt.Sprintf("last seen %d days ago", int(now.Sub(lastSeen).Hours()/24)))
+		}
+		if len(roles) > 1 {
+			issues = append(issues, "combines "+strings.Join(roles, ", ")+" roles")
+		}
+
+		if len(issues) > 0 {
+			problems = append(problems, fmt.Sprintf("%s (%s) [%s]: %s", dev.Name, dev.Hostname, strings.Join(roles, ", "), strings.Join(issues, "; ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		finding.Pass = false
+		finding.Details = problems
+		finding.Description = fmt.Sprintf("Found %d infrastructure device(s) with hygiene issues.", len(problems))
+		// User-owned or external infrastructure gives one identity control
+		// over traffic for everyone routed through it
+		if identityIssue {
+			finding.Severity = types.High
+		}
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Re-authenticate infrastructure devices with a tagged auth key, update their clients, and split combined roles onto dedicated hosts",
+			AdminURL:    "https://login.tailscale.com/admin/machines",
+			DocURL:      "https://tailscale.com/kb/1068/tags",
+		}
+	}
+
+	return finding
+}
@@ -0,0 +1,178 @@
+package auditor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// DeviceInventoryCollector builds a per-device view of audit results
+type DeviceInventoryCollector struct {
+	client *client.Client
+	clock  Clock
+}
+
+// NewDeviceInventoryCollector creates a new device inventory collector
+func NewDeviceInventoryCollector(c *client.Client, clock Clock) *DeviceInventoryCollector {
+	return &DeviceInventoryCollector{client: c, clock: clock}
+}
+
+// Collect fetches devices and evaluates each one against the device-level checks
+func (c *DeviceInventoryCollector) Collect(ctx context.Context) (*types.DeviceInventoryReport, error) {
+	devices, err := c.client.GetDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	// DNS config is only needed for DEV-007; a nil config skips that check
+	dnsConfig, _ := c.client.GetDNSConfig(ctx)
+
+	_, latestMajor, latestMinor := expectedClientVersion(ctx, devices, c.clock.Now())
+
+	// Posture is only needed for DEV-014 to DEV-017; devices without it
+	// skip those checks and are listed in the report
+	posture, unavailable := fetchDevicePosture(ctx, c.client, devices)
+
+	report := buildDeviceInventory(ctx, devices, dnsConfig, posture, latestMajor, latestMinor, c.clock)
+	report.Tailnet = c.client.Tailnet()
+	report.GeneratedAt = time.Now()
+	report.AsOf = c.clock.asOfPtr()
+	report.PostureUnavailable = unavailable
+	return report, nil
+}
+
+// buildDeviceInventory runs each device-level check against one device at a
+// time, so a device's failed checks are exactly those the full audit would
+// attribute to it.
+func buildDeviceInventory(ctx context.Context, devices []*client.Device, dnsConfig *client.DNSConfig, posture map[string]*client.DevicePosture, latestMajor, latestMinor int, clock Clock) *types.DeviceInventoryReport {
+	now := clock.Now()
+	osVersions := osVersionsFrom(posture)
+	checkMissing := posturePolicy != nil || postureInUse(posture)
+
+	d := &DeviceAuditor{clock: clock}
+	n := &NetworkAuditor{clock: clock}
+
+	checks := []func([]*client.Device) types.Suggestion{
+		d.checkTaggedDevicesKeyExpiry, // DEV-001
+		d.checkUserDevicesWithTags,    // DEV-002
+		d.checkStaleDevices,           // DEV-004
+		d.checkUnauthorizedDevices,    // DEV-005
+		d.checkExternalDevices,        // DEV-006
+		func(devs []*client.Device) types.Suggestion { // DEV-007
+			return d.checkSensitiveMachineNames(devs, dnsConfig)
+		},
+		d.checkLongKeyExpiry,                // DEV-008
+		d.checkUserDevicesKeyExpiryDisabled, // DEV-013
+		func(devs []*client.Device) types.Suggestion { // NET-003
+			return n.checkSubnetRoutes(ctx, devs)
+		},
+		n.checkExitNodes,     // NET-005
+		n.checkAppConnectors, // NET-007
+	}
+
+	report := &types.DeviceInventoryReport{}
+	for _, dev := range devices {
+		record := newDeviceRecord(dev)
+		record.OSVersion = osVersions[dev.DeviceID]
+		single := []*client.Device{dev}
+
+		for _, check := range checks {
+			if f := check(single); !f.Pass {
+				record.FailedChecks = append(record.FailedChecks, types.DeviceCheckFailure{
+					CheckID:  f.ID,
+					Title:    f.Title,
+					Severity: f.Severity,
+				})
+			}
+		}
+
+		// DEV-014 is evaluated directly so devices without a known OS version
+		// don't pick up the check's "data unavailable" result
+		if _, ok := eolPlatformFor(dev.OS, record.OSVersion, now); ok {
+			record.FailedChecks = append(record.FailedChecks, types.DeviceCheckFailure{
+				CheckID:  "DEV-014",
+				Title:    "Devices running end-of-life operating systems",
+				Severity: types.Medium,
+			})
+		}
+
+		// DEV-015 to DEV-017 are evaluated directly for the same reason.
+		// Missing attributes are only reported once posture is in use.
+		devicePosture := posture[dev.DeviceID]
+		if checkMissing && len(missingPostureAttributes(dev, devicePosture)) > 0 {
+			record.FailedChecks = append(record.FailedChecks, types.DeviceCheckFailure{
+				CheckID:  "DEV-015",
+				Title:    "Devices missing expected posture attributes",
+				Severity: types.Medium,
+			})
+		}
+		if len(stalePostureAttributes(devicePosture, now)) > 0 {
+			record.FailedChecks = append(record.FailedChecks, types.DeviceCheckFailure{
+				CheckID:  "DEV-016",
+				Title:    "Stale device posture data",
+				Severity: types.Medium,
+			})
+		}
+		if len(failedPostureConditions(dev, devicePosture)) > 0 {
+			record.FailedChecks = append(record.FailedChecks, types.DeviceCheckFailure{
+				CheckID:  "DEV-017",
+				Title:    "Devices failing posture conditions",
+				Severity: types.High,
+			})
+		}
+
+		// DEV-003 compares against a tailnet-wide expected version, so it
+		// can't be evaluated on a single device in isolation
+		_, outdated := minorVersionsBehind(dev, latestMajor, latestMinor)
+		bulletins := deviceBulletins(dev)
+		if outdated || len(bulletins) > 0 {
+			severity := types.Medium
+			if len(bulletins) > 0 {
+				severity = bulletinSeverity(bulletins)
+			}
+			record.FailedChecks = append(record.FailedChecks, types.DeviceCheckFailure{
+				CheckID:  "DEV-003",
+				Title:    "Outdated Tailscale clients",
+				Severity: severity,
+			})
+		}
+
+		report.Devices = append(report.Devices, record)
+	}
+
+	report.CalculateRisk()
+	return report
+}
+
+// newDeviceRecord copies inventory fields from an API device
+func newDeviceRecord(dev *client.Device) types.DeviceRecord {
+	record := types.DeviceRecord{
+		DeviceID:          dev.DeviceID,
+		Name:              dev.Name,
+		Hostname:          dev.Hostname,
+		Owner:             dev.User,
+		Tags:              dev.Tags,
+		OS:                dev.OS,
+		ClientVersion:     dev.ClientVersion,
+		UpdateAvailable:   dev.UpdateAvailable,
+		LastSeen:          dev.LastSeen,
+		KeyExpiryDisabled: dev.KeyExpiryDisabled,
+		AdvertisedRoutes:  dev.AdvertisedRoutes,
+		EnabledRoutes:     dev.EnabledRoutes,
+		External:          dev.IsExternal,
+		Authorized:        dev.Authorized,
+		FailedChecks:      []types.DeviceCheckFailure{},
+	}
+	if !dev.KeyExpiryDisabled {
+		record.KeyExpiry = dev.Expires
+	}
+	for _, route := range dev.AdvertisedRoutes {
+		if route == "0.0.0.0/0" || route == "::/0" {
+			record.ExitNode = true
+		}
+	}
+	return record
+}
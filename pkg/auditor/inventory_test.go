@@ -0,0 +1,58 @@
+package auditor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+)
+
+func TestBuildDeviceInventory(t *testing.T) {
+	recent := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	stale := time.Now().AddDate(0, 0, -120).Format(time.RFC3339)
+
+	devices := []*client.Device{
+		{DeviceID: "1", Name: "laptop", Hostname: "alice-macbook", User: "alice@example.com", OS: "macOS", ClientVersion: "1.76.0", LastSeen: recent, Authorized: true},
+		{
+			DeviceID: "2", Name: "router", Hostname: "router", User: "bob@example.com", OS: "linux", ClientVersion: "1.60.0",
+			Tags: []string{"tag:router"}, KeyExpiryDisabled: true, LastSeen: stale, Authorized: true,
+			AdvertisedRoutes: []string{"10.0.0.0/24", "0.0.0.0/0", "::/0"}, EnabledRoutes: []string{"10.0.0.0/24", "0.0.0.0/0", "::/0"},
+		},
+	}
+
+	report := buildDeviceInventory(context.Background(), devices, nil, nil, 1, 76, Clock{})
+	if len(report.Devices) != 2 {
+		t.Fatalf("got %d devices, want 2", len(report.Devices))
+	}
+
+	// Highest risk sorts first
+	router := report.Devices[0]
+	if router.DeviceID != "2" {
+		t.Fatalf("first device = %s, want router (2)", router.DeviceID)
+	}
+	if !router.ExitNode {
+		t.Errorf("router ExitNode = false, want true")
+	}
+
+	failed := make(map[string]bool)
+	for _, f := range router.FailedChecks {
+		failed[f.CheckID] = true
+	}
+	for _, id := range []string{"DEV-001", "DEV-003", "DEV-004", "NET-003", "NET-005"} {
+		if !failed[id] {
+			t.Errorf("router missing failed check %s (got %v)", id, router.FailedChecks)
+		}
+	}
+	if router.RiskScore <= report.Devices[1].RiskScore {
+		t.Errorf("router RiskScore %d should exceed laptop %d", router.RiskScore, report.Devices[1].RiskScore)
+	}
+
+	laptop := report.Devices[1]
+	if len(laptop.FailedChecks) != 0 || laptop.RiskScore != 0 {
+		t.Errorf("laptop FailedChecks = %v, RiskScore = %d, want none", laptop.FailedChecks, laptop.RiskScore)
+	}
+	if laptop.KeyExpiry != "" || laptop.Owner != "alice@example.com" {
+		t.Errorf("laptop record = %+v", laptop)
+	}
+}
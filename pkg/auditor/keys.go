@@ -0,0 +1,364 @@
+package auditor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// maxAPITokenDays is the longest lifetime an API access token should have.
+// Automation should use OAuth clients, whose tokens last an hour.
+const maxAPITokenDays = 30
+
+// keyCreators resolves the user IDs that created keys. If users couldn't be
+// read, err is set and creators are never reported as removed.
+type keyCreators struct {
+	users map[string]client.User
+	err   error
+}
+
+func newKeyCreators(users []client.User, err error) keyCreators {
+	creators := keyCreators{users: make(map[string]client.User), err: err}
+	for _, user := range users {
+		creators.users[user.ID] = user
+	}
+	return creators
+}
+
+// name returns the creator's login name, or the user ID if it's unknown
+func (k keyCreators) name(userID string) string {
+	if user, ok := k.users[userID]; ok {
+		return user.LoginName
+	}
+	return userID
+}
+
+// removed reports whether the key's creator no longer exists in the tailnet
+func (k keyCreators) removed(userID string) bool {
+	if k.err != nil || userID == "" {
+		return false
+	}
+	_, ok := k.users[userID]
+	return !ok
+}
+
+// keyActive reports whether a key can still be used
+func keyActive(key client.KeyEntry, now time.Time) bool {
+	if key.Invalid || !key.Revoked.IsZero() {
+		return false
+	}
+	return key.Expires.IsZero() || key.Expires.After(now)
+}
+
+// keyLifetimeDays returns how long a key was issued for, or -1 if it never expires
+func keyLifetimeDays(key client.KeyEntry) int {
+	if key.Expires.IsZero() {
+		return -1
+	}
+	return int(key.Expires.Sub(key.Created).Hours() / 24)
+}
+
+// hasAllScope reports whether scopes include "all", full read and write
+// access to every API resource
+func hasAllScope(scopes []string) bool {
+	for _, scope := range scopes {
+		if scope == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// keyLine describes a key for finding details
+func keyLine(key client.KeyEntry, creators keyCreators) string {
+	line := key.ID
+	if key.Description != "" {
+		line += fmt.Sprintf(" %q", key.Description)
+	}
+	if key.UserID != "" {
+		line += " created by " + creators.name(key.UserID)
+	}
+	if !key.Created.IsZero() {
+		line += " on " + key.Created.Format("2006-01-02")
+	}
+	if !key.LastUsed.IsZero() {
+		line += ", last used " + key.LastUsed.Format("2006-01-02")
+	}
+	return line
+}
+
+// apiTokenFinding is AUTH-006 before tokens are checked
+func apiTokenFinding() types.Suggestion {
+	return types.Suggestion{
+		ID:          "AUTH-006",
+		Title:       "API access tokens with long lifetimes or removed creators",
+		Severity:    types.Medium,
+		Category:    types.Authentication,
+		Description: "API access tokens act with the full permissions of the user who created them. Long-lived tokens widen the window for a leaked token, and tokens whose creator has left keep working for automation nobody owns.",
+		Remediation: fmt.Sprintf("Revoke API access tokens that are no longer needed. Issue tokens for at most %d days, and move automation to OAuth clients with narrow scopes.", maxAPITokenDays),
+		Source:      "https://tailscale.com/kb/1101/api",
+		Pass:        true,
+	}
+}
+
+func (a *AuthAuditor) checkAPITokens(entries []client.KeyEntry, creators keyCreators, now time.Time) types.Suggestion {
+	finding := apiTokenFinding()
+
+	var orphaned, longLived []string
+	for _, key := range entries {
+		if key.Type() != client.KeyTypeAPI || !keyActive(key, now) {
+			continue
+		}
+		if creators.removed(key.UserID) {
+			orphaned = append(orphaned, "  - "+keyLine(key, creators))
+		}
+		if days := keyLifetimeDays(key); days < 0 || days > maxAPITokenDays {
+			lifetime := "never expires"
+			if days >= 0 {
+				lifetime = fmt.Sprintf("%d-day lifetime, expires %s", days, key.Expires.Format("2006-01-02"))
+			}
+			longLived = append(longLived, fmt.Sprintf("  - %s (%s)", keyLine(key, creators), lifetime))
+		}
+	}
+
+	var details []string
+	if creators.err != nil {
+		details = append(details, fmt.Sprintf("Creators not checked: cannot read users: %v", creators.err))
+	}
+	if len(orphaned) == 0 && len(longLived) == 0 {
+		if len(details) > 0 {
+			finding.Details = details
+		}
+		return finding
+	}
+
+	finding.Pass = false
+	var problems []string
+	if len(orphaned) > 0 {
+		finding.Severity = types.High
+		problems = append(problems, fmt.Sprintf("%d API access token(s) whose creator no longer exists", len(orphaned)))
+		details = append(details, "Creator removed from the tailnet:")
+		details = append(details, orphaned...)
+	}
+	if len(longLived) > 0 {
+		problems = append(problems, fmt.Sprintf("%d API access token(s) issued for more than %d days", len(longLived), maxAPITokenDays))
+		if len(details) > 0 {
+			details = append(details, "")
+		}
+		details = append(details, "Long-lived tokens:")
+		details = append(details, longLived...)
+	}
+	finding.Details = details
+	finding.Description = fmt.Sprintf("Found %s.", strings.Join(problems, " and "))
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Revoke unneeded API access tokens and reissue with shorter expiry",
+		AdminURL:    "https://login.tailscale.com/admin/settings/keys",
+		DocURL:      "https://tailscale.com/kb/1101/api",
+	}
+
+	return finding
+}
+
+func (l *LoggingAuditor) checkOAuthClients(entries []client.KeyEntry, err error, creators keyCreators, now time.Time) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOG-006",
+		Title:       "OAuth clients with full write scope or removed creators",
+		Severity:    types.Medium,
+		Category:    types.LoggingAdmin,
+		Description: "OAuth clients don't expire and keep working after the user who created them loses access. A client with the all scope can change the policy, devices, keys and settings.",
+		Remediation: "Revoke OAuth clients whose creator has left, after moving their automation to a client owned by a current admin. Replace all-scope clients with clients limited to the scopes they use.",
+		Source:      "https://tailscale.com/kb/1215/oauth-clients",
+		Pass:        true,
+	}
+
+	if err != nil {
+		finding.Severity = types.Informational
+		finding.Pass = false
+		finding.Description = fmt.Sprintf("Cannot list OAuth clients: %v. OAuth clients continue functioning after creating user loses access.", err)
+		finding.Details = "MANUAL CHECK REQUIRED: Review OAuth clients for scopes and whether their creators still need access."
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Review OAuth clients in admin console",
+			AdminURL:    "https://login.tailscale.com/admin/settings/oauth",
+			DocURL:      "https://tailscale.com/kb/1215/oauth-clients",
+		}
+		return finding
+	}
+
+	var broad, orphaned []string
+	for _, key := range entries {
+		if key.Type() != client.KeyTypeOAuthClient || !keyActive(key, now) {
+			continue
+		}
+		if hasAllScope(key.Scopes) {
+			broad = append(broad, fmt.Sprintf("  - %s: %s", keyLine(key, creators), strings.Join(key.Scopes, ", ")))
+		}
+		if creators.removed(key.UserID) {
+			orphaned = append(orphaned, "  - "+keyLine(key, creators))
+		}
+	}
+
+	var details []string
+	if creators.err != nil {
+		details = append(details, fmt.Sprintf("Creators not checked: cannot read users: %v", creators.err))
+	}
+	if len(broad) == 0 && len(orphaned) == 0 {
+		if len(details) > 0 {
+			finding.Details = details
+		}
+		return finding
+	}
+
+	finding.Pass = false
+	var problems []string
+	if len(broad) > 0 {
+		finding.Severity = types.High
+		problems = append(problems, fmt.Sprintf("%d OAuth client(s) with the all scope", len(broad)))
+		details = append(details, "Full read and write access:")
+		details = append(details, broad...)
+	}
+	if len(orphaned) > 0 {
+		problems = append(problems, fmt.Sprintf("%d OAuth client(s) whose creator no longer exists", len(orphaned)))
+		if len(details) > 0 {
+			details = append(details, "")
+		}
+		details = append(details, "Creator removed from the tailnet:")
+		details = append(details, orphaned...)
+	}
+	finding.Details = details
+	finding.Description = fmt.Sprintf("Found %s.", strings.Join(problems, " and "))
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Narrow OAuth client scopes and revoke orphaned clients in admin console",
+		AdminURL:    "https://login.tailscale.com/admin/settings/oauth",
+		DocURL:      "https://tailscale.com/kb/1215/oauth-clients",
+	}
+
+	return finding
+}
+
+// KeyInventoryCollector builds a per-key view of auth keys, API access
+// tokens and OAuth clients
+type KeyInventoryCollector struct {
+	client *client.Client
+	clock  Clock
+}
+
+// NewKeyInventoryCollector creates a new key inventory collector
+func NewKeyInventoryCollector(c *client.Client, clock Clock) *KeyInventoryCollector {
+	return &KeyInventoryCollector{client: c, clock: clock}
+}
+
+// Collect fetches every key and evaluates each one against the key checks
+func (c *KeyInventoryCollector) Collect(ctx context.Context) (*types.KeyInventoryReport, error) {
+	entries, skipped, err := c.client.GetAllKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keys: %w", err)
+	}
+
+	// Users resolve creators; without them creators are shown as user IDs
+	users, usersErr := c.client.GetUsers(ctx)
+
+	report := buildKeyInventory(entries, newKeyCreators(users, usersErr), c.clock.Now())
+	report.Tailnet = c.client.Tailnet()
+	report.GeneratedAt = time.Now()
+	report.AsOf = c.clock.asOfPtr()
+	report.SkippedKeys = skipped
+	if usersErr != nil {
+		report.UsersError = usersErr.Error()
+	}
+	return report, nil
+}
+
+// buildKeyInventory runs each key check against one key at a time, so a
+// key's failed checks are exactly those the full audit would attribute to it
+func buildKeyInventory(entries []client.KeyEntry, creators keyCreators, now time.Time) *types.KeyInventoryReport {
+	a := &AuthAuditor{}
+	l := &LoggingAuditor{}
+
+	authChecks := []func([]keyInfo) types.Suggestion{
+		a.checkReusableKeys,      // AUTH-001
+		a.checkLongExpiryKeys,    // AUTH-002
+		a.checkPreauthorizedKeys, // AUTH-003
+	}
+	entryChecks := []func([]client.KeyEntry) types.Suggestion{
+		func(keys []client.KeyEntry) types.Suggestion { // AUTH-006
+			return a.checkAPITokens(keys, creators, now)
+		},
+		func(keys []client.KeyEntry) types.Suggestion { // LOG-006
+			return l.checkOAuthClients(keys, nil, creators, now)
+		},
+	}
+
+	report := &types.KeyInventoryReport{}
+	for _, key := range entries {
+		record := newKeyRecord(key, creators)
+		var failed []types.Suggestion
+		for _, check := range entryChecks {
+			failed = append(failed, check([]client.KeyEntry{key}))
+		}
+		for _, info := range authKeyInfo([]client.KeyEntry{key}, now) {
+			for _, check := range authChecks {
+				failed = append(failed, check([]keyInfo{info}))
+			}
+		}
+
+		for _, f := range failed {
+			if !f.Pass {
+				record.FailedChecks = append(record.FailedChecks, types.DeviceCheckFailure{
+					CheckID:  f.ID,
+					Title:    f.Title,
+					Severity: f.Severity,
+				})
+			}
+		}
+		report.Keys = append(report.Keys, record)
+	}
+
+	report.CalculateRisk()
+	return report
+}
+
+// newKeyRecord copies inventory fields from an API key
+func newKeyRecord(key client.KeyEntry, creators keyCreators) types.KeyRecord {
+	record := types.KeyRecord{
+		ID:             key.ID,
+		Type:           key.Type(),
+		Description:    key.Description,
+		Creator:        creators.name(key.UserID),
+		CreatorRemoved: creators.removed(key.UserID),
+		Scopes:         key.Scopes,
+		Tags:           key.Tags,
+		FailedChecks:   []types.DeviceCheckFailure{},
+	}
+	if len(record.Tags) == 0 {
+		record.Tags = key.Capabilities.Devices.Create.Tags
+	}
+
+	create := key.Capabilities.Devices.Create
+	if create.Reusable {
+		record.Capabilities = append(record.Capabilities, "reusable")
+	}
+	if create.Ephemeral {
+		record.Capabilities = append(record.Capabilities, "ephemeral")
+	}
+	if create.Preauthorized {
+		record.Capabilities = append(record.Capabilities, "preauthorized")
+	}
+
+	if !key.Created.IsZero() {
+		record.Created = key.Created.Format(time.RFC3339)
+	}
+	if !key.Expires.IsZero() {
+		record.Expires = key.Expires.Format(time.RFC3339)
+	}
+	if !key.LastUsed.IsZero() {
+		record.LastUsed = key.LastUsed.Format(time.RFC3339)
+	}
+	return record
+}
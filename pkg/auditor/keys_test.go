@@ -0,0 +1,129 @@
+package auditor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func keysFixture(now time.Time) ([]client.KeyEntry, []client.User) {
+	users := []client.User{
+		{ID: "u1", LoginName: "alice@example.com", Role: "admin"},
+		{ID: "u2", LoginName: "bob@example.com", Role: "member"},
+	}
+	var reusable client.KeyCapabilities
+	reusable.Devices.Create.Reusable = true
+	reusable.Devices.Create.Tags = []string{"tag:ci"}
+
+	keys := []client.KeyEntry{
+		{ID: "kShortAPI", KeyType: "api", UserID: "u1", Created: now.AddDate(0, 0, -5), Expires: now.AddDate(0, 0, 2)},
+		{ID: "kLongAPI", KeyType: "api", Description: "terraform", UserID: "u1", Created: now.AddDate(0, 0, -10), Expires: now.AddDate(0, 0, 80)},
+		{ID: "kOrphanAPI", KeyType: "api", UserID: "u9", Created: now.AddDate(0, 0, -3), Expires: now.AddDate(0, 0, 4)},
+		{ID: "kRevokedAPI", KeyType: "api", UserID: "u9", Created: now.AddDate(0, 0, -100), Expires: now.AddDate(0, 0, 10), Revoked: now.AddDate(0, 0, -1)},
+		{ID: "kAllClient", KeyType: "client", UserID: "u2", Created: now.AddDate(-1, 0, 0), Scopes: []string{"all"}},
+		{ID: "kOrphanClient", KeyType: "client", UserID: "u8", Created: now.AddDate(-1, 0, 0), Scopes: []string{"devices:core:read"}},
+		{ID: "kNarrowClient", KeyType: "client", UserID: "u2", Created: now.AddDate(0, -1, 0), Scopes: []string{"all:read"}},
+		{ID: "kAuth", KeyType: "auth", UserID: "u1", Created: now.AddDate(0, 0, -1), Expires: now.AddDate(0, 0, 30), Capabilities: reusable},
+	}
+	return keys, users
+}
+
+func TestCheckAPITokens(t *testing.T) {
+	a := &AuthAuditor{}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	keys, users := keysFixture(now)
+
+	result := a.checkAPITokens(keys, newKeyCreators(users, nil), now)
+	joined := strings.Join(result.Details.([]string), "\n")
+	if result.Pass || result.Severity != types.High {
+		t.Errorf("AUTH-006 Pass = %v, Severity = %v", result.Pass, result.Severity)
+	}
+	if !strings.Contains(joined, "kOrphanAPI created by u9") || !strings.Contains(joined, `kLongAPI "terraform" created by alice@example.com`) {
+		t.Errorf("AUTH-006 Details =\n%s", joined)
+	}
+	if strings.Contains(joined, "kShortAPI") || strings.Contains(joined, "kRevokedAPI") || strings.Contains(joined, "kAllClient") {
+		t.Errorf("AUTH-006 should only list active API tokens with problems:\n%s", joined)
+	}
+
+	// Without users, creators can't be checked but lifetimes still are
+	result = a.checkAPITokens(keys, newKeyCreators(nil, errors.New("403 Forbidden")), now)
+	joined = strings.Join(result.Details.([]string), "\n")
+	if result.Pass || result.Severity != types.Medium || strings.Contains(joined, "kOrphanAPI") || !strings.Contains(joined, "Creators not checked") {
+		t.Errorf("AUTH-006 without users = %v\n%s", result.Severity, joined)
+	}
+}
+
+func TestCheckOAuthClients(t *testing.T) {
+	l := &LoggingAuditor{}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	keys, users := keysFixture(now)
+
+	result := l.checkOAuthClients(keys, nil, newKeyCreators(users, nil), now)
+	joined := strings.Join(result.Details.([]string), "\n")
+	if result.Pass || result.Severity != types.High {
+		t.Errorf("LOG-006 Pass = %v, Severity = %v", result.Pass, result.Severity)
+	}
+	if !strings.Contains(joined, "kAllClient created by bob@example.com") || !strings.Contains(joined, "kOrphanClient created by u8") || strings.Contains(joined, "kNarrowClient") {
+		t.Errorf("LOG-006 Details =\n%s", joined)
+	}
+
+	result = l.checkOAuthClients(nil, errors.New("403 Forbidden"), keyCreators{}, now)
+	if result.Pass || result.Severity != types.Informational {
+		t.Errorf("LOG-006 should fall back to manual review on API error, got %v", result.Severity)
+	}
+}
+
+func TestAuthKeyInfoSkipsOtherKeyTypes(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	keys, _ := keysFixture(now)
+
+	infos := authKeyInfo(keys, now)
+	if len(infos) != 1 || infos[0].ID != "kAuth" || !infos[0].Reusable || infos[0].DaysToExpiry != 30 {
+		t.Errorf("authKeyInfo() = %+v", infos)
+	}
+}
+
+func TestBuildKeyInventory(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	keys, users := keysFixture(now)
+
+	report := buildKeyInventory(keys, newKeyCreators(users, nil), now)
+	if len(report.Keys) != len(keys) {
+		t.Fatalf("got %d records, want %d", len(report.Keys), len(keys))
+	}
+
+	records := make(map[string]types.KeyRecord)
+	for _, r := range report.Keys {
+		records[r.ID] = r
+	}
+	failed := func(id string) []string {
+		var ids []string
+		for _, f := range records[id].FailedChecks {
+			ids = append(ids, f.CheckID)
+		}
+		return ids
+	}
+
+	if got := failed("kAllClient"); len(got) != 1 || got[0] != "LOG-006" {
+		t.Errorf("kAllClient failed checks = %v", got)
+	}
+	if got := failed("kOrphanAPI"); len(got) != 1 || got[0] != "AUTH-006" || !records["kOrphanAPI"].CreatorRemoved {
+		t.Errorf("kOrphanAPI failed checks = %v, record = %+v", got, records["kOrphanAPI"])
+	}
+	if got := failed("kAuth"); len(got) != 1 || got[0] != "AUTH-001" {
+		t.Errorf("kAuth failed checks = %v", got)
+	}
+	if got := failed("kShortAPI"); len(got) != 0 {
+		t.Errorf("kShortAPI failed checks = %v", got)
+	}
+	if r := records["kAuth"]; r.Creator != "alice@example.com" || strings.Join(r.Capabilities, ";") != "reusable" || strings.Join(r.Tags, ";") != "tag:ci" {
+		t.Errorf("kAuth record = %+v", r)
+	}
+	if report.Keys[0].RiskScore < report.Keys[len(report.Keys)-1].RiskScore {
+		t.Errorf("keys not sorted by risk")
+	}
+}
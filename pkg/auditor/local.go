@@ -0,0 +1,373 @@
+package auditor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tailscale.com/client/tailscale"
+	"tailscale.com/ipn"
+	"tailscale.com/ipn/ipnstate"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// localAPI is the subset of the tailscaled LocalAPI used by LocalAuditor
+type localAPI interface {
+	StatusWithoutPeers(ctx context.Context) (*ipnstate.Status, error)
+	GetPrefs(ctx context.Context) (*ipn.Prefs, error)
+	GetServeConfig(ctx context.Context) (*ipn.ServeConfig, error)
+}
+
+// LocalAuditor audits the preferences and Serve configuration of the node
+// running tailsnitch, read from the local tailscaled. It complements the
+// tailnet-wide checks, which only see control plane state.
+type LocalAuditor struct {
+	lc localAPI
+}
+
+// NewLocalAuditor creates a local node auditor. An empty socket path uses
+// the platform's default tailscaled socket.
+func NewLocalAuditor(socket string) *LocalAuditor {
+	return &LocalAuditor{lc: &tailscale.LocalClient{Socket: socket, UseSocketOnly: socket != ""}}
+}
+
+// Run executes the local node checks and returns a report
+func (l *LocalAuditor) Run(ctx context.Context) (*types.AuditReport, error) {
+	st, err := l.lc.StatusWithoutPeers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach tailscaled: %w", err)
+	}
+
+	prefs, err := l.lc.GetPrefs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prefs: %w", err)
+	}
+
+	// Older daemons and nodes without HTTPS support may not return a config;
+	// LOCAL-007 reports that
+	serveConfig, serveErr := l.lc.GetServeConfig(ctx)
+
+	report := &types.AuditReport{
+		Timestamp: time.Now(),
+		Tailnet:   "(not logged in)",
+	}
+	if st.CurrentTailnet != nil && st.CurrentTailnet.Name != "" {
+		report.Tailnet = st.CurrentTailnet.Name
+	}
+
+	self := selfDevice(st)
+
+	// LOCAL-001: Subnet routes accepted on a server
+	report.Suggestions = append(report.Suggestions, l.checkAcceptRoutes(prefs, self))
+
+	// LOCAL-002: Shields up disabled on a user device
+	report.Suggestions = append(report.Suggestions, l.checkShieldsUp(prefs, self))
+
+	// LOCAL-003: Tailscale SSH server enabled
+	report.Suggestions = append(report.Suggestions, l.checkSSHServer(prefs))
+
+	// LOCAL-004: Automatic updates disabled
+	report.Suggestions = append(report.Suggestions, l.checkAutoUpdate(prefs))
+
+	// LOCAL-005: Exit node in use on a server
+	report.Suggestions = append(report.Suggestions, l.checkExitNode(prefs, self))
+
+	// LOCAL-006: Non-root operator can control tailscaled
+	report.Suggestions = append(report.Suggestions, l.checkOperator(prefs))
+
+	// LOCAL-007: Services exposed with Serve or Funnel
+	report.Suggestions = append(report.Suggestions, l.checkServeConfig(serveConfig, serveErr))
+
+	report.CalculateSummary()
+	return report, nil
+}
+
+// selfDevice describes the local node in the form the device heuristics
+// expect, so isDevDevice can tell servers from user devices
+func selfDevice(st *ipnstate.Status) *client.Device {
+	dev := &client.Device{}
+	if st.Self == nil {
+		return dev
+	}
+	dev.Name = strings.TrimSuffix(st.Self.DNSName, ".")
+	dev.Hostname = st.Self.HostName
+	dev.OS = st.Self.OS
+	if st.Self.Tags != nil {
+		dev.Tags = st.Self.Tags.AsSlice()
+	}
+	return dev
+}
+
+// nodeKind names the kind of node for finding descriptions
+func nodeKind(self *client.Device) string {
+	if isDevDevice(self) {
+		return "user device"
+	}
+	return "server"
+}
+
+func (l *LocalAuditor) checkAcceptRoutes(prefs *ipn.Prefs, self *client.Device) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOCAL-001",
+		Title:       "Subnet routes accepted on a server",
+		Severity:    types.Medium,
+		Category:    types.LocalNode,
+		Description: "Servers that accept subnet routes send traffic for those ranges through other nodes' subnet routers.",
+		Remediation: "Unless the server needs to reach a subnet route, run: tailscale set --accept-routes=false",
+		Source:      "https://tailscale.com/kb/1019/subnets",
+		Pass:        true,
+	}
+
+	if !prefs.RouteAll {
+		finding.Description = "This node does not accept subnet routes."
+		return finding
+	}
+
+	if isDevDevice(self) {
+		finding.Description = "This user device accepts subnet routes, as expected for reaching internal networks."
+		return finding
+	}
+
+	finding.Pass = false
+	finding.Description = fmt.Sprintf("This server (%s) accepts subnet routes advertised by other nodes. A compromised or misconfigured subnet router can capture its traffic to those ranges.", self.Hostname)
+	finding.Details = []string{
+		"Prefs: RouteAll (--accept-routes) is on",
+		fmt.Sprintf("Tags: %s", strings.Join(self.Tags, ", ")),
+	}
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeExternal,
+		Description: "Run 'tailscale set --accept-routes=false' on this node",
+		DocURL:      "https://tailscale.com/kb/1019/subnets",
+	}
+	return finding
+}
+
+func (l *LocalAuditor) checkShieldsUp(prefs *ipn.Prefs, self *client.Device) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOCAL-002",
+		Title:       "Shields up disabled on a user device",
+		Severity:    types.Low,
+		Category:    types.LocalNode,
+		Description: "Shields up blocks all incoming connections from the tailnet. Laptops and phones rarely need to accept them.",
+		Remediation: "Run: tailscale set --shields-up",
+		Source:      "https://tailscale.com/kb/1072/client-preferences",
+		Pass:        true,
+	}
+
+	if prefs.ShieldsUp {
+		finding.Description = fmt.Sprintf("Shields up is on. This %s accepts no incoming connections from the tailnet.", nodeKind(self))
+		return finding
+	}
+
+	if !isDevDevice(self) {
+		finding.Description = "Shields up is off, as expected for a server that accepts connections."
+		return finding
+	}
+
+	finding.Pass = false
+	finding.Description = fmt.Sprintf("This user device (%s) accepts incoming connections from any node the ACL policy allows.", self.Hostname)
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeExternal,
+		Description: "Run 'tailscale set --shields-up' on this device",
+		DocURL:      "https://tailscale.com/kb/1072/client-preferences",
+	}
+	return finding
+}
+
+func (l *LocalAuditor) checkSSHServer(prefs *ipn.Prefs) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOCAL-003",
+		Title:       "Tailscale SSH server enabled",
+		Severity:    types.Informational,
+		Category:    types.LocalNode,
+		Description: "Nodes running the Tailscale SSH server accept SSH sessions authorized by the tailnet policy's ssh rules.",
+		Remediation: "If this node should not accept Tailscale SSH, run: tailscale set --ssh=false",
+		Source:      "https://tailscale.com/kb/1193/tailscale-ssh",
+		Pass:        true,
+	}
+
+	if !prefs.RunSSH {
+		finding.Description = "This node does not run the Tailscale SSH server."
+		return finding
+	}
+
+	finding.Pass = false
+	finding.Description = "This node runs the Tailscale SSH server. Confirm it is meant to accept SSH and review the ssh rules that reach it (SSH-001 to SSH-010)."
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeExternal,
+		Description: "Run 'tailscale set --ssh=false' if this node should not accept Tailscale SSH",
+		DocURL:      "https://tailscale.com/kb/1193/tailscale-ssh",
+	}
+	return finding
+}
+
+func (l *LocalAuditor) checkAutoUpdate(prefs *ipn.Prefs) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOCAL-004",
+		Title:       "Automatic updates disabled",
+		Severity:    types.Medium,
+		Category:    types.LocalNode,
+		Description: "Nodes without automatic updates keep running vulnerable clients until someone updates them.",
+		Remediation: "Run: tailscale set --auto-update, or keep the tailscale package current through configuration management.",
+		Source:      "https://tailscale.com/kb/1067/update",
+		Pass:        true,
+	}
+
+	apply, ok := prefs.AutoUpdate.Apply.Get()
+	switch {
+	case ok && apply:
+		finding.Description = "Automatic updates are enabled on this node."
+		return finding
+	case !ok:
+		finding.Pass = false
+		finding.Severity = types.Informational
+		finding.Description = "Automatic updates are not configured on this node, so the tailnet default applies."
+		finding.Details = "MANUAL CHECK REQUIRED: Verify auto-updates are enabled in Device management settings, or set them on this node."
+	default:
+		finding.Pass = false
+		finding.Description = "Automatic updates are turned off on this node. Security fixes wait for a manual update."
+	}
+
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeExternal,
+		Description: "Run 'tailscale set --auto-update' on this node",
+		AdminURL:    "https://login.tailscale.com/admin/settings/device-management",
+		DocURL:      "https://tailscale.com/kb/1067/update",
+	}
+	return finding
+}
+
+func (l *LocalAuditor) checkExitNode(prefs *ipn.Prefs, self *client.Device) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOCAL-005",
+		Title:       "Exit node in use on a server",
+		Severity:    types.Low,
+		Category:    types.LocalNode,
+		Description: "Nodes using an exit node send all internet traffic through it, so the exit node can observe and alter that traffic.",
+		Remediation: "Unless the server must egress through the exit node, run: tailscale set --exit-node=",
+		Source:      "https://tailscale.com/kb/1103/exit-nodes",
+		Pass:        true,
+	}
+
+	exitNode := string(prefs.ExitNodeID)
+	if exitNode == "" && prefs.ExitNodeIP.IsValid() {
+		exitNode = prefs.ExitNodeIP.String()
+	}
+	if exitNode == "" {
+		finding.Description = "This node does not use an exit node."
+		return finding
+	}
+
+	details := []string{fmt.Sprintf("Exit node: %s", exitNode)}
+	if prefs.ExitNodeAllowLANAccess {
+		details = append(details, "Local network access is allowed while using the exit node")
+	}
+	finding.Details = details
+
+	if isDevDevice(self) {
+		finding.Description = fmt.Sprintf("This user device routes internet traffic through exit node %s.", exitNode)
+		return finding
+	}
+
+	finding.Pass = false
+	finding.Description = fmt.Sprintf("All internet traffic from this server (%s) is routed through exit node %s.", self.Hostname, exitNode)
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeExternal,
+		Description: "Run 'tailscale set --exit-node=' on this node",
+		DocURL:      "https://tailscale.com/kb/1103/exit-nodes",
+	}
+	return finding
+}
+
+func (l *LocalAuditor) checkOperator(prefs *ipn.Prefs) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOCAL-006",
+		Title:       "Non-root operator can control tailscaled",
+		Severity:    types.Low,
+		Category:    types.LocalNode,
+		Description: "An operator user can change Tailscale settings without root, including turning on Serve, Funnel and SSH.",
+		Remediation: "Remove the operator unless a local user needs it. Run: tailscale set --operator=",
+		Source:      "https://tailscale.com/kb/1080/cli",
+		Pass:        true,
+	}
+
+	if prefs.OperatorUser == "" {
+		finding.Description = "No operator is set. Only root can change this node's Tailscale settings."
+		return finding
+	}
+
+	finding.Pass = false
+	finding.Description = fmt.Sprintf("Local user %q can change this node's Tailscale settings without root, including turning on Serve, Funnel and SSH.", prefs.OperatorUser)
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeExternal,
+		Description: "Run 'tailscale set --operator=' on this node",
+		DocURL:      "https://tailscale.com/kb/1080/cli",
+	}
+	return finding
+}
+
+func (l *LocalAuditor) checkServeConfig(sc *ipn.ServeConfig, serveErr error) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOCAL-007",
+		Title:       "Services exposed with Serve or Funnel",
+		Severity:    types.High,
+		Category:    types.LocalNode,
+		Description: "Serve shares local services with the tailnet; Funnel publishes them to the public internet.",
+		Remediation: "Review each endpoint. Turn off unneeded ones with: tailscale serve reset or tailscale funnel <port> off",
+		Source:      "https://tailscale.com/kb/1223/funnel",
+		Pass:        true,
+	}
+
+	if serveErr != nil {
+		finding.Pass = false
+		finding.Severity = types.Informational
+		finding.Description = fmt.Sprintf("Could not read the Serve configuration: %v", serveErr)
+		finding.Details = "MANUAL CHECK REQUIRED: Run 'tailscale serve status' and 'tailscale funnel status' on this node."
+		return finding
+	}
+
+	// Described from the same endpoints as the Serve inventory, so the two
+	// agree on what is exposed
+	var endpoints []string
+	funnels := 0
+	for _, e := range inventoryEndpoints("", sc) {
+		endpoints = append(endpoints, e.String())
+		if e.Funnel {
+			funnels++
+		}
+	}
+	if len(endpoints) == 0 {
+		finding.Description = "This node exposes no services with Serve or Funnel."
+		return finding
+	}
+
+	finding.Pass = false
+	finding.Details = endpoints
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeExternal,
+		Description: "Review 'tailscale serve status' and turn off unneeded endpoints",
+		DocURL:      "https://tailscale.com/kb/1312/serve",
+	}
+
+	if funnels == 0 {
+		finding.Severity = types.Informational
+		finding.Description = fmt.Sprintf("Found %d endpoint(s) shared with the tailnet via Serve. None are public via Funnel.", len(endpoints))
+		return finding
+	}
+
+	finding.Description = fmt.Sprintf("Found %d endpoint(s) exposed by Serve, %d of them public on the internet via Funnel.", len(endpoints), funnels)
+	return finding
+}
+
+// portFunneled reports whether any host is funneled on the given port
+func portFunneled(sc *ipn.ServeConfig, port uint16) bool {
+	for hp, allowed := range sc.AllowFunnel {
+		if p, err := hp.Port(); err == nil && p == port && allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package auditor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"tailscale.com/ipn"
+	"tailscale.com/ipn/ipnstate"
+	"tailscale.com/types/opt"
+	"tailscale.com/types/views"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+type fakeLocalAPI struct {
+	status   *ipnstate.Status
+	prefs    *ipn.Prefs
+	serve    *ipn.ServeConfig
+	serveErr error
+}
+
+func (f *fakeLocalAPI) StatusWithoutPeers(ctx context.Context) (*ipnstate.Status, error) {
+	return f.status, nil
+}
+
+func (f *fakeLocalAPI) GetPrefs(ctx context.Context) (*ipn.Prefs, error) {
+	return f.prefs, nil
+}
+
+func (f *fakeLocalAPI) GetServeConfig(ctx context.Context) (*ipn.ServeConfig, error) {
+	return f.serve, f.serveErr
+}
+
+func serverStatus() *ipnstate.Status {
+	tags := views.SliceOf([]string{"tag:server"})
+	return &ipnstate.Status{
+		Self: &ipnstate.PeerStatus{
+			DNSName:  "db-1.example.ts.net.",
+			HostName: "db-1",
+			OS:       "linux",
+			Tags:     &tags,
+		},
+		CurrentTailnet: &ipnstate.TailnetStatus{Name: "example.com"},
+	}
+}
+
+func laptopStatus() *ipnstate.Status {
+	return &ipnstate.Status{
+		Self: &ipnstate.PeerStatus{DNSName: "alice-mbp.example.ts.net.", HostName: "alice-mbp", OS: "macOS"},
+	}
+}
+
+func TestLocalAuditorRun(t *testing.T) {
+	prefs := &ipn.Prefs{
+		RouteAll:     true,
+		RunSSH:       true,
+		OperatorUser: "deploy",
+		AutoUpdate:   ipn.AutoUpdatePrefs{Check: true, Apply: opt.NewBool(false)},
+		ExitNodeID:   "nExit123",
+	}
+	l := &LocalAuditor{lc: &fakeLocalAPI{status: serverStatus(), prefs: prefs, serve: &ipn.ServeConfig{}}}
+
+	report, err := l.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Tailnet != "example.com" {
+		t.Errorf("Tailnet = %q, want example.com", report.Tailnet)
+	}
+
+	want := map[string]bool{
+		"LOCAL-001": false, // accept-routes on a tagged server
+		"LOCAL-002": true,  // shields up not expected on a server
+		"LOCAL-003": false, // SSH server enabled
+		"LOCAL-004": false, // auto-update explicitly off
+		"LOCAL-005": false, // exit node on a server
+		"LOCAL-006": false, // operator set
+		"LOCAL-007": true,  // nothing served
+	}
+	if len(report.Suggestions) != len(want) {
+		t.Fatalf("got %d findings, want %d", len(report.Suggestions), len(want))
+	}
+	for _, s := range report.Suggestions {
+		if s.Category != types.LocalNode {
+			t.Errorf("%s Category = %q, want %q", s.ID, s.Category, types.LocalNode)
+		}
+		if s.Pass != want[s.ID] {
+			t.Errorf("%s Pass = %v, want %v (%s)", s.ID, s.Pass, want[s.ID], s.Description)
+		}
+	}
+}
+
+func TestLocalAuditorUserDevice(t *testing.T) {
+	l := &LocalAuditor{}
+	self := selfDevice(laptopStatus())
+	prefs := &ipn.Prefs{RouteAll: true, ExitNodeID: "nExit123"}
+
+	if result := l.checkAcceptRoutes(prefs, self); !result.Pass {
+		t.Error("LOCAL-001 should pass for a user device accepting routes")
+	}
+	if result := l.checkShieldsUp(prefs, self); result.Pass {
+		t.Error("LOCAL-002 should fail for a user device without shields up")
+	}
+	if result := l.checkExitNode(prefs, self); !result.Pass {
+		t.Error("LOCAL-005 should pass for a user device using an exit node")
+	}
+}
+
+func TestCheckAutoUpdateUnset(t *testing.T) {
+	l := &LocalAuditor{}
+	result := l.checkAutoUpdate(&ipn.Prefs{})
+	if result.Pass || result.Severity != types.Informational {
+		t.Errorf("unset auto-update: Pass = %v, Severity = %v, want informational failure", result.Pass, result.Severity)
+	}
+
+	result = l.checkAutoUpdate(&ipn.Prefs{AutoUpdate: ipn.AutoUpdatePrefs{Check: true, Apply: opt.NewBool(true)}})
+	if !result.Pass {
+		t.Error("enabled auto-update should pass")
+	}
+}
+
+func TestCheckServeConfig(t *testing.T) {
+	l := &LocalAuditor{}
+
+	sc := &ipn.ServeConfig{
+		TCP: map[uint16]*ipn.TCPPortHandler{
+			443:  {HTTPS: true},
+			5432: {TCPForward: "127.0.0.1:5432"},
+		},
+		Web: map[ipn.HostPort]*ipn.WebServerConfig{
+			"db-1.example.ts.net:443": {Handlers: map[string]*ipn.HTTPHandler{
+				"/":      {Proxy: "http://127.0.0.1:3000"},
+				"/files": {Path: "/srv/files"},
+			}},
+		},
+		AllowFunnel: map[ipn.HostPort]bool{"db-1.example.ts.net:443": true},
+		Foreground: map[string]*ipn.ServeConfig{
+			"session1": {TCP: map[uint16]*ipn.TCPPortHandler{8443: {TCPForward: "127.0.0.1:8443"}}},
+		},
+	}
+
+	result := l.checkServeConfig(sc, nil)
+	if result.Pass || result.Severity != types.High {
+		t.Fatalf("Funnel endpoints: Pass = %v, Severity = %v, want HIGH failure", result.Pass, result.Severity)
+	}
+	details, _ := result.Details.([]string)
+	want := []string{
+		"tcp:5432 -> 127.0.0.1:5432 [admin: PostgreSQL]",
+		"https://db-1.example.ts.net:443/ -> proxy http://127.0.0.1:3000 [Funnel: public internet]",
+		"https://db-1.example.ts.net:443/files -> path /srv/files [Funnel: public internet]",
+		"tcp:8443 -> 127.0.0.1:8443 (foreground session)",
+	}
+	if strings.Join(details, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Details =\n%s\nwant\n%s", strings.Join(details, "\n"), strings.Join(want, "\n"))
+	}
+
+	delete(sc.AllowFunnel, "db-1.example.ts.net:443")
+	if result := l.checkServeConfig(sc, nil); result.Pass || result.Severity != types.Informational {
+		t.Errorf("Serve only: Pass = %v, Severity = %v, want informational failure", result.Pass, result.Severity)
+	}
+
+	if result := l.checkServeConfig(nil, nil); !result.Pass {
+		t.Error("empty Serve config should pass")
+	}
+
+	if result := l.checkServeConfig(nil, errors.New("not supported")); result.Pass || result.Severity != types.Informational {
+		t.Error("Serve config error should be an informational failure")
+	}
+}
@@ -10,22 +10,35 @@ import (
 // LoggingAuditor checks for logging and administrative issues
 type LoggingAuditor struct {
 	client *client.Client
+	clock  Clock
 }
 
 // NewLoggingAuditor creates a new logging auditor
-func NewLoggingAuditor(c *client.Client) *LoggingAuditor {
-	return &LoggingAuditor{client: c}
+func NewLoggingAuditor(c *client.Client, clock Clock) *LoggingAuditor {
+	return &LoggingAuditor{client: c, clock: clock}
 }
 
 // Audit performs logging and admin security checks
-func (l *LoggingAuditor) Audit(ctx context.Context) ([]types.Suggestion, error) {
+func (l *LoggingAuditor) Audit(ctx context.Context, data *TailnetData) ([]types.Suggestion, error) {
 	var findings []types.Suggestion
 
-	// LOG-001: Network flow logs (manual check)
-	findings = append(findings, l.checkNetworkFlowLogs())
+	// Webhooks for LOG-005 and LOG-012; on error both fall back to manual review
+	webhooks, webhookErr := l.client.GetWebhooks(ctx)
 
-	// LOG-002: Log streaming (manual check)
-	findings = append(findings, l.checkLogStreaming())
+	// Tailnet settings and log streaming destinations for LOG-001 and
+	// LOG-002; on error each falls back to manual review
+	settings, settingsErr := data.Settings, data.SettingsErr
+	configStream, streamErr := l.client.GetLogStream(ctx, client.LogTypeConfiguration)
+	var networkStream *client.LogStream
+	if streamErr == nil {
+		networkStream, streamErr = l.client.GetLogStream(ctx, client.LogTypeNetwork)
+	}
+
+	// LOG-001: Network flow logging
+	findings = append(findings, l.checkNetworkFlowLogs(settings, settingsErr))
+
+	// LOG-002: Log streaming destinations
+	findings = append(findings, l.checkLogStreaming(configStream, networkStream, streamErr, settings))
 
 	// LOG-003: Audit log retention
 	findings = append(findings, l.checkAuditLogRetention())
@@ -33,11 +46,11 @@ func (l *LoggingAuditor) Audit(ctx context.Context) ([]types.Suggestion, error)
 	// LOG-004: Failed login monitoring
 	findings = append(findings, l.checkFailedLoginMonitoring())
 
-	// LOG-005: Webhook configuration (manual check)
-	findings = append(findings, l.checkWebhookConfiguration())
+	// LOG-005: Webhook secret rotation and endpoint transport
+	findings = append(findings, l.checkWebhookSecrets(webhooks, webhookErr, l.clock.Now()))
 
-	// LOG-006: OAuth client review (manual check)
-	findings = append(findings, l.checkOAuthClients())
+	// LOG-006: OAuth clients with the all scope or removed creators
+	findings = append(findings, l.checkOAuthClients(data.Keys, data.KeysErr, newKeyCreators(data.Users, data.UsersErr), l.clock.Now()))
 
 	// LOG-007: SCIM configuration (manual check)
 	findings = append(findings, l.checkSCIMConfiguration())
@@ -54,58 +67,12 @@ func (l *LoggingAuditor) Audit(ctx context.Context) ([]types.Suggestion, error)
 	// LOG-011: Security contact configuration
 	findings = append(findings, l.checkSecurityContact())
 
-	// LOG-012: Webhook events configuration
-	findings = append(findings, l.checkWebhookEvents())
-
-	// USER-001: User roles and ownership review
-	findings = append(findings, l.checkUserRoles())
-
-	// DEV-013: Device posture configuration
-	findings = append(findings, l.checkDevicePosture())
+	// LOG-012: Webhook subscriptions to critical events
+	findings = append(findings, l.checkWebhookEvents(webhooks, webhookErr))
 
 	return findings, nil
 }
 
-func (l *LoggingAuditor) checkNetworkFlowLogs() types.Suggestion {
-	return types.Suggestion{
-		ID:          "LOG-001",
-		Title:       "Network flow logs configuration",
-		Severity:    types.Informational,
-		Category:    types.LoggingAdmin,
-		Description: "Network flow logs are disabled by default and only available for Premium/Enterprise plans.",
-		Remediation: "Navigate to admin console > Network flow logs > Start logging. Enable log streaming for retention beyond 30 days.",
-		Source:      "https://tailscale.com/kb/1219/network-flow-logs",
-		Pass:        false, // Manual check required
-		Details:     "MANUAL CHECK REQUIRED: Verify network flow logs are enabled in admin console.",
-		Fix: &types.FixInfo{
-			Type:        types.FixTypeManual,
-			Description: "Enable network flow logs in admin console",
-			AdminURL:    "https://login.tailscale.com/admin/logs/network",
-			DocURL:      "https://tailscale.com/kb/1219/network-flow-logs",
-		},
-	}
-}
-
-func (l *LoggingAuditor) checkLogStreaming() types.Suggestion {
-	return types.Suggestion{
-		ID:          "LOG-002",
-		Title:       "Log streaming for long-term retention",
-		Severity:    types.Informational,
-		Category:    types.LoggingAdmin,
-		Description: "Configuration audit logs are retained for 90 days, network flow logs for 30 days. Log streaming required for longer retention.",
-		Remediation: "Configure log streaming to SIEM or S3 for compliance requirements exceeding retention limits.",
-		Source:      "https://tailscale.com/kb/1203/audit-logging",
-		Pass:        false, // Manual check required
-		Details:     "MANUAL CHECK REQUIRED: Verify log streaming is configured if retention >30/90 days is required.",
-		Fix: &types.FixInfo{
-			Type:        types.FixTypeManual,
-			Description: "Configure log streaming in admin console",
-			AdminURL:    "https://login.tailscale.com/admin/logs",
-			DocURL:      "https://tailscale.com/kb/1203/audit-logging",
-		},
-	}
-}
-
 func (l *LoggingAuditor) checkAuditLogRetention() types.Suggestion {
 	return types.Suggestion{
 		ID:          "LOG-003",
@@ -134,46 +101,6 @@ func (l *LoggingAuditor) checkFailedLoginMonitoring() types.Suggestion {
 	}
 }
 
-func (l *LoggingAuditor) checkWebhookConfiguration() types.Suggestion {
-	return types.Suggestion{
-		ID:          "LOG-005",
-		Title:       "Webhook secrets never expire",
-		Severity:    types.Informational,
-		Category:    types.LoggingAdmin,
-		Description: "Webhook endpoint secrets have no automatic expiration. If compromised, anyone can send fake events.",
-		Remediation: "Implement periodic manual rotation schedule for webhook secrets. Store secrets securely.",
-		Source:      "https://tailscale.com/kb/1213/webhooks",
-		Pass:        false, // Manual check required
-		Details:     "MANUAL CHECK REQUIRED: Review webhook configurations and implement rotation schedule.",
-		Fix: &types.FixInfo{
-			Type:        types.FixTypeManual,
-			Description: "Review and rotate webhook secrets in admin console",
-			AdminURL:    "https://login.tailscale.com/admin/settings/webhooks",
-			DocURL:      "https://tailscale.com/kb/1213/webhooks",
-		},
-	}
-}
-
-func (l *LoggingAuditor) checkOAuthClients() types.Suggestion {
-	return types.Suggestion{
-		ID:          "LOG-006",
-		Title:       "OAuth clients persist after user removal",
-		Severity:    types.Informational,
-		Category:    types.LoggingAdmin,
-		Description: "OAuth clients continue functioning after creating user loses tailnet access, creating persistent access vectors.",
-		Remediation: "Review all OAuth clients in Trust credentials page. Identify clients created by former employees. Add OAuth client review to offboarding checklist.",
-		Source:      "https://tailscale.com/kb/1215/oauth-clients",
-		Pass:        false, // Manual check required
-		Details:     "MANUAL CHECK REQUIRED: Review OAuth clients in admin console and verify creators still have access.",
-		Fix: &types.FixInfo{
-			Type:        types.FixTypeManual,
-			Description: "Review OAuth clients in admin console",
-			AdminURL:    "https://login.tailscale.com/admin/settings/oauth",
-			DocURL:      "https://tailscale.com/kb/1215/oauth-clients",
-		},
-	}
-}
-
 func (l *LoggingAuditor) checkSCIMConfiguration() types.Suggestion {
 	return types.Suggestion{
 		ID:          "LOG-007",
@@ -261,107 +188,3 @@ func (l *LoggingAuditor) checkSecurityContact() types.Suggestion {
 		},
 	}
 }
-
-func (l *LoggingAuditor) checkWebhookEvents() types.Suggestion {
-	return types.Suggestion{
-		ID:          "LOG-012",
-		Title:       "Webhooks for critical events",
-		Severity:    types.Informational,
-		Category:    types.LoggingAdmin,
-		Description: "Webhooks can notify external systems about critical tailnet events (device additions, ACL changes, user changes). Enable webhooks for security monitoring.",
-		Remediation: "Configure webhooks for critical event types: nodeCreated, nodeDeleted, nodeApproved, aclUpdated, userCreated, userDeleted. Integrate with your SIEM or alerting system.",
-		Source:      "https://tailscale.com/kb/1213/webhooks",
-		Pass:        false, // Manual check required
-		Details: []string{
-			"MANUAL CHECK REQUIRED: Verify webhooks are configured for critical events.",
-			"",
-			"Recommended webhook events to enable:",
-			"  - nodeCreated: New device added to tailnet",
-			"  - nodeDeleted: Device removed from tailnet",
-			"  - nodeApproved: Pending device approved",
-			"  - nodeKeyExpiringInOneDay: Key expiry warning",
-			"  - aclUpdated: ACL policy changed",
-			"  - userCreated: New user added",
-			"  - userDeleted: User removed",
-			"  - userRoleUpdated: User permissions changed",
-		},
-		Fix: &types.FixInfo{
-			Type:        types.FixTypeManual,
-			Description: "Configure webhooks for critical events in admin console",
-			AdminURL:    "https://login.tailscale.com/admin/settings/webhooks",
-			DocURL:      "https://tailscale.com/kb/1213/webhooks",
-		},
-	}
-}
-
-func (l *LoggingAuditor) checkUserRoles() types.Suggestion {
-	return types.Suggestion{
-		ID:          "USER-001",
-		Title:       "Review user roles and ownership",
-		Severity:    types.Informational,
-		Category:    types.LoggingAdmin,
-		Description: "User roles (Owner, Admin, Member, IT Admin, Network Admin, Auditor, Billing Admin) control access to tailnet administration. Regular review prevents privilege creep.",
-		Remediation: "Audit user roles periodically. Apply least privilege - most users should be Members. Review Owners (cannot be removed) and Admins (full control). Use IT Admin for helpdesk roles.",
-		Source:      "https://tailscale.com/kb/1352/roles",
-		Pass:        false, // Manual check required
-		Details: []string{
-			"MANUAL CHECK REQUIRED: Review user roles in admin console.",
-			"",
-			"Role hierarchy (highest to lowest privilege):",
-			"  - Owner: Full control, cannot be removed, irrevocable",
-			"  - Admin: Full control, can manage users/ACLs/devices",
-			"  - IT Admin: Device management only",
-			"  - Network Admin: ACL and network settings",
-			"  - Auditor: Read-only access to logs and settings",
-			"  - Billing Admin: Billing and subscription only",
-			"  - Member: Regular user, no admin access",
-			"",
-			"Security recommendations:",
-			"  - Minimize Owner accounts (ideally 1-2)",
-			"  - Review Admin accounts quarterly",
-			"  - Use specific roles (IT Admin, Network Admin) instead of full Admin",
-		},
-		Fix: &types.FixInfo{
-			Type:        types.FixTypeManual,
-			Description: "Review and adjust user roles in admin console",
-			AdminURL:    "https://login.tailscale.com/admin/users",
-			DocURL:      "https://tailscale.com/kb/1352/roles",
-		},
-	}
-}
-
-func (l *LoggingAuditor) checkDevicePosture() types.Suggestion {
-	return types.Suggestion{
-		ID:          "DEV-013",
-		Title:       "Device posture configuration",
-		Severity:    types.Informational,
-		Category:    types.LoggingAdmin,
-		Description: "Device posture integrations (Intune, Jamf, CrowdStrike, etc.) can restrict tailnet access based on device health and compliance status. Enterprise feature.",
-		Remediation: "If available on your plan, configure device posture integration with your MDM/EDR. Define posture attributes in nodeAttrs to restrict non-compliant devices.",
-		Source:      "https://tailscale.com/kb/1288/device-posture",
-		Pass:        false, // Manual check required
-		Details: []string{
-			"MANUAL CHECK REQUIRED: Review device posture configuration if available on your plan.",
-			"",
-			"Device posture integrations:",
-			"  - Intune: Microsoft endpoint management",
-			"  - Jamf: Apple device management",
-			"  - CrowdStrike Falcon: EDR posture",
-			"  - Kolide: Cross-platform device health",
-			"  - Custom: Define posture attributes via API",
-			"",
-			"To use device posture:",
-			"  1. Configure integration in Settings > Integrations",
-			"  2. Define posture requirements in ACL nodeAttrs",
-			"  3. Non-compliant devices can be restricted from accessing resources",
-			"",
-			"Note: Device posture is an Enterprise feature.",
-		},
-		Fix: &types.FixInfo{
-			Type:        types.FixTypeManual,
-			Description: "Configure device posture in admin console (Enterprise feature)",
-			AdminURL:    "https://login.tailscale.com/admin/settings/integrations",
-			DocURL:      "https://tailscale.com/kb/1288/device-posture",
-		},
-	}
-}
@@ -0,0 +1,137 @@
+package auditor
+
+import (
+	"fmt"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+const (
+	// Days Tailscale keeps configuration audit logs and network flow logs
+	configLogRetentionDays  = 90
+	networkLogRetentionDays = 30
+)
+
+func (l *LoggingAuditor) checkNetworkFlowLogs(settings *client.TailnetSettings, err error) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOG-001",
+		Title:       "Network flow logs configuration",
+		Severity:    types.Low,
+		Category:    types.LoggingAdmin,
+		Description: "Network flow logs record which devices connect to which, and are disabled by default. They are only available on Premium and Enterprise plans.",
+		Remediation: "Navigate to admin console > Network flow logs > Start logging. Enable log streaming for retention beyond 30 days.",
+		Source:      "https://tailscale.com/kb/1219/network-flow-logs",
+		Pass:        true,
+	}
+
+	if err != nil || settings == nil {
+		finding.Severity = types.Informational
+		finding.Pass = false
+		finding.Description = fmt.Sprintf("Cannot read tailnet settings: %v. Network flow logs are disabled by default and only available for Premium/Enterprise plans.", err)
+		finding.Details = []string{
+			"MANUAL CHECK REQUIRED: Verify network flow logs are enabled in admin console.",
+			"Grant the API key or OAuth client the feature_settings:read scope for a definitive result.",
+		}
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Enable network flow logs in admin console",
+			AdminURL:    "https://login.tailscale.com/admin/logs/network",
+			DocURL:      "https://tailscale.com/kb/1219/network-flow-logs",
+		}
+		return finding
+	}
+
+	if settings.NetworkFlowLoggingOn {
+		finding.Description = fmt.Sprintf("Network flow logging is enabled. Logs are kept %d days unless streamed (LOG-002).", networkLogRetentionDays)
+		finding.Details = []string{"Network flow logging (networkFlowLoggingOn): on"}
+		return finding
+	}
+
+	finding.Pass = false
+	finding.Description = "Network flow logging is disabled. Connections between devices aren't recorded, so lateral movement inside the tailnet leaves no trace."
+	finding.Details = []string{
+		"Network flow logging (networkFlowLoggingOn): off",
+		"Flow logs require a Premium or Enterprise plan; ignore this finding if the plan doesn't include them.",
+	}
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Enable network flow logs in admin console",
+		AdminURL:    "https://login.tailscale.com/admin/logs/network",
+		DocURL:      "https://tailscale.com/kb/1219/network-flow-logs",
+	}
+
+	return finding
+}
+
+// checkLogStreaming reports where configuration and network logs are
+// streamed. Network logs are only required when settings show flow logging
+// is on.
+func (l *LoggingAuditor) checkLogStreaming(configStream, networkStream *client.LogStream, err error, settings *client.TailnetSettings) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOG-002",
+		Title:       "Log streaming for long-term retention",
+		Severity:    types.Medium,
+		Category:    types.LoggingAdmin,
+		Description: fmt.Sprintf("Configuration audit logs are retained for %d days, network flow logs for %d days. Log streaming required for longer retention.", configLogRetentionDays, networkLogRetentionDays),
+		Remediation: "Configure log streaming to SIEM or S3 for compliance requirements exceeding retention limits.",
+		Source:      "https://tailscale.com/kb/1255/log-streaming",
+		Pass:        true,
+	}
+
+	fix := &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Configure log streaming in admin console",
+		AdminURL:    "https://login.tailscale.com/admin/logs",
+		DocURL:      "https://tailscale.com/kb/1255/log-streaming",
+	}
+
+	if err != nil {
+		finding.Severity = types.Informational
+		finding.Pass = false
+		finding.Description = fmt.Sprintf("Cannot read log streaming configuration: %v.", err)
+		finding.Details = []string{
+			fmt.Sprintf("MANUAL CHECK REQUIRED: Verify log streaming is configured if retention >%d/%d days is required.", networkLogRetentionDays, configLogRetentionDays),
+			"Grant the API key or OAuth client the log_streaming:read scope for a definitive result.",
+		}
+		finding.Fix = fix
+		return finding
+	}
+
+	flowLogsOn := settings != nil && settings.NetworkFlowLoggingOn
+
+	var problems, details []string
+	if configStream != nil {
+		details = append(details, "Configuration audit logs: streamed to "+configStream.Destination())
+	} else {
+		problems = append(problems, "configuration audit logs")
+		details = append(details, fmt.Sprintf("Configuration audit logs: not streamed (kept %d days)", configLogRetentionDays))
+	}
+	switch {
+	case networkStream != nil:
+		details = append(details, "Network flow logs: streamed to "+networkStream.Destination())
+	case flowLogsOn:
+		problems = append(problems, "network flow logs")
+		details = append(details, fmt.Sprintf("Network flow logs: not streamed (kept %d days)", networkLogRetentionDays))
+	case settings != nil:
+		details = append(details, "Network flow logs: not streamed (flow logging is off, see LOG-001)")
+	default:
+		details = append(details, "Network flow logs: not streamed (flow logging state unknown)")
+	}
+	finding.Details = details
+
+	if len(problems) == 0 {
+		finding.Description = "Configuration audit logs and enabled network flow logs are streamed for long-term retention."
+		return finding
+	}
+
+	finding.Pass = false
+	if len(problems) == 2 {
+		finding.Description = "Neither configuration audit logs nor network flow logs are streamed. Both are lost once Tailscale's retention period ends."
+	} else {
+		finding.Description = fmt.Sprintf("No log streaming destination is configured for %s. They are lost once Tailscale's retention period ends.", problems[0])
+	}
+	finding.Fix = fix
+
+	return finding
+}
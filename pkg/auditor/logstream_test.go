@@ -0,0 +1,61 @@
+package auditor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestNetworkFlowLogs(t *testing.T) {
+	l := &LoggingAuditor{}
+
+	if result := l.checkNetworkFlowLogs(&client.TailnetSettings{NetworkFlowLoggingOn: true}, nil); !result.Pass {
+		t.Errorf("LOG-001 should pass with flow logging on: %s", result.Description)
+	}
+
+	result := l.checkNetworkFlowLogs(&client.TailnetSettings{}, nil)
+	if result.Pass || result.Severity != types.Low {
+		t.Errorf("LOG-001 with flow logging off: Pass = %v, Severity = %v", result.Pass, result.Severity)
+	}
+
+	result = l.checkNetworkFlowLogs(nil, errors.New("403 Forbidden"))
+	if result.Pass || result.Severity != types.Informational {
+		t.Errorf("LOG-001 should fall back to manual review on API error, got %v", result.Severity)
+	}
+}
+
+func TestLogStreaming(t *testing.T) {
+	l := &LoggingAuditor{}
+	s3 := &client.LogStream{LogType: client.LogTypeConfiguration, DestinationType: "s3", S3Bucket: "audit-logs"}
+	splunk := &client.LogStream{LogType: client.LogTypeNetwork, DestinationType: "splunk", URL: "https://splunk.example.com:8088"}
+	flowOn := &client.TailnetSettings{NetworkFlowLoggingOn: true}
+
+	result := l.checkLogStreaming(s3, nil, nil, &client.TailnetSettings{})
+	if !result.Pass {
+		t.Errorf("LOG-002 should pass when flow logging is off and audit logs are streamed: %s", result.Description)
+	}
+
+	result = l.checkLogStreaming(s3, nil, nil, flowOn)
+	joined := strings.Join(result.Details.([]string), "\n")
+	if result.Pass || !strings.Contains(result.Description, "network flow logs") || !strings.Contains(joined, "streamed to s3 s3://audit-logs") {
+		t.Errorf("LOG-002 with unstreamed flow logs = %s\n%s", result.Description, joined)
+	}
+
+	result = l.checkLogStreaming(nil, splunk, nil, flowOn)
+	joined = strings.Join(result.Details.([]string), "\n")
+	if result.Pass || !strings.Contains(result.Description, "configuration audit logs") || !strings.Contains(joined, "streamed to splunk https://splunk.example.com:8088") {
+		t.Errorf("LOG-002 with unstreamed audit logs = %s\n%s", result.Description, joined)
+	}
+
+	if result = l.checkLogStreaming(nil, nil, nil, flowOn); result.Pass || !strings.HasPrefix(result.Description, "Neither") {
+		t.Errorf("LOG-002 with no streams = %s", result.Description)
+	}
+
+	result = l.checkLogStreaming(nil, nil, errors.New("403 Forbidden"), nil)
+	if result.Pass || result.Severity != types.Informational {
+		t.Errorf("LOG-002 should fall back to manual review on API error, got %v", result.Severity)
+	}
+}
@@ -3,29 +3,49 @@ package auditor
 import (
 	"context"
 	"fmt"
+	"net/netip"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/Adversis/tailsnitch/pkg/client"
 	"github.com/Adversis/tailsnitch/pkg/types"
 )
 
+// trustedDomains is set via CLI flag --domains
+var trustedDomains []string
+
+// SetTrustedDomains sets the domains the organization controls. Custom DERP
+// hostnames outside these domains are flagged. When unset, domains are inferred
+// from the tailnet name and device owners.
+func SetTrustedDomains(domains []string) {
+	trustedDomains = nil
+	for _, d := range domains {
+		d = strings.ToLower(strings.Trim(strings.TrimSpace(d), "."))
+		if d != "" {
+			trustedDomains = append(trustedDomains, d)
+		}
+	}
+}
+
 // NetworkAuditor checks for network exposure issues
 type NetworkAuditor struct {
 	client *client.Client
+	clock  Clock // Not used for liveness, which is judged at the current time
 }
 
 // NewNetworkAuditor creates a new network auditor
-func NewNetworkAuditor(c *client.Client) *NetworkAuditor {
-	return &NetworkAuditor{client: c}
+func NewNetworkAuditor(c *client.Client, clock Clock) *NetworkAuditor {
+	return &NetworkAuditor{client: c, clock: clock}
 }
 
 // Audit performs network exposure security checks
-func (n *NetworkAuditor) Audit(ctx context.Context, policy ACLPolicy) ([]types.Suggestion, error) {
+func (n *NetworkAuditor) Audit(ctx context.Context, policy ACLPolicy, data *TailnetData) ([]types.Suggestion, error) {
 	var findings []types.Suggestion
 
-	devices, err := n.client.GetDevices(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get devices: %w", err)
+	devices := data.Devices
+	if data.DevicesErr != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", data.DevicesErr)
 	}
 
 	// NET-001: Check for Funnel endpoints
@@ -38,7 +58,8 @@ func (n *NetworkAuditor) Audit(ctx context.Context, policy ACLPolicy) ([]types.S
 	findings = append(findings, n.checkSubnetRoutes(ctx, devices))
 
 	// NET-004: Check for HTTPS/Certificate Transparency exposure
-	findings = append(findings, n.checkHTTPSExposure(policy))
+	dnsConfig, _ := n.client.GetDNSConfig(ctx) // Ignore error, check will handle nil
+	findings = append(findings, n.checkHTTPSExposure(policy, devices, dnsConfig))
 
 	// NET-005: Check for exit nodes
 	findings = append(findings, n.checkExitNodes(devices))
@@ -49,6 +70,21 @@ func (n *NetworkAuditor) Audit(ctx context.Context, policy ACLPolicy) ([]types.S
 	// NET-007: Check for app connectors
 	findings = append(findings, n.checkAppConnectors(devices))
 
+	// NET-008: Check for custom DERP nodes with TLS verification disabled
+	findings = append(findings, n.checkDERPInsecure(policy))
+
+	// NET-009: Check custom DERP redundancy when default regions are omitted
+	findings = append(findings, n.checkDERPRedundancy(policy))
+
+	// NET-010: Check for STUN-only custom DERP regions
+	findings = append(findings, n.checkDERPSTUNOnly(policy))
+
+	// NET-011: Check custom DERP hostnames are in trusted domains
+	findings = append(findings, n.checkDERPHostnames(policy, n.domains(devices)))
+
+	// NET-012: Check subnet routers, exit nodes and app connectors for role hygiene
+	findings = append(findings, n.checkInfraHygiene(devices, policy))
+
 	return findings, nil
 }
 
@@ -73,7 +109,10 @@ func (n *NetworkAuditor) checkFunnelEndpoints(policy ACLPolicy) types.Suggestion
 		}
 	}
 
-	if len(funnelConfigs) > 0 {
+	// Endpoints actually funneled, from Serve snapshots loaded with --serve-dir
+	funneled := serveInventoryDetails(func(e types.ServeEndpoint) bool { return e.Funnel })
+
+	if len(funnelConfigs) > 0 || len(funneled) > 0 {
 		finding.Pass = false
 		finding.Details = funnelConfigs
 		finding.Description = fmt.Sprintf("Found %d Funnel configuration(s). These expose services to the public internet.", len(funnelConfigs))
@@ -85,6 +124,20 @@ func (n *NetworkAuditor) checkFunnelEndpoints(policy ACLPolicy) types.Suggestion
 		}
 	}
 
+	if serveInventory != nil && !finding.Pass {
+		if len(funneled) > 0 {
+			finding.Description = fmt.Sprintf("Found %d Funnel configuration(s) and %d endpoint(s) public on the internet in Serve snapshots from %d node(s).",
+				len(funnelConfigs), len(funneled), serveInventory.Summary.Nodes)
+			details := funnelConfigs
+			if len(details) > 0 {
+				details = append(details, "")
+			}
+			finding.Details = append(append(details, "Endpoints public via Funnel:"), funneled...)
+		} else {
+			finding.Description += fmt.Sprintf(" No Serve snapshot from %d node(s) shows an active Funnel endpoint.", serveInventory.Summary.Nodes)
+		}
+	}
+
 	return finding
 }
 
@@ -173,6 +226,46 @@ func (n *NetworkAuditor) checkSubnetRoutes(ctx context.Context, devices []*clien
 		}
 	}
 
+	// Availability analysis over enabled and advertised routes. Whether a
+	// router is online is judged at the current time, not the --as-of date.
+	availability := analyzeSubnetRoutes(devices, time.Now())
+	if details, ok := finding.Details.([]string); ok {
+		sections := []struct {
+			heading string
+			lines   []string
+		}{
+			{"Routes with a single router (no failover):", availability.SingleRouter},
+			{"Routes whose routers are all offline:", availability.AllStale},
+			{"Overlapping routes from routers with different tags:", availability.Overlapping},
+			{"Overly broad routes:", availability.Broad},
+		}
+		for _, section := range sections {
+			if len(section.lines) == 0 {
+				continue
+			}
+			details = append(details, "", section.heading)
+			details = append(details, section.lines...)
+		}
+		finding.Details = details
+
+		var problems []string
+		if count := len(availability.SingleRouter); count > 0 {
+			problems = append(problems, fmt.Sprintf("%d route(s) have no failover router", count))
+		}
+		if count := len(availability.AllStale); count > 0 {
+			problems = append(problems, fmt.Sprintf("%d route(s) have only offline routers", count))
+		}
+		if count := len(availability.Overlapping); count > 0 {
+			problems = append(problems, fmt.Sprintf("%d overlapping route(s) cross tag boundaries", count))
+		}
+		if count := len(availability.Broad); count > 0 {
+			problems = append(problems, fmt.Sprintf("%d route(s) are overly broad", count))
+		}
+		if len(problems) > 0 {
+			finding.Description += " " + strings.Join(problems, "; ") + "."
+		}
+	}
+
 	if len(unapprovedRoutes) > 0 {
 		if finding.Pass {
 			// No subnet routers found, but there are unapproved routes
@@ -199,7 +292,27 @@ func (n *NetworkAuditor) checkSubnetRoutes(ctx context.Context, devices []*clien
 	return finding
 }
 
-func (n *NetworkAuditor) checkHTTPSExposure(policy ACLPolicy) types.Suggestion {
+// certificateFQDNs lists the MagicDNS names that appear in Certificate
+// Transparency logs once HTTPS certificates are issued, noting any that
+// violate the naming policy
+func certificateFQDNs(devices []*client.Device) []string {
+	var fqdns []string
+	for _, dev := range devices {
+		// Shared-in devices get certificates under their home tailnet's name
+		if dev.IsExternal || dev.Name == "" {
+			continue
+		}
+		line := dev.Name
+		if reasons := namingPolicy.Violations(dev.Name, dev.Hostname, dev.Tags); len(reasons) > 0 {
+			line += " (" + strings.Join(reasons, "; ") + ")"
+		}
+		fqdns = append(fqdns, line)
+	}
+	sort.Strings(fqdns)
+	return fqdns
+}
+
+func (n *NetworkAuditor) checkHTTPSExposure(policy ACLPolicy, devices []*client.Device, dnsConfig *client.DNSConfig) types.Suggestion {
 	finding := types.Suggestion{
 		ID:          "NET-004",
 		Title:       "HTTPS certificates publish names to CT logs",
@@ -221,10 +334,20 @@ func (n *NetworkAuditor) checkHTTPSExposure(policy ACLPolicy) types.Suggestion {
 		}
 	}
 
+	// Machine names only get certificates under MagicDNS
+	var fqdns []string
+	if dnsConfig != nil && dnsConfig.MagicDNS {
+		fqdns = certificateFQDNs(devices)
+	}
+
 	if len(httpsConfigs) > 0 {
 		finding.Pass = false
 		finding.Details = httpsConfigs
 		finding.Description = fmt.Sprintf("Found %d HTTPS-related configuration(s). Machine names may be published to CT logs.", len(httpsConfigs))
+		if len(fqdns) > 0 {
+			details := append(httpsConfigs, "", "FQDNs published to CT logs when certificates are issued:")
+			finding.Details = append(details, fqdns...)
+		}
 		finding.Fix = &types.FixInfo{
 			Type:        types.FixTypeManual,
 			Description: "Review HTTPS configuration and machine names",
@@ -235,6 +358,9 @@ func (n *NetworkAuditor) checkHTTPSExposure(policy ACLPolicy) types.Suggestion {
 		// This is informational - HTTPS might be enabled at the tailnet level
 		finding.Severity = types.Informational
 		finding.Description = "HTTPS configuration not found in nodeAttrs. If HTTPS is enabled at tailnet level, machine names are published to CT logs."
+		if len(fqdns) > 0 {
+			finding.Details = append([]string{"FQDNs published to CT logs if HTTPS is enabled:"}, fqdns...)
+		}
 		finding.Fix = &types.FixInfo{
 			Type:        types.FixTypeManual,
 			Description: "Review HTTPS/DNS settings",
@@ -318,7 +444,10 @@ func (n *NetworkAuditor) checkServeExposure(policy ACLPolicy) types.Suggestion {
 		}
 	}
 
-	if len(serveConfigs) > 0 {
+	// Endpoints actually served, from Serve snapshots loaded with --serve-dir
+	served := serveInventoryDetails(func(e types.ServeEndpoint) bool { return true })
+
+	if len(serveConfigs) > 0 || len(served) > 0 {
 		finding.Pass = false
 		finding.Details = serveConfigs
 		finding.Description = fmt.Sprintf("Found %d Serve configuration(s). Local services are exposed to the tailnet.", len(serveConfigs))
@@ -330,6 +459,17 @@ func (n *NetworkAuditor) checkServeExposure(policy ACLPolicy) types.Suggestion {
 		}
 	}
 
+	if len(served) > 0 {
+		sum := serveInventory.Summary
+		finding.Description = fmt.Sprintf("Found %d endpoint(s) exposed by Serve on %d of %d node(s) with snapshots: %d in front of plaintext backends, %d in front of admin or data services.",
+			sum.Endpoints, sum.ExposingNodes, sum.Nodes, sum.PlaintextBackends, sum.AdminBackends)
+		details := serveConfigs
+		if len(details) > 0 {
+			details = append(details, "")
+		}
+		finding.Details = append(append(details, "Endpoints in Serve snapshots:"), served...)
+	}
+
 	return finding
 }
 
@@ -385,3 +525,274 @@ func (n *NetworkAuditor) checkAppConnectors(devices []*client.Device) types.Sugg
 
 	return finding
 }
+
+// domains returns the configured trusted domains, or infers them from the
+// tailnet name and the email domains of device owners
+func (n *NetworkAuditor) domains(devices []*client.Device) []string {
+	var tailnet string
+	if n.client != nil {
+		tailnet = n.client.Tailnet()
+	}
+	var owners []string
+	for _, dev := range devices {
+		if !dev.IsExternal {
+			owners = append(owners, dev.User)
+		}
+	}
+	return inferOrgDomains(tailnet, owners)
+}
+
+// inferOrgDomains returns the domains set with --domains, or infers them
+// from the tailnet name and the email domains of the given logins
+func inferOrgDomains(tailnet string, logins []string) []string {
+	if len(trustedDomains) > 0 {
+		return trustedDomains
+	}
+
+	seen := make(map[string]bool)
+	var inferred []string
+	for _, name := range append([]string{tailnet}, logins...) {
+		if at := strings.LastIndex(name, "@"); at >= 0 {
+			name = name[at+1:]
+		}
+		name = strings.ToLower(name)
+		if strings.Contains(name, ".") && !seen[name] {
+			seen[name] = true
+			inferred = append(inferred, name)
+		}
+	}
+	sort.Strings(inferred)
+	return inferred
+}
+
+// sortedDERPRegions returns custom DERP regions in a stable order
+func sortedDERPRegions(policy ACLPolicy) []string {
+	if policy.DERPMap == nil {
+		return nil
+	}
+	var ids []string
+	for id := range policy.DERPMap.Regions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// derpRegionLabel returns a readable name for a custom DERP region
+func derpRegionLabel(id string, region *DERPRegion) string {
+	if region.RegionCode != "" {
+		return fmt.Sprintf("region %s (%s)", id, region.RegionCode)
+	}
+	return fmt.Sprintf("region %s", id)
+}
+
+func (n *NetworkAuditor) checkDERPInsecure(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "NET-008",
+		Title:       "Custom DERP nodes skip TLS verification",
+		Severity:    types.High,
+		Category:    types.NetworkExposure,
+		Description: "InsecureForTests disables TLS certificate verification for a DERP node. Clients will relay traffic through anyone able to intercept the connection.",
+		Remediation: "Remove InsecureForTests from production DERP nodes and serve a valid certificate.",
+		Source:      "https://tailscale.com/kb/1118/custom-derp-servers",
+		Pass:        true,
+	}
+
+	var insecure []string
+	for _, id := range sortedDERPRegions(policy) {
+		region := policy.DERPMap.Regions[id]
+		if region == nil {
+			continue
+		}
+		for _, node := range region.Nodes {
+			if node.InsecureForTests {
+				insecure = append(insecure, fmt.Sprintf("%s: node %s (%s)", derpRegionLabel(id, region), node.Name, node.HostName))
+			}
+		}
+	}
+
+	if len(insecure) > 0 {
+		finding.Pass = false
+		finding.Details = insecure
+		finding.Description = fmt.Sprintf("Found %d custom DERP node(s) with InsecureForTests enabled.", len(insecure))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Remove \"InsecureForTests\": true from derpMap nodes in the policy file",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1118/custom-derp-servers",
+		}
+	}
+
+	return finding
+}
+
+// derpRelayNodes counts nodes in a region that can relay traffic (not STUN-only)
+func derpRelayNodes(region *DERPRegion) int {
+	count := 0
+	for _, node := range region.Nodes {
+		if !node.STUNOnly {
+			count++
+		}
+	}
+	return count
+}
+
+func (n *NetworkAuditor) checkDERPRedundancy(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "NET-009",
+		Title:       "Custom DERP map lacks redundancy",
+		Severity:    types.Medium,
+		Category:    types.NetworkExposure,
+		Description: "With OmitDefaultRegions, clients can only use your custom DERP regions. If those go down, devices that can't connect directly lose connectivity.",
+		Remediation: "Run at least two custom regions, each with at least two relay nodes, or keep Tailscale's default regions as a fallback.",
+		Source:      "https://tailscale.com/kb/1118/custom-derp-servers",
+		Pass:        true,
+	}
+
+	if policy.DERPMap == nil || !policy.DERPMap.OmitDefaultRegions {
+		return finding
+	}
+
+	var issues []string
+	usableRegions := 0
+	for _, id := range sortedDERPRegions(policy) {
+		region := policy.DERPMap.Regions[id]
+		if region == nil {
+			continue
+		}
+		relays := derpRelayNodes(region)
+		if relays > 0 {
+			usableRegions++
+		}
+		if relays == 1 {
+			issues = append(issues, fmt.Sprintf("%s has a single relay node", derpRegionLabel(id, region)))
+		}
+	}
+
+	if usableRegions < 2 {
+		issues = append([]string{fmt.Sprintf("OmitDefaultRegions is set with %d usable custom region(s)", usableRegions)}, issues...)
+		finding.Pass = false
+		finding.Details = issues
+		finding.Description = fmt.Sprintf("Default DERP regions are disabled and only %d custom region(s) can relay traffic.", usableRegions)
+	} else if len(issues) > 0 {
+		finding.Pass = false
+		finding.Severity = types.Low
+		finding.Details = issues
+		finding.Description = fmt.Sprintf("Default DERP regions are disabled and %d custom region(s) rely on a single relay node.", len(issues))
+	}
+
+	if !finding.Pass {
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Add relay nodes or regions to derpMap, or set OmitDefaultRegions to false",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1118/custom-derp-servers",
+		}
+	}
+
+	return finding
+}
+
+func (n *NetworkAuditor) checkDERPSTUNOnly(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "NET-010",
+		Title:       "Custom DERP regions with STUN-only nodes",
+		Severity:    types.Low,
+		Category:    types.NetworkExposure,
+		Description: "STUN-only nodes help with NAT traversal but cannot relay traffic. A region made up only of STUN-only nodes provides no relay fallback.",
+		Remediation: "Add at least one relay-capable node to each custom region, or remove regions that are not meant to relay.",
+		Source:      "https://tailscale.com/kb/1118/custom-derp-servers",
+		Pass:        true,
+	}
+
+	var stunOnly []string
+	for _, id := range sortedDERPRegions(policy) {
+		region := policy.DERPMap.Regions[id]
+		if region == nil || len(region.Nodes) == 0 {
+			continue
+		}
+		if derpRelayNodes(region) == 0 {
+			stunOnly = append(stunOnly, fmt.Sprintf("%s: all %d node(s) are STUN-only", derpRegionLabel(id, region), len(region.Nodes)))
+		}
+	}
+
+	if len(stunOnly) > 0 {
+		finding.Pass = false
+		finding.Details = stunOnly
+		finding.Description = fmt.Sprintf("Found %d custom DERP region(s) that cannot relay traffic.", len(stunOnly))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Add a relay node (STUNOnly: false) to each region in derpMap",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1118/custom-derp-servers",
+		}
+	}
+
+	return finding
+}
+
+// inDomain reports whether a hostname, or a login name's email domain,
+// equals or is a subdomain of one of the domains
+func inDomain(name string, domains []string) bool {
+	if at := strings.LastIndex(name, "@"); at >= 0 {
+		name = name[at+1:]
+	}
+	host := strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *NetworkAuditor) checkDERPHostnames(policy ACLPolicy, domains []string) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "NET-011",
+		Title:       "Custom DERP hostnames outside trusted domains",
+		Severity:    types.Medium,
+		Category:    types.NetworkExposure,
+		Description: "Custom DERP nodes relay encrypted tailnet traffic and see connection metadata. Nodes on domains you don't control may be operated by a third party.",
+		Remediation: "Host custom DERP nodes under domains your organization controls, or pass them with --domains if they are yours.",
+		Source:      "https://tailscale.com/kb/1118/custom-derp-servers",
+		Pass:        true,
+	}
+
+	if len(domains) == 0 {
+		return finding
+	}
+
+	var outside []string
+	for _, id := range sortedDERPRegions(policy) {
+		region := policy.DERPMap.Regions[id]
+		if region == nil {
+			continue
+		}
+		for _, node := range region.Nodes {
+			if node.HostName == "" {
+				continue
+			}
+			// IP literals can't be attributed to a domain
+			if _, err := netip.ParseAddr(node.HostName); err == nil {
+				continue
+			}
+			if !inDomain(node.HostName, domains) {
+				outside = append(outside, fmt.Sprintf("%s: node %s (%s)", derpRegionLabel(id, region), node.Name, node.HostName))
+			}
+		}
+	}
+
+	if len(outside) > 0 {
+		finding.Pass = false
+		finding.Details = append(outside, fmt.Sprintf("Trusted domains: %s", strings.Join(domains, ", ")))
+		finding.Description = fmt.Sprintf("Found %d custom DERP node(s) with hostnames outside trusted domains.", len(outside))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Move DERP nodes to hostnames under your own domains",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1118/custom-derp-servers",
+		}
+	}
+
+	return finding
+}
@@ -0,0 +1,469 @@
+package auditor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestCheckDERPMap(t *testing.T) {
+	n := &NetworkAuditor{}
+
+	relay := func(name, host string) DERPNode {
+		return DERPNode{Name: name, HostName: host}
+	}
+
+	tests := []struct {
+		name         string
+		derpMap      *DERPMap
+		wantInsecure bool
+		wantRedund   bool
+		wantRedSev   types.Severity
+		wantSTUN     bool
+		wantHosts    bool
+	}{
+		{
+			name:         "no derpMap",
+			derpMap:      nil,
+			wantInsecure: true,
+			wantRedund:   true,
+			wantSTUN:     true,
+			wantHosts:    true,
+		},
+		{
+			name: "redundant custom regions on own domain",
+			derpMap: &DERPMap{
+				OmitDefaultRegions: true,
+				Regions: map[string]*DERPRegion{
+					"900": {RegionCode: "nyc", Nodes: []DERPNode{relay("900a", "derp1.example.com"), relay("900b", "derp2.example.com")}},
+					"901": {RegionCode: "sfo", Nodes: []DERPNode{relay("901a", "derp3.example.com"), relay("901b", "derp4.example.com")}},
+				},
+			},
+			wantInsecure: true,
+			wantRedund:   true,
+			wantSTUN:     true,
+			wantHosts:    true,
+		},
+		{
+			name: "single region, insecure, foreign host",
+			derpMap: &DERPMap{
+				OmitDefaultRegions: true,
+				Regions: map[string]*DERPRegion{
+					"900": {Nodes: []DERPNode{{Name: "900a", HostName: "derp.thirdparty.net", InsecureForTests: true}}},
+				},
+			},
+			wantInsecure: false,
+			wantRedund:   false,
+			wantRedSev:   types.Medium,
+			wantSTUN:     true,
+			wantHosts:    false,
+		},
+		{
+			name: "STUN-only region with defaults kept",
+			derpMap: &DERPMap{
+				Regions: map[string]*DERPRegion{
+					"900": {Nodes: []DERPNode{{Name: "900a", HostName: "192.0.2.10", STUNOnly: true}}},
+				},
+			},
+			wantInsecure: true,
+			wantRedund:   true,
+			wantSTUN:     false,
+			wantHosts:    true,
+		},
+		{
+			name: "two regions, one with a single relay",
+			derpMap: &DERPMap{
+				OmitDefaultRegions: true,
+				Regions: map[string]*DERPRegion{
+					"900": {Nodes: []DERPNode{relay("900a", "derp1.example.com"), relay("900b", "derp2.example.com")}},
+					"901": {Nodes: []DERPNode{relay("901a", "derp3.example.com")}},
+				},
+			},
+			wantInsecure: true,
+			wantRedund:   false,
+			wantRedSev:   types.Low,
+			wantSTUN:     true,
+			wantHosts:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := ACLPolicy{DERPMap: tt.derpMap}
+
+			if f := n.checkDERPInsecure(policy); f.Pass != tt.wantInsecure {
+				t.Errorf("NET-008 Pass = %v, want %v", f.Pass, tt.wantInsecure)
+			}
+			f := n.checkDERPRedundancy(policy)
+			if f.Pass != tt.wantRedund {
+				t.Errorf("NET-009 Pass = %v, want %v (details: %v)", f.Pass, tt.wantRedund, f.Details)
+			}
+			if !tt.wantRedund && f.Severity != tt.wantRedSev {
+				t.Errorf("NET-009 Severity = %v, want %v", f.Severity, tt.wantRedSev)
+			}
+			if f := n.checkDERPSTUNOnly(policy); f.Pass != tt.wantSTUN {
+				t.Errorf("NET-010 Pass = %v, want %v", f.Pass, tt.wantSTUN)
+			}
+			if f := n.checkDERPHostnames(policy, []string{"example.com"}); f.Pass != tt.wantHosts {
+				t.Errorf("NET-011 Pass = %v, want %v (details: %v)", f.Pass, tt.wantHosts, f.Details)
+			}
+		})
+	}
+}
+
+func TestNetworkAuditorDomains(t *testing.T) {
+	n := &NetworkAuditor{}
+	devices := []*client.Device{
+		{User: "alice@example.com"},
+		{User: "bob@Example.com"},
+		{User: "carol@partner.org", IsExternal: true},
+	}
+
+	got := n.domains(devices)
+	if len(got) != 1 || got[0] != "example.com" {
+		t.Errorf("domains() = %v, want [example.com]", got)
+	}
+
+	SetTrustedDomains([]string{" corp.example ", ""})
+	defer SetTrustedDomains(nil)
+	got = n.domains(devices)
+	if len(got) != 1 || got[0] != "corp.example" {
+		t.Errorf("domains() with override = %v, want [corp.example]", got)
+	}
+}
+
+func TestInDomain(t *testing.T) {
+	domains := []string{"example.com"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"example.com", true},
+		{"derp.example.com.", true},
+		{"notexample.com", false},
+		{"alice@Example.com", true},
+		{"bob@eu.example.com", true},
+		{"mallory@example.com.evil.org", false},
+		{"alice", false},
+	}
+	for _, tt := range tests {
+		if got := inDomain(tt.name, domains); got != tt.want {
+			t.Errorf("inDomain(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeviceRoles(t *testing.T) {
+	connectorTags := []string{"tag:connector"}
+
+	tests := []struct {
+		name string
+		dev  *client.Device
+		want []string
+	}{
+		{
+			name: "no routes",
+			dev:  &client.Device{Tags: []string{"tag:server"}},
+			want: nil,
+		},
+		{
+			name: "tagged exit node and subnet router",
+			dev:  &client.Device{Tags: []string{"tag:gw"}, OS: "linux", AdvertisedRoutes: []string{"0.0.0.0/0", "::/0", "10.0.0.0/16"}},
+			want: []string{roleExitNode, roleSubnetRouter},
+		},
+		{
+			name: "app connector routes are not subnet routes",
+			dev:  &client.Device{Tags: []string{"tag:connector"}, OS: "linux", AdvertisedRoutes: []string{"52.1.2.3/32"}},
+			want: []string{roleAppConnector},
+		},
+		{
+			name: "exit node on a laptop",
+			dev:  &client.Device{User: "alice@example.com", OS: "macOS", AdvertisedRoutes: []string{"0.0.0.0/0"}},
+			want: []string{roleExitNode, roleWorkstation},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deviceRoles(tt.dev, connectorTags)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("deviceRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppConnectorTags(t *testing.T) {
+	policy, err := parseACLPolicy(`{
+		"nodeAttrs": [
+			{"target": ["*"], "app": {"tailscale.com/app-connectors": [
+				{"name": "github", "connectors": ["tag:connector"], "domains": ["github.com"]},
+				{"name": "okta", "connectors": ["tag:connector", "tag:okta-connector"], "domains": ["example.okta.com"]},
+			]}},
+			{"target": ["tag:server"], "attr": ["funnel"]},
+		],
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := appConnectorTags(policy)
+	if strings.Join(got, ",") != "tag:connector,tag:okta-connector" {
+		t.Errorf("appConnectorTags() = %v", got)
+	}
+}
+
+func TestCheckInfraHygiene(t *testing.T) {
+	n := &NetworkAuditor{}
+	now := time.Now().UTC()
+	recent := now.Add(-time.Hour).Format(time.RFC3339)
+	old := now.AddDate(0, 0, -10).Format(time.RFC3339)
+
+	tests := []struct {
+		name         string
+		devices      []*client.Device
+		wantPass     bool
+		wantSeverity types.Severity
+		wantCount    int
+	}{
+		{
+			name: "tagged, current, online router",
+			devices: []*client.Device{
+				{Name: "router", Tags: []string{"tag:router"}, OS: "linux", ClientVersion: "1.94.2", LastSeen: recent, AdvertisedRoutes: []string{"10.0.0.0/16"}},
+			},
+			wantPass:     true,
+			wantSeverity: types.Medium,
+		},
+		{
+			name: "user-owned exit node",
+			devices: []*client.Device{
+				{Name: "box", User: "alice@example.com", OS: "linux", LastSeen: recent, AdvertisedRoutes: []string{"0.0.0.0/0"}},
+			},
+			wantPass:     false,
+			wantSeverity: types.High,
+			wantCount:    1,
+		},
+		{
+			name: "stale and outdated tagged router",
+			devices: []*client.Device{
+				{Name: "router", Tags: []string{"tag:router"}, OS: "linux", UpdateAvailable: true, LastSeen: old, AdvertisedRoutes: []string{"10.0.0.0/16"}},
+				{Name: "plain", Tags: []string{"tag:server"}, OS: "linux", LastSeen: old},
+			},
+			wantPass:     false,
+			wantSeverity: types.Medium,
+			wantCount:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := n.checkInfraHygiene(tt.devices, ACLPolicy{})
+			if result.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v", result.Pass, tt.wantPass)
+			}
+			if result.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %v, want %v", result.Severity, tt.wantSeverity)
+			}
+			if tt.wantCount > 0 {
+				details, _ := result.Details.([]string)
+				if len(details) != tt.wantCount {
+					t.Errorf("Details = %v, want %d entries", details, tt.wantCount)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeSubnetRoutes(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Hour).Format(time.RFC3339)
+	old := now.AddDate(0, 0, -10).Format(time.RFC3339)
+
+	tests := []struct {
+		name            string
+		devices         []*client.Device
+		wantSingle      int
+		wantStale       int
+		wantOverlapping int
+		wantBroad       int
+	}{
+		{
+			name: "redundant routers",
+			devices: []*client.Device{
+				{Name: "r1", Tags: []string{"tag:router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.1.0.0/16"}, EnabledRoutes: []string{"10.1.0.0/16"}},
+				{Name: "r2", Tags: []string{"tag:router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.1.0.0/16"}, EnabledRoutes: []string{"10.1.0.0/16"}},
+			},
+		},
+		{
+			name: "single router",
+			devices: []*client.Device{
+				{Name: "r1", Tags: []string{"tag:router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.1.0.0/16"}, EnabledRoutes: []string{"10.1.0.0/16"}},
+			},
+			wantSingle: 1,
+		},
+		{
+			name: "broader route backs up narrower route",
+			devices: []*client.Device{
+				{Name: "r1", Tags: []string{"tag:router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.1.2.0/24"}, EnabledRoutes: []string{"10.1.2.0/24"}},
+				{Name: "r2", Tags: []string{"tag:router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.1.0.0/16"}, EnabledRoutes: []string{"10.1.0.0/16"}},
+			},
+			wantSingle: 1, // the /16 itself has only r2
+		},
+		{
+			name: "all routers offline",
+			devices: []*client.Device{
+				{Name: "r1", Tags: []string{"tag:router"}, LastSeen: old, AdvertisedRoutes: []string{"10.1.0.0/16"}, EnabledRoutes: []string{"10.1.0.0/16"}},
+				{Name: "r2", Tags: []string{"tag:router"}, LastSeen: old, AdvertisedRoutes: []string{"10.1.0.0/16"}, EnabledRoutes: []string{"10.1.0.0/16"}},
+			},
+			wantStale: 1,
+		},
+		{
+			name: "one router still online",
+			devices: []*client.Device{
+				{Name: "r1", Tags: []string{"tag:router"}, LastSeen: old, AdvertisedRoutes: []string{"10.1.0.0/16"}, EnabledRoutes: []string{"10.1.0.0/16"}},
+				{Name: "r2", Tags: []string{"tag:router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.1.0.0/16"}, EnabledRoutes: []string{"10.1.0.0/16"}},
+			},
+		},
+		{
+			name: "overlap across tags",
+			devices: []*client.Device{
+				{Name: "prod", Tags: []string{"tag:prod-router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.1.0.0/16"}},
+				{Name: "dev", Tags: []string{"tag:dev-router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.1.5.0/24"}},
+			},
+			wantOverlapping: 1,
+		},
+		{
+			name: "broad prefix and half exit route",
+			devices: []*client.Device{
+				{Name: "r1", Tags: []string{"tag:router"}, LastSeen: recent, AdvertisedRoutes: []string{"10.0.0.0/8", "0.0.0.0/0"}},
+			},
+			wantBroad: 2,
+		},
+		{
+			name: "exit node and external devices ignored",
+			devices: []*client.Device{
+				{Name: "exit", Tags: []string{"tag:exit"}, LastSeen: recent, AdvertisedRoutes: []string{"0.0.0.0/0", "::/0"}, EnabledRoutes: []string{"0.0.0.0/0", "::/0"}},
+				{Name: "shared", IsExternal: true, LastSeen: old, AdvertisedRoutes: []string{"10.0.0.0/8"}, EnabledRoutes: []string{"10.0.0.0/8"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := analyzeSubnetRoutes(tt.devices, now)
+			if len(result.SingleRouter) != tt.wantSingle {
+				t.Errorf("SingleRouter = %v, want %d entries", result.SingleRouter, tt.wantSingle)
+			}
+			if len(result.AllStale) != tt.wantStale {
+				t.Errorf("AllStale = %v, want %d entries", result.AllStale, tt.wantStale)
+			}
+			if len(result.Overlapping) != tt.wantOverlapping {
+				t.Errorf("Overlapping = %v, want %d entries", result.Overlapping, tt.wantOverlapping)
+			}
+			if len(result.Broad) != tt.wantBroad {
+				t.Errorf("Broad = %v, want %d entries", result.Broad, tt.wantBroad)
+			}
+		})
+	}
+}
+
+func TestCheckHTTPSExposureFQDNs(t *testing.T) {
+	n := &NetworkAuditor{}
+	devices := []*client.Device{
+		{Name: "web.example.ts.net", Hostname: "web"},
+		{Name: "vault-secret.example.ts.net", Hostname: "vault"},
+		{Name: "shared.other.ts.net", Hostname: "shared", IsExternal: true},
+	}
+	httpsPolicy := ACLPolicy{NodeAttrs: []NodeAttr{{Target: []string{"*"}, Attr: []string{"https"}}}}
+
+	tests := []struct {
+		name      string
+		policy    ACLPolicy
+		dnsConfig *client.DNSConfig
+		want      []string
+		wantNone  bool
+	}{
+		{
+			name:      "https nodeAttr with MagicDNS",
+			policy:    httpsPolicy,
+			dnsConfig: &client.DNSConfig{MagicDNS: true},
+			want:      []string{"FQDNs published to CT logs when certificates are issued:", "web.example.ts.net", "vault-secret.example.ts.net (matches deny pattern"},
+		},
+		{
+			name:      "tailnet-level https unknown",
+			dnsConfig: &client.DNSConfig{MagicDNS: true},
+			want:      []string{"FQDNs published to CT logs if HTTPS is enabled:", "web.example.ts.net"},
+		},
+		{
+			name:      "MagicDNS disabled",
+			policy:    httpsPolicy,
+			dnsConfig: &client.DNSConfig{MagicDNS: false},
+			wantNone:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := n.checkHTTPSExposure(tt.policy, devices, tt.dnsConfig)
+			details, _ := result.Details.([]string)
+			joined := strings.Join(details, "\n")
+			if strings.Contains(joined, "shared.other.ts.net") {
+				t.Error("external devices should not be listed")
+			}
+			if tt.wantNone && strings.Contains(joined, "FQDNs") {
+				t.Errorf("expected no FQDN list, got %v", details)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(joined, want) {
+					t.Errorf("Details missing %q:\n%s", want, joined)
+				}
+			}
+		})
+	}
+}
+
+func TestServeInventoryNetworkChecks(t *testing.T) {
+	n := &NetworkAuditor{}
+
+	if result := n.checkFunnelEndpoints(ACLPolicy{}); !result.Pass {
+		t.Error("NET-001 should pass without funnel attrs or snapshots")
+	}
+
+	SetServeInventory(&types.ServeInventoryReport{
+		Nodes: []types.ServeNode{
+			{Node: "web", Endpoints: []types.ServeEndpoint{
+				{Node: "web", Protocol: "https", Host: "web.example.ts.net", Port: 443, Path: "/", Handler: "proxy", Backend: "http://127.0.0.1:3000", Funnel: true},
+			}},
+			{Node: "db-1", Endpoints: []types.ServeEndpoint{
+				{Node: "db-1", Protocol: "tcp", Port: 5432, Handler: "tcp", Backend: "127.0.0.1:5432", AdminService: "PostgreSQL"},
+			}},
+			{Node: "idle"},
+		},
+	})
+	defer SetServeInventory(nil)
+	serveInventory.CalculateSummary()
+
+	result := n.checkFunnelEndpoints(ACLPolicy{})
+	if result.Pass {
+		t.Fatal("NET-001 should fail when a snapshot shows a Funnel endpoint")
+	}
+	details, _ := result.Details.([]string)
+	if len(details) != 2 || details[0] != "Endpoints public via Funnel:" || !strings.HasPrefix(details[1], "web: https://web.example.ts.net:443/") {
+		t.Errorf("NET-001 Details = %v", details)
+	}
+
+	result = n.checkServeExposure(ACLPolicy{})
+	if result.Pass {
+		t.Fatal("NET-006 should fail when snapshots show served endpoints")
+	}
+	if !strings.Contains(result.Description, "2 endpoint(s) exposed by Serve on 2 of 3 node(s)") {
+		t.Errorf("NET-006 Description = %q", result.Description)
+	}
+	details, _ = result.Details.([]string)
+	if joined := strings.Join(details, "\n"); !strings.Contains(joined, "db-1: tcp:5432 -> 127.0.0.1:5432 [admin: PostgreSQL]") {
+		t.Errorf("NET-006 Details missing admin endpoint:\n%s", joined)
+	}
+}
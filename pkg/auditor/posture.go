@@ -0,0 +1,414 @@
+package auditor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// posturePolicy sets the posture conditions for DEV-015 to DEV-017. Nil
+// applies defaultPostureConditions to devices with sensitive tags.
+var posturePolicy *types.PosturePolicy
+
+// SetPosturePolicy configures which posture attributes devices must report
+// and the conditions they must meet. Pass nil to use the defaults.
+func SetPosturePolicy(policy *types.PosturePolicy) {
+	posturePolicy = policy
+}
+
+// defaultPostureConditions flag EDR and MDM integrations reporting a device
+// as non-compliant. Without a posture policy they apply to devices with
+// sensitive tags, and only to the attributes those devices report.
+var defaultPostureConditions = mustParsePostureConditions(
+	"falcon:ztaScore >= 50",
+	"intune:complianceState == 'compliant'",
+	"sentinelOne:infected == false",
+	"sentinelOne:activeThreats == 0",
+	"kolide:authState == 'Good'",
+)
+
+func mustParsePostureConditions(conditions ...string) []types.PostureCondition {
+	parsed := make([]types.PostureCondition, len(conditions))
+	for i, s := range conditions {
+		c, err := types.ParsePostureCondition(s)
+		if err != nil {
+			panic(err)
+		}
+		parsed[i] = c
+	}
+	return parsed
+}
+
+// postureFetchWorkers bounds concurrent posture requests; the client's rate
+// limiter still applies across them
+const postureFetchWorkers = 8
+
+// fetchDevicePosture returns the posture attributes of each device, keyed
+// by device ID, and a line for each device whose attributes couldn't be
+// read. Shared-in devices are skipped since their attributes belong to
+// another tailnet.
+func fetchDevicePosture(ctx context.Context, c *client.Client, devices []*client.Device) (map[string]*client.DevicePosture, []string) {
+	var (
+		mu          sync.Mutex
+		posture     = make(map[string]*client.DevicePosture)
+		unavailable []string
+		g           errgroup.Group
+	)
+	g.SetLimit(postureFetchWorkers)
+
+	for _, dev := range devices {
+		if dev.IsExternal {
+			continue
+		}
+		g.Go(func() error {
+			p, err := c.GetDevicePosture(ctx, dev.DeviceID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				unavailable = append(unavailable, fmt.Sprintf("%s (%s): %v", dev.Name, dev.Hostname, err))
+				return nil // Keep fetching the other devices
+			}
+			posture[dev.DeviceID] = p
+			return nil
+		})
+	}
+	g.Wait()
+
+	sort.Strings(unavailable)
+	return posture, unavailable
+}
+
+// notePostureUnavailable lists the devices whose posture couldn't be read
+// in a posture check's details. Those devices weren't checked, so a check
+// that otherwise passes becomes an informational failure.
+func notePostureUnavailable(finding types.Suggestion, unavailable []string) types.Suggestion {
+	if len(unavailable) == 0 {
+		return finding
+	}
+
+	var details []string
+	switch d := finding.Details.(type) {
+	case []string:
+		details = d
+	case string:
+		details = []string{d}
+	}
+	for _, line := range unavailable {
+		details = append(details, "Posture unavailable: "+line)
+	}
+	finding.Details = details
+
+	if finding.Pass {
+		finding.Pass = false
+		finding.Severity = types.Informational
+		finding.Description = fmt.Sprintf("Posture attributes could not be read for %d device(s), so they were not checked.", len(unavailable))
+	} else {
+		finding.Description += fmt.Sprintf(" Posture attributes could not be read for %d device(s), which were not checked.", len(unavailable))
+	}
+	return finding
+}
+
+// osVersionsFrom returns the node:osVersion posture attribute for each
+// device, keyed by device ID
+func osVersionsFrom(posture map[string]*client.DevicePosture) map[string]string {
+	versions := make(map[string]string)
+	for id, p := range posture {
+		if v, ok := p.Attributes["node:osVersion"].(string); ok && v != "" {
+			versions[id] = v
+		}
+	}
+	return versions
+}
+
+// isIntegrationAttribute reports whether an attribute was set by a posture
+// integration or the API, rather than by the Tailscale client itself
+func isIntegrationAttribute(attr string) bool {
+	namespace, _, ok := strings.Cut(attr, ":")
+	return ok && namespace != "node" && namespace != "ip"
+}
+
+// postureInUse reports whether any device reports integration or custom
+// posture attributes
+func postureInUse(posture map[string]*client.DevicePosture) bool {
+	for _, p := range posture {
+		for attr := range p.Attributes {
+			if isIntegrationAttribute(attr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// devicePostureConditions returns the conditions that apply to a device:
+// those of every matching policy rule, or the defaults for sensitive tags
+// if no policy rules are configured. fromPolicy is false for the defaults.
+func devicePostureConditions(dev *client.Device) (conditions []types.PostureCondition, fromPolicy bool) {
+	if posturePolicy != nil && len(posturePolicy.Rules) > 0 {
+		for _, rule := range posturePolicy.DeviceRules(dev.Tags, dev.OS) {
+			conditions = append(conditions, rule.ParsedConditions()...)
+		}
+		return conditions, true
+	}
+	if isSensitiveDestination(dev.Tags) {
+		return defaultPostureConditions, false
+	}
+	return nil, false
+}
+
+// missingPostureAttributes lists the attributes a device's conditions need
+// that it doesn't report. Without policy rules, a device with sensitive tags
+// that reports no integration or custom attributes at all is missing them.
+// Devices without posture data are skipped.
+func missingPostureAttributes(dev *client.Device, p *client.DevicePosture) []string {
+	if p == nil {
+		return nil
+	}
+	conditions, fromPolicy := devicePostureConditions(dev)
+	if !fromPolicy {
+		if len(conditions) == 0 {
+			return nil
+		}
+		for attr := range p.Attributes {
+			if isIntegrationAttribute(attr) {
+				return nil
+			}
+		}
+		return []string{"any EDR, MDM or custom attribute"}
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, c := range conditions {
+		if _, ok := p.Attributes[c.Attribute]; ok || !c.NeedsAttribute() || seen[c.Attribute] {
+			continue
+		}
+		seen[c.Attribute] = true
+		missing = append(missing, c.Attribute)
+	}
+	return missing
+}
+
+// stalePostureAttributes lists a device's attributes whose expiry has passed
+// or whose last-sync timestamp is older than the policy's stale threshold
+func stalePostureAttributes(p *client.DevicePosture, now time.Time) []string {
+	if p == nil {
+		return nil
+	}
+	staleDays := posturePolicy.StaleThresholdDays()
+
+	var stale []string
+	for attr, expiry := range p.Expiries {
+		if _, ok := p.Attributes[attr]; ok && !expiry.IsZero() && !now.Before(expiry) {
+			stale = append(stale, fmt.Sprintf("%s expired %s", attr, expiry.Format("2006-01-02")))
+		}
+	}
+	for attr, v := range p.Attributes {
+		if !posturePolicy.IsTimestampAttribute(attr) {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		synced, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			continue
+		}
+		if age := int(now.Sub(synced).Hours() / 24); age > staleDays {
+			stale = append(stale, fmt.Sprintf("%s %s (%d days ago)", attr, synced.Format("2006-01-02"), age))
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// failedPostureConditions lists the conditions a device fails among the
+// attributes it reports. Missing attributes are reported by DEV-015.
+func failedPostureConditions(dev *client.Device, p *client.DevicePosture) []string {
+	if p == nil {
+		return nil
+	}
+	conditions, _ := devicePostureConditions(dev)
+
+	var failed []string
+	for _, c := range conditions {
+		v, present := p.Attributes[c.Attribute]
+		if !present && c.NeedsAttribute() {
+			continue
+		}
+		if !c.Eval(v, present) {
+			if present {
+				failed = append(failed, fmt.Sprintf("%s (reported %v)", c, v))
+			} else {
+				failed = append(failed, c.String())
+			}
+		}
+	}
+	return failed
+}
+
+// postureUnavailable marks a posture check as a manual check when no
+// posture attributes could be read for any device. Returns false if there
+// is data to check.
+func postureUnavailable(finding *types.Suggestion, devices []*client.Device, posture map[string]*client.DevicePosture) bool {
+	if len(posture) > 0 {
+		return false
+	}
+	for _, dev := range devices {
+		if !dev.IsExternal {
+			finding.Pass = false
+			finding.Severity = types.Informational
+			finding.Description = "Cannot check device posture: no posture attributes were returned."
+			finding.Details = "MANUAL CHECK REQUIRED: Ensure the API key can read device posture attributes, or review posture on the Machines page."
+			return true
+		}
+	}
+	return true
+}
+
+func (d *DeviceAuditor) checkMissingPosture(devices []*client.Device, posture map[string]*client.DevicePosture) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "DEV-015",
+		Title:       "Devices missing expected posture attributes",
+		Severity:    types.Medium,
+		Category:    types.DeviceSecurity,
+		Description: "Posture rules can only restrict devices that report the attributes they test. A device not enrolled in the EDR or MDM integration is silently outside its checks.",
+		Remediation: "Enroll these devices in your EDR or MDM integration, or set the expected custom attributes through the API. Write posture conditions so that a missing attribute denies access.",
+		Source:      "https://tailscale.com/kb/1288/device-posture",
+		Pass:        true,
+	}
+
+	if postureUnavailable(&finding, devices, posture) {
+		return finding
+	}
+
+	if posturePolicy == nil && !postureInUse(posture) {
+		finding.Pass = false
+		finding.Severity = types.Informational
+		finding.Description = "No device reports EDR, MDM or custom posture attributes. Device posture integrations are not in use."
+		finding.Details = []string{
+			"MANUAL CHECK REQUIRED: Review device posture configuration if available on your plan.",
+			"",
+			"Device posture integrations:",
+			"  - CrowdStrike Falcon, SentinelOne: EDR posture",
+			"  - Intune, Jamf, Kandji: MDM compliance",
+			"  - Kolide: Cross-platform device health",
+			"  - Custom: Set posture attributes via API",
+			"",
+			"Define the attributes each device must report in a posture policy (--posture-file).",
+		}
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Configure a device posture integration in admin console",
+			AdminURL:    "https://login.tailscale.com/admin/settings/integrations",
+			DocURL:      "https://tailscale.com/kb/1288/device-posture",
+		}
+		return finding
+	}
+
+	var missingDevices []string
+	for _, dev := range devices {
+		if missing := missingPostureAttributes(dev, posture[dev.DeviceID]); len(missing) > 0 {
+			missingDevices = append(missingDevices, fmt.Sprintf("%s (%s) - tags: %v, missing: %s", dev.Name, dev.Hostname, dev.Tags, strings.Join(missing, ", ")))
+		}
+	}
+
+	if len(missingDevices) > 0 {
+		finding.Pass = false
+		finding.Details = missingDevices
+		finding.Description = fmt.Sprintf("Found %d device(s) not reporting the posture attributes expected of them.", len(missingDevices))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Enroll these devices in the posture integration or set their custom attributes",
+			AdminURL:    "https://login.tailscale.com/admin/machines",
+			DocURL:      "https://tailscale.com/kb/1288/device-posture",
+		}
+	}
+
+	return finding
+}
+
+func (d *DeviceAuditor) checkStalePosture(devices []*client.Device, posture map[string]*client.DevicePosture, now time.Time) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "DEV-016",
+		Title:       "Stale device posture data",
+		Severity:    types.Medium,
+		Category:    types.DeviceSecurity,
+		Description: "Posture attributes are only as current as their last sync. A device that stopped checking in with its EDR or MDM keeps the last compliant result.",
+		Remediation: "Check the integration's sync status and the agent on these devices. Set expiries on custom attributes so that they lapse when not refreshed.",
+		Source:      "https://tailscale.com/kb/1288/device-posture",
+		Pass:        true,
+	}
+
+	if postureUnavailable(&finding, devices, posture) {
+		return finding
+	}
+
+	var staleDevices []string
+	for _, dev := range devices {
+		if stale := stalePostureAttributes(posture[dev.DeviceID], now); len(stale) > 0 {
+			staleDevices = append(staleDevices, fmt.Sprintf("%s (%s): %s", dev.Name, dev.Hostname, strings.Join(stale, ", ")))
+		}
+	}
+
+	if len(staleDevices) > 0 {
+		finding.Pass = false
+		finding.Details = staleDevices
+		finding.Description = fmt.Sprintf("Found %d device(s) with posture data that expired or was last synced more than %d days ago.", len(staleDevices), posturePolicy.StaleThresholdDays())
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Restore the posture integration sync or the agent on these devices",
+			AdminURL:    "https://login.tailscale.com/admin/settings/integrations",
+			DocURL:      "https://tailscale.com/kb/1288/device-posture",
+		}
+	}
+
+	return finding
+}
+
+func (d *DeviceAuditor) checkFailedPosture(devices []*client.Device, posture map[string]*client.DevicePosture) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "DEV-017",
+		Title:       "Devices failing posture conditions",
+		Severity:    types.High,
+		Category:    types.DeviceSecurity,
+		Description: "Devices whose EDR or compliance attributes report them as non-compliant are likely compromised or unmanaged. On sensitive infrastructure they put every resource they can reach at risk.",
+		Remediation: "Investigate these devices with the EDR or MDM. Add the same conditions to postures in the tailnet policy file so non-compliant devices lose access automatically.",
+		Source:      "https://tailscale.com/kb/1288/device-posture",
+		Pass:        true,
+	}
+
+	if postureUnavailable(&finding, devices, posture) {
+		return finding
+	}
+
+	var failedDevices []string
+	for _, dev := range devices {
+		if failed := failedPostureConditions(dev, posture[dev.DeviceID]); len(failed) > 0 {
+			failedDevices = append(failedDevices, fmt.Sprintf("%s (%s) - tags: %v, fails: %s", dev.Name, dev.Hostname, dev.Tags, strings.Join(failed, "; ")))
+		}
+	}
+
+	if len(failedDevices) > 0 {
+		finding.Pass = false
+		finding.Details = failedDevices
+		finding.Description = fmt.Sprintf("Found %d device(s) whose posture attributes fail the conditions required of them.", len(failedDevices))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Remediate these devices and enforce the conditions with postures in the policy file",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1288/device-posture",
+		}
+	}
+
+	return finding
+}
@@ -0,0 +1,155 @@
+package auditor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func postureFixture() ([]*client.Device, map[string]*client.DevicePosture) {
+	devices := []*client.Device{
+		{DeviceID: "1", Name: "db-1.example.ts.net", Hostname: "db-1", OS: "linux", Tags: []string{"tag:prod-db"}},
+		{DeviceID: "2", Name: "db-2.example.ts.net", Hostname: "db-2", OS: "linux", Tags: []string{"tag:prod-db"}},
+		{DeviceID: "3", Name: "web-1.example.ts.net", Hostname: "web-1", OS: "linux", Tags: []string{"tag:web"}},
+		{DeviceID: "4", Name: "alice-mbp.example.ts.net", Hostname: "alice-mbp", OS: "macOS", User: "alice@example.com"},
+		{DeviceID: "5", Name: "shared.other.ts.net", Hostname: "shared", IsExternal: true},
+	}
+	posture := map[string]*client.DevicePosture{
+		"1": {Attributes: map[string]interface{}{
+			"node:os":                 "linux",
+			"falcon:ztaScore":         float64(31),
+			"intune:lastSyncDateTime": "2026-09-01T00:00:00Z",
+		}},
+		"2": {Attributes: map[string]interface{}{"node:os": "linux"}},
+		"3": {Attributes: map[string]interface{}{"node:os": "linux"}},
+		"4": {
+			Attributes: map[string]interface{}{"node:os": "macos", "custom:mdmEnrolled": true},
+			Expiries:   map[string]time.Time{"custom:mdmEnrolled": time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	return devices, posture
+}
+
+func TestPostureChecksDefaults(t *testing.T) {
+	d := &DeviceAuditor{}
+	devices, posture := postureFixture()
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	result := d.checkMissingPosture(devices, posture)
+	details, _ := result.Details.([]string)
+	if result.Pass || len(details) != 1 || !strings.HasPrefix(details[0], "db-2.example.ts.net") {
+		t.Errorf("DEV-015 should flag only the sensitive device without EDR attributes, got %v", details)
+	}
+
+	result = d.checkStalePosture(devices, posture, now)
+	details, _ = result.Details.([]string)
+	joined := strings.Join(details, "\n")
+	if result.Pass || len(details) != 2 ||
+		!strings.Contains(joined, "intune:lastSyncDateTime 2026-09-01 (47 days ago)") ||
+		!strings.Contains(joined, "custom:mdmEnrolled expired 2026-10-01") {
+		t.Errorf("DEV-016 Details =\n%s", joined)
+	}
+
+	result = d.checkFailedPosture(devices, posture)
+	details, _ = result.Details.([]string)
+	if result.Pass || result.Severity != types.High || len(details) != 1 || !strings.Contains(details[0], "falcon:ztaScore >= 50 (reported 31)") {
+		t.Errorf("DEV-017 Details = %v", details)
+	}
+}
+
+func TestPostureChecksPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posture")
+	policy := `{"rules": [
+		{"name": "prod", "tags": ["tag:prod-db"], "conditions": ["falcon:ztaScore >= 30", "custom:quarantined NOT SET"]},
+		{"name": "laptops", "os": ["macOS"], "conditions": ["custom:mdmEnrolled == true", "kolide:authState == 'Good'"]},
+	]}`
+	if err := os.WriteFile(path, []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := types.LoadPostureFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetPosturePolicy(p)
+	defer SetPosturePolicy(nil)
+
+	d := &DeviceAuditor{}
+	devices, posture := postureFixture()
+
+	result := d.checkMissingPosture(devices, posture)
+	joined := strings.Join(result.Details.([]string), "\n")
+	for _, want := range []string{"db-2.example.ts.net", "missing: falcon:ztaScore", "alice-mbp.example.ts.net", "missing: kolide:authState"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("DEV-015 Details missing %q:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "web-1") || strings.Contains(joined, "quarantined") {
+		t.Errorf("DEV-015 should skip unmatched devices and NOT SET conditions:\n%s", joined)
+	}
+
+	// db-1 meets the policy's lower threshold
+	if result := d.checkFailedPosture(devices, posture); !result.Pass {
+		t.Errorf("DEV-017 should pass, got %v", result.Details)
+	}
+
+	posture["1"].Attributes["custom:quarantined"] = true
+	result = d.checkFailedPosture(devices, posture)
+	if result.Pass || !strings.Contains(strings.Join(result.Details.([]string), "\n"), "custom:quarantined NOT SET (reported true)") {
+		t.Errorf("DEV-017 should flag the quarantined device, got %v", result.Details)
+	}
+}
+
+func TestPostureChecksNoData(t *testing.T) {
+	d := &DeviceAuditor{}
+	devices, posture := postureFixture()
+
+	if result := d.checkFailedPosture(devices, nil); result.Pass || result.Severity != types.Informational {
+		t.Errorf("DEV-017 without posture data: Pass = %v, Severity = %v, want informational failure", result.Pass, result.Severity)
+	}
+
+	// No integration attributes anywhere and no policy: posture isn't in use
+	for _, p := range posture {
+		for attr := range p.Attributes {
+			if isIntegrationAttribute(attr) {
+				delete(p.Attributes, attr)
+			}
+		}
+	}
+	result := d.checkMissingPosture(devices, posture)
+	if result.Pass || result.Severity != types.Informational || !strings.Contains(result.Description, "not in use") {
+		t.Errorf("DEV-015 without integrations: Pass = %v, Severity = %v, %q", result.Pass, result.Severity, result.Description)
+	}
+}
+
+func TestNotePostureUnavailable(t *testing.T) {
+	d := &DeviceAuditor{}
+	devices, posture := postureFixture()
+	delete(posture, "2")
+	unavailable := []string{"db-2.example.ts.net (db-2): rate limited"}
+
+	// A check that passes on the devices it could read no longer passes
+	result := notePostureUnavailable(d.checkStalePosture(devices[1:3], posture, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)), unavailable)
+	if result.Pass || result.Severity != types.Informational {
+		t.Errorf("DEV-016 with unreadable posture: Pass = %v, Severity = %v, want informational failure", result.Pass, result.Severity)
+	}
+	details, _ := result.Details.([]string)
+	if len(details) != 1 || details[0] != "Posture unavailable: db-2.example.ts.net (db-2): rate limited" {
+		t.Errorf("Details = %v, want the unavailable device", details)
+	}
+
+	// A failing check keeps its severity and lists the device after its own details
+	result = notePostureUnavailable(d.checkFailedPosture(devices, posture), unavailable)
+	details, _ = result.Details.([]string)
+	if result.Pass || result.Severity != types.High || !strings.HasPrefix(details[len(details)-1], "Posture unavailable: db-2") {
+		t.Errorf("DEV-017 with unreadable posture: Pass = %v, Severity = %v, Details = %v", result.Pass, result.Severity, details)
+	}
+
+	if result := notePostureUnavailable(types.Suggestion{Pass: true}, nil); !result.Pass {
+		t.Error("no unavailable devices should leave the finding unchanged")
+	}
+}
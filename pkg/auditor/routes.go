@@ -0,0 +1,162 @@
+package auditor
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+)
+
+// routeAvailability is the result of analyzing subnet routes for failover
+type routeAvailability struct {
+	SingleRouter []string // enabled routes with exactly one router
+	AllStale     []string // enabled routes whose routers are all offline
+	Overlapping  []string // overlapping routes from routers with different tags
+	Broad        []string // routes covering a /8 or more
+}
+
+// subnetRoute is one route a device advertises
+type subnetRoute struct {
+	prefix netip.Prefix
+	dev    *client.Device
+}
+
+// isExitRoute reports whether a route is part of exit node advertisement
+func isExitRoute(route string) bool {
+	return route == "0.0.0.0/0" || route == "::/0"
+}
+
+// deviceLabel names a device with its tags for route reports
+func deviceLabel(dev *client.Device) string {
+	if len(dev.Tags) == 0 {
+		return fmt.Sprintf("%s (untagged)", dev.Name)
+	}
+	return fmt.Sprintf("%s [%s]", dev.Name, strings.Join(dev.Tags, ","))
+}
+
+// parseRoutes parses a device's routes, skipping exit node routes
+func parseRoutes(dev *client.Device, routes []string) []subnetRoute {
+	var parsed []subnetRoute
+	for _, r := range routes {
+		if isExitRoute(r) {
+			continue
+		}
+		if p, err := netip.ParsePrefix(r); err == nil {
+			parsed = append(parsed, subnetRoute{prefix: p.Masked(), dev: dev})
+		}
+	}
+	return parsed
+}
+
+// analyzeSubnetRoutes checks enabled subnet routes for single points of
+// failure and advertised routes for overlaps and overly broad prefixes.
+// A route counts as served by every router with an enabled route that
+// contains it, so a /16 router backs up a /24 router.
+func analyzeSubnetRoutes(devices []*client.Device, now time.Time) routeAvailability {
+	var result routeAvailability
+	var enabled, advertised []subnetRoute
+
+	for _, dev := range devices {
+		// Shared-in devices route for their home tailnet
+		if dev.IsExternal {
+			continue
+		}
+		enabled = append(enabled, parseRoutes(dev, dev.EnabledRoutes)...)
+		advertised = append(advertised, parseRoutes(dev, dev.AdvertisedRoutes)...)
+
+		// 0.0.0.0/0 without ::/0 (or vice versa) was advertised as a subnet
+		// route, not via --advertise-exit-node
+		hasV4, hasV6 := containsExact(dev.AdvertisedRoutes, "0.0.0.0/0"), containsExact(dev.AdvertisedRoutes, "::/0")
+		if hasV4 != hasV6 {
+			route := "0.0.0.0/0"
+			if hasV6 {
+				route = "::/0"
+			}
+			result.Broad = append(result.Broad, fmt.Sprintf("%s: %s advertised as a subnet route", deviceLabel(dev), route))
+		}
+	}
+
+	for _, r := range advertised {
+		if isBroadPrefix(r.prefix.String()) {
+			result.Broad = append(result.Broad, fmt.Sprintf("%s: %s", deviceLabel(r.dev), r.prefix))
+		}
+	}
+
+	// Failover: for each distinct enabled prefix, find every router that serves it
+	seen := make(map[netip.Prefix]bool)
+	for _, r := range enabled {
+		if seen[r.prefix] {
+			continue
+		}
+		seen[r.prefix] = true
+
+		var routers []*client.Device
+		for _, other := range enabled {
+			if other.prefix.Bits() <= r.prefix.Bits() && other.prefix.Contains(r.prefix.Addr()) && !containsDevice(routers, other.dev) {
+				routers = append(routers, other.dev)
+			}
+		}
+
+		if len(routers) == 1 {
+			result.SingleRouter = append(result.SingleRouter, fmt.Sprintf("%s: only %s", r.prefix, deviceLabel(routers[0])))
+		}
+
+		allStale := true
+		var offline []string
+		for _, dev := range routers {
+			lastSeen, err := time.Parse(time.RFC3339, dev.LastSeen)
+			if err != nil || now.Sub(lastSeen) <= infraMaxOffline {
+				allStale = false
+				break
+			}
+			offline = append(offline, fmt.Sprintf("%s last seen %d days ago", dev.Name, int(now.Sub(lastSeen).Hours()/24)))
+		}
+		if allStale {
+			result.AllStale = append(result.AllStale, fmt.Sprintf("%s: %s", r.prefix, strings.Join(offline, ", ")))
+		}
+	}
+
+	// Overlaps between routers in different tag groups
+	reported := make(map[string]bool)
+	for i, a := range advertised {
+		for _, b := range advertised[i+1:] {
+			if a.dev == b.dev || !a.prefix.Overlaps(b.prefix) || sameTags(a.dev.Tags, b.dev.Tags) {
+				continue
+			}
+			line := fmt.Sprintf("%s from %s overlaps %s from %s", a.prefix, deviceLabel(a.dev), b.prefix, deviceLabel(b.dev))
+			if !reported[line] {
+				reported[line] = true
+				result.Overlapping = append(result.Overlapping, line)
+			}
+		}
+	}
+
+	sort.Strings(result.SingleRouter)
+	sort.Strings(result.AllStale)
+	return result
+}
+
+func containsDevice(devices []*client.Device, dev *client.Device) bool {
+	for _, d := range devices {
+		if d == dev {
+			return true
+		}
+	}
+	return false
+}
+
+// sameTags reports whether two devices carry the same set of tags
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, tag := range a {
+		if !containsExact(b, tag) {
+			return false
+		}
+	}
+	return true
+}
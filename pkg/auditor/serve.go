@@ -0,0 +1,385 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tailscale.com/ipn"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// serveInventory holds Serve snapshots loaded with --serve-dir, for NET-001
+// and NET-006. Nil means only the policy's nodeAttrs are checked.
+var serveInventory *types.ServeInventoryReport
+
+// SetServeInventory provides the Serve and Funnel endpoints actually
+// configured on nodes to the network checks. Pass nil to check nodeAttrs only.
+func SetServeInventory(inv *types.ServeInventoryReport) {
+	serveInventory = inv
+}
+
+// adminPorts name services that should rarely sit behind Serve, let alone Funnel
+var adminPorts = map[string]string{
+	"22":    "SSH",
+	"2375":  "Docker API",
+	"2376":  "Docker API",
+	"2379":  "etcd",
+	"3306":  "MySQL",
+	"3389":  "RDP",
+	"5432":  "PostgreSQL",
+	"5900":  "VNC",
+	"6379":  "Redis",
+	"6443":  "Kubernetes API",
+	"8200":  "Vault",
+	"8500":  "Consul",
+	"9090":  "Prometheus",
+	"9200":  "Elasticsearch",
+	"10250": "kubelet",
+	"15672": "RabbitMQ management",
+	"27017": "MongoDB",
+}
+
+// adminPaths are path prefixes of admin and debug interfaces
+var adminPaths = []string{"/admin", "/debug", "/metrics", "/actuator", "/phpmyadmin", "/wp-admin"}
+
+// inventoryEndpoints lists each port and path a Serve configuration exposes,
+// including foreground sessions, with the backend details the inventory
+// reports
+func inventoryEndpoints(node string, sc *ipn.ServeConfig) []types.ServeEndpoint {
+	if sc == nil {
+		return nil
+	}
+
+	var endpoints []types.ServeEndpoint
+
+	ports := make([]int, 0, len(sc.TCP))
+	for port := range sc.TCP {
+		ports = append(ports, int(port))
+	}
+	sort.Ints(ports)
+
+	for _, port := range ports {
+		h := sc.TCP[uint16(port)]
+		if h == nil || h.TCPForward == "" {
+			continue // HTTP(S) ports are described by their web handlers
+		}
+		e := types.ServeEndpoint{
+			Node:         node,
+			Protocol:     "tcp",
+			Port:         uint16(port),
+			Handler:      "tcp",
+			Backend:      h.TCPForward,
+			TerminateTLS: h.TerminateTLS,
+			Funnel:       portFunneled(sc, uint16(port)),
+		}
+		host, backendPort, _ := net.SplitHostPort(h.TCPForward)
+		// Without TLS termination the client's own encryption, if any, reaches
+		// the backend; with it, tailscaled forwards the decrypted stream
+		e.PlaintextBackend = h.TerminateTLS != "" && !isLoopbackHost(host)
+		e.AdminService = adminPorts[backendPort]
+		endpoints = append(endpoints, e)
+	}
+
+	hostPorts := make([]string, 0, len(sc.Web))
+	for hp := range sc.Web {
+		hostPorts = append(hostPorts, string(hp))
+	}
+	sort.Strings(hostPorts)
+
+	for _, hp := range hostPorts {
+		web := sc.Web[ipn.HostPort(hp)]
+		if web == nil {
+			continue
+		}
+		host, portStr, err := net.SplitHostPort(hp)
+		if err != nil {
+			continue
+		}
+		port, _ := strconv.ParseUint(portStr, 10, 16)
+		protocol := "https"
+		if h := sc.TCP[uint16(port)]; h != nil && h.HTTP {
+			protocol = "http"
+		}
+
+		mounts := make([]string, 0, len(web.Handlers))
+		for mount := range web.Handlers {
+			mounts = append(mounts, mount)
+		}
+		sort.Strings(mounts)
+
+		for _, mount := range mounts {
+			h := web.Handlers[mount]
+			if h == nil {
+				continue
+			}
+			e := types.ServeEndpoint{
+				Node:     node,
+				Protocol: protocol,
+				Host:     host,
+				Port:     uint16(port),
+				Path:     mount,
+				Funnel:   sc.AllowFunnel[ipn.HostPort(hp)],
+			}
+			switch {
+			case h.Proxy != "":
+				e.Handler = "proxy"
+				e.Backend = h.Proxy
+				scheme, backendHost, backendPort, backendPath := parseProxyTarget(h.Proxy)
+				e.PlaintextBackend = scheme == "http" && !isLoopbackHost(backendHost)
+				e.AdminService = adminPorts[backendPort]
+				if e.AdminService == "" {
+					e.AdminService = adminPath(mount, backendPath)
+				}
+			case h.Path != "":
+				e.Handler = "path"
+				e.Backend = h.Path
+				e.AdminService = adminPath(mount, "")
+			default:
+				e.Handler = "text"
+			}
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	sessions := make([]string, 0, len(sc.Foreground))
+	for id := range sc.Foreground {
+		sessions = append(sessions, id)
+	}
+	sort.Strings(sessions)
+
+	for _, id := range sessions {
+		for _, e := range inventoryEndpoints(node, sc.Foreground[id]) {
+			e.Foreground = true
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	return endpoints
+}
+
+// parseProxyTarget splits a Serve proxy target into its parts. Targets may be
+// a bare port ("3000"), host:port or a URL, as accepted by `tailscale serve`;
+// the first two mean plain HTTP.
+func parseProxyTarget(target string) (scheme, host, port, path string) {
+	if _, err := strconv.ParseUint(target, 10, 16); err == nil {
+		return "http", "127.0.0.1", target, ""
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", "", "", ""
+	}
+	scheme = u.Scheme
+	port = u.Port()
+	if port == "" {
+		switch scheme {
+		case "http":
+			port = "80"
+		case "https", "https+insecure":
+			port = "443"
+		}
+	}
+	return scheme, u.Hostname(), port, u.Path
+}
+
+// isLoopbackHost reports whether a backend host is on the serving node itself
+func isLoopbackHost(host string) bool {
+	if host == "" || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip, err := netip.ParseAddr(host)
+	return err == nil && ip.IsLoopback()
+}
+
+// adminPath returns a description if a mount point or backend path looks
+// like an admin or debug interface
+func adminPath(paths ...string) string {
+	for _, p := range paths {
+		lower := strings.ToLower(p)
+		for _, prefix := range adminPaths {
+			if lower == prefix || strings.HasPrefix(lower, prefix+"/") {
+				return "path " + prefix
+			}
+		}
+	}
+	return ""
+}
+
+// LoadServeSnapshots reads the Serve configuration of many nodes from a
+// directory of .json files. Each file is either a snapshot written by
+// `tailsnitch serve --snapshot` or the output of `tailscale serve status
+// --json`, in which case the file name (without .json) names the node.
+// Files that cannot be parsed, snapshots without a node name or Serve
+// config, and other JSON files are recorded in the report's Errors.
+func LoadServeSnapshots(dir string) (*types.ServeInventoryReport, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.ServeInventoryReport{GeneratedAt: time.Now()}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		node, err := loadServeSnapshot(path)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		report.Nodes = append(report.Nodes, node)
+	}
+
+	report.CalculateSummary()
+	return report, nil
+}
+
+func loadServeSnapshot(path string) (types.ServeNode, error) {
+	node := types.ServeNode{
+		Node:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Source: path,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return node, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return node, err
+	}
+
+	config := data
+	_, hasNode := fields["node"]
+	_, hasConfig := fields["serve_config"]
+	if hasNode || hasConfig {
+		var snap types.ServeSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return node, err
+		}
+		if snap.Node == "" {
+			return node, fmt.Errorf("snapshot has no node name")
+		}
+		if len(snap.ServeConfig) == 0 || string(snap.ServeConfig) == "null" {
+			return node, fmt.Errorf("snapshot has no serve_config")
+		}
+		node.Node = snap.Node
+		if !snap.CollectedAt.IsZero() {
+			node.CollectedAt = &snap.CollectedAt
+		}
+		config = snap.ServeConfig
+	}
+
+	// A Serve config is empty or has at least one Serve field; anything else
+	// is another JSON file in the same directory. Unknown fields alone are
+	// allowed, since newer clients add them.
+	var configFields map[string]json.RawMessage
+	if err := json.Unmarshal(config, &configFields); err != nil {
+		return node, fmt.Errorf("invalid serve config: %w", err)
+	}
+	if !isServeConfig(configFields) {
+		return node, fmt.Errorf("not a Serve config or snapshot")
+	}
+	var sc ipn.ServeConfig
+	if err := json.Unmarshal(config, &sc); err != nil {
+		return node, fmt.Errorf("invalid serve config: %w", err)
+	}
+	node.Endpoints = inventoryEndpoints(node.Node, &sc)
+	return node, nil
+}
+
+// serveConfigFields are the top-level fields of `tailscale serve status --json`
+var serveConfigFields = []string{"TCP", "Web", "AllowFunnel", "Foreground", "Services"}
+
+// isServeConfig reports whether a decoded JSON object looks like a Serve config
+func isServeConfig(fields map[string]json.RawMessage) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, f := range serveConfigFields {
+		if _, ok := fields[f]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot returns the local node's Serve configuration in the snapshot
+// format read by LoadServeSnapshots
+func (l *LocalAuditor) Snapshot(ctx context.Context) (*types.ServeSnapshot, error) {
+	st, err := l.lc.StatusWithoutPeers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach tailscaled: %w", err)
+	}
+	sc, err := l.lc.GetServeConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get serve config: %w", err)
+	}
+	if sc == nil {
+		sc = &ipn.ServeConfig{}
+	}
+	config, err := json.Marshal(sc)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ServeSnapshot{
+		Node:        selfDevice(st).Name,
+		CollectedAt: time.Now(),
+		ServeConfig: config,
+	}, nil
+}
+
+// ServeInventory returns the Serve and Funnel endpoints of the local node
+func (l *LocalAuditor) ServeInventory(ctx context.Context) (*types.ServeInventoryReport, error) {
+	snap, err := l.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sc ipn.ServeConfig
+	if err := json.Unmarshal(snap.ServeConfig, &sc); err != nil {
+		return nil, err
+	}
+
+	report := &types.ServeInventoryReport{
+		GeneratedAt: snap.CollectedAt,
+		Nodes: []types.ServeNode{{
+			Node:        snap.Node,
+			Source:      "LocalAPI",
+			CollectedAt: &snap.CollectedAt,
+			Endpoints:   inventoryEndpoints(snap.Node, &sc),
+		}},
+	}
+	report.CalculateSummary()
+	return report, nil
+}
+
+// serveInventoryDetails lists endpoints from loaded snapshots that match
+// keep, prefixed with their node, or nil if no snapshots were loaded
+func serveInventoryDetails(keep func(types.ServeEndpoint) bool) []string {
+	if serveInventory == nil {
+		return nil
+	}
+	var details []string
+	for _, e := range serveInventory.Endpoints() {
+		if keep(e) {
+			details = append(details, fmt.Sprintf("%s: %s", e.Node, e))
+		}
+	}
+	return details
+}
@@ -0,0 +1,139 @@
+package auditor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tailscale.com/ipn"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestServeEndpoints(t *testing.T) {
+	sc := &ipn.ServeConfig{
+		TCP: map[uint16]*ipn.TCPPortHandler{
+			443:  {HTTPS: true},
+			80:   {HTTP: true},
+			8443: {TCPForward: "10.0.0.7:8080", TerminateTLS: "web.example.ts.net"},
+			2222: {TCPForward: "127.0.0.1:22", TerminateTLS: "web.example.ts.net"},
+		},
+		Web: map[ipn.HostPort]*ipn.WebServerConfig{
+			"web.example.ts.net:443": {Handlers: map[string]*ipn.HTTPHandler{
+				"/":      {Proxy: "3000"},
+				"/admin": {Proxy: "https://127.0.0.1:8443"},
+			}},
+			"web.example.ts.net:80": {Handlers: map[string]*ipn.HTTPHandler{
+				"/grafana": {Proxy: "http://10.0.0.5:3000"},
+			}},
+		},
+		AllowFunnel: map[ipn.HostPort]bool{"web.example.ts.net:443": true},
+	}
+
+	got := make(map[string]types.ServeEndpoint)
+	for _, e := range inventoryEndpoints("web", sc) {
+		got[e.URL()] = e
+	}
+
+	tests := []struct {
+		url       string
+		funnel    bool
+		plaintext bool
+		admin     string
+	}{
+		{url: "tcp:2222", admin: "SSH"},
+		{url: "tcp:8443", plaintext: true},
+		{url: "http://web.example.ts.net:80/grafana", plaintext: true},
+		{url: "https://web.example.ts.net:443/", funnel: true},
+		{url: "https://web.example.ts.net:443/admin", funnel: true, admin: "path /admin"},
+	}
+
+	if len(got) != len(tests) {
+		t.Fatalf("got %d endpoints, want %d: %v", len(got), len(tests), got)
+	}
+	for _, tt := range tests {
+		e, ok := got[tt.url]
+		if !ok {
+			t.Errorf("missing endpoint %s", tt.url)
+			continue
+		}
+		if e.Funnel != tt.funnel || e.PlaintextBackend != tt.plaintext || e.AdminService != tt.admin {
+			t.Errorf("%s: Funnel = %v, PlaintextBackend = %v, AdminService = %q, want %v, %v, %q",
+				tt.url, e.Funnel, e.PlaintextBackend, e.AdminService, tt.funnel, tt.plaintext, tt.admin)
+		}
+	}
+}
+
+func TestParseProxyTarget(t *testing.T) {
+	tests := []struct {
+		target                   string
+		scheme, host, port, path string
+	}{
+		{"3000", "http", "127.0.0.1", "3000", ""},
+		{"localhost:8080", "http", "localhost", "8080", ""},
+		{"https+insecure://10.0.0.1/ui", "https+insecure", "10.0.0.1", "443", "/ui"},
+	}
+	for _, tt := range tests {
+		scheme, host, port, path := parseProxyTarget(tt.target)
+		if scheme != tt.scheme || host != tt.host || port != tt.port || path != tt.path {
+			t.Errorf("parseProxyTarget(%q) = %q, %q, %q, %q, want %q, %q, %q, %q",
+				tt.target, scheme, host, port, path, tt.scheme, tt.host, tt.port, tt.path)
+		}
+	}
+}
+
+func TestLoadServeSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		// Written by tailsnitch serve --snapshot
+		"a.json": `{"node": "db-1.example.ts.net", "collected_at": "2026-10-01T00:00:00Z",
+			"serve_config": {"TCP": {"5432": {"TCPForward": "127.0.0.1:5432"}}}}`,
+		// Output of tailscale serve status --json, named after the node
+		"web.example.ts.net.json": `{"TCP": {"443": {"HTTPS": true}},
+			"Web": {"web.example.ts.net:443": {"Handlers": {"/": {"Proxy": "http://127.0.0.1:3000"}}}},
+			"AllowFunnel": {"web.example.ts.net:443": true}}`,
+		"idle.json": `{}`,
+		"bad.json":  `{"TCP": "nope"}`,
+		"notes.txt": `not a snapshot`,
+		// Rejected: snapshots missing a node or config, and unrelated JSON
+		"nonode.json":   `{"collected_at": "2026-10-01T00:00:00Z", "serve_config": {}}`,
+		"noconfig.json": `{"node": "db-2.example.ts.net"}`,
+		"package.json":  `{"name": "app", "version": "1.0.0"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := LoadServeSnapshots(dir)
+	if err != nil {
+		t.Fatalf("LoadServeSnapshots() error = %v", err)
+	}
+
+	rejected := []string{"bad.json", "noconfig.json", "nonode.json", "package.json"}
+	if len(report.Errors) != len(rejected) {
+		t.Fatalf("Errors = %v, want one error each for %v", report.Errors, rejected)
+	}
+	for i, name := range rejected {
+		if !strings.Contains(report.Errors[i], name) {
+			t.Errorf("Errors[%d] = %q, want error for %s", i, report.Errors[i], name)
+		}
+	}
+	want := types.ServeInventorySummary{Nodes: 3, ExposingNodes: 2, Endpoints: 2, Funnel: 1, AdminBackends: 1}
+	if report.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", report.Summary, want)
+	}
+
+	nodes := make(map[string]types.ServeNode)
+	for _, n := range report.Nodes {
+		nodes[n.Node] = n
+	}
+	if n, ok := nodes["db-1.example.ts.net"]; !ok || n.CollectedAt == nil {
+		t.Errorf("snapshot envelope should name the node and carry its collection time, got %+v", nodes)
+	}
+	if n := nodes["web.example.ts.net"]; len(n.Endpoints) != 1 || !n.Endpoints[0].Funnel {
+		t.Errorf("raw serve status should be named after its file, got %+v", n)
+	}
+}
@@ -18,13 +18,15 @@ import (
 // SOC2Collector gathers evidence for SOC 2 audit reports
 type SOC2Collector struct {
 	client   *client.Client
+	clock    Clock
 	registry *types.CheckRegistry
 }
 
 // NewSOC2Collector creates a new SOC2 evidence collector
-func NewSOC2Collector(c *client.Client) *SOC2Collector {
+func NewSOC2Collector(c *client.Client, clock Clock) *SOC2Collector {
 	return &SOC2Collector{
 		client:   c,
+		clock:    clock,
 		registry: types.DefaultRegistry,
 	}
 }
@@ -37,7 +39,8 @@ func (c *SOC2Collector) Collect(ctx context.Context) (*types.SOC2Report, error)
 		Tests:       []types.SOC2ControlTest{},
 	}
 
-	now := time.Now()
+	now := c.clock.Now()
+	report.AsOf = c.clock.asOfPtr()
 
 	// Fetch devices
 	devices, err := c.client.GetDevices(ctx)
@@ -49,31 +52,7 @@ func (c *SOC2Collector) Collect(ctx context.Context) (*types.SOC2Report, error)
 	var keys []keyInfo
 	keyIDs, err := c.client.GetKeys(ctx)
 	if err == nil {
-		for _, id := range keyIDs {
-			key, err := c.client.GetKey(ctx, id)
-			if err != nil {
-				continue
-			}
-			info := keyInfo{
-				ID:      key.ID,
-				Created: key.Created,
-				Expires: key.Expires,
-			}
-			if !key.Expires.IsZero() {
-				info.DaysToExpiry = int(time.Until(key.Expires).Hours() / 24)
-			}
-			if key.Capabilities.Devices.Create.Reusable {
-				info.Reusable = true
-			}
-			if key.Capabilities.Devices.Create.Preauthorized {
-				info.Preauthorized = true
-			}
-			if key.Capabilities.Devices.Create.Ephemeral {
-				info.Ephemeral = true
-			}
-			info.Tags = key.Capabilities.Devices.Create.Tags
-			keys = append(keys, info)
-		}
+		keys = fetchKeyInfo(ctx, c.client, keyIDs, now)
 	}
 
 	// Fetch ACL policy
@@ -108,7 +87,6 @@ func (c *SOC2Collector) Collect(ctx context.Context) (*types.SOC2Report, error)
 func (c *SOC2Collector) evaluateDevices(devices []*client.Device, now time.Time) []types.SOC2ControlTest {
 	var tests []types.SOC2ControlTest
 
-	versionRegex := regexp.MustCompile(`v?(\d+)\.(\d+)`)
 	sensitivePatterns := []string{
 		"prod", "production", "staging", "dev", "database", "db",
 		"api", "admin", "internal", "secret", "vault", "key",
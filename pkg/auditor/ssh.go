@@ -3,7 +3,10 @@ package auditor
 import (
 	"context"
 	"fmt"
+	"net/netip"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/Adversis/tailsnitch/pkg/client"
 	"github.com/Adversis/tailsnitch/pkg/types"
@@ -12,15 +15,16 @@ import (
 // SSHAuditor checks for SSH and device security issues
 type SSHAuditor struct {
 	client *client.Client
+	clock  Clock
 }
 
 // NewSSHAuditor creates a new SSH auditor
-func NewSSHAuditor(c *client.Client) *SSHAuditor {
-	return &SSHAuditor{client: c}
+func NewSSHAuditor(c *client.Client, clock Clock) *SSHAuditor {
+	return &SSHAuditor{client: c, clock: clock}
 }
 
 // Audit performs SSH security checks
-func (s *SSHAuditor) Audit(ctx context.Context, policy ACLPolicy) ([]types.Suggestion, error) {
+func (s *SSHAuditor) Audit(ctx context.Context, policy ACLPolicy, data *TailnetData) ([]types.Suggestion, error) {
 	var findings []types.Suggestion
 
 	// SSH-001: Check session recording enforcement
@@ -35,6 +39,35 @@ func (s *SSHAuditor) Audit(ctx context.Context, policy ACLPolicy) ([]types.Sugge
 	// SSH-004: Check for SSH rules overall
 	findings = append(findings, s.checkSSHRulesExist(policy))
 
+	if data.DevicesErr != nil {
+		// SSH-005 and SSH-006 expand rules against devices; without them,
+		// only the policy-only checks run
+		findings = append(findings,
+			manualCheck(effectiveRootFinding(), fmt.Sprintf("Cannot read devices: %v", data.DevicesErr),
+				"Export the SSH access matrix with --ssh-access once devices can be read, or review which users can log in as root on the Machines page."),
+			manualCheck(recorderHealthFinding(), fmt.Sprintf("Cannot read devices: %v", data.DevicesErr),
+				"Confirm each recorder tag in the SSH rules has online, up-to-date nodes on the Machines page."),
+		)
+	} else {
+		// SSH-005: Check effective root access across users and devices
+		findings = append(findings, s.checkEffectiveRootAccess(policy, data.Devices))
+
+		// SSH-006: Check that session recorders exist and are healthy
+		findings = append(findings, s.checkRecorderHealth(policy, data.Devices))
+	}
+
+	// SSH-007: Check for long check mode periods on high-risk rules
+	findings = append(findings, s.checkLongCheckPeriods(policy))
+
+	// SSH-008: Check for acceptEnv patterns allowing dangerous variables
+	findings = append(findings, s.checkAcceptEnv(policy))
+
+	// SSH-009: Check sensitive SSH rules are covered by sshTests
+	findings = append(findings, s.checkSSHTestCoverage(policy))
+
+	// SSH-010: Evaluate sshTests assertions against SSH rules
+	findings = append(findings, s.checkSSHTestResults(policy))
+
 	return findings, nil
 }
 
@@ -381,6 +414,340 @@ func (s *SSHAuditor) checkSSHRulesExist(policy ACLPolicy) types.Suggestion {
 	return finding
 }
 
+// effectiveRootFinding is SSH-005 before devices are checked
+func effectiveRootFinding() types.Suggestion {
+	return types.Suggestion{
+		ID:          "SSH-005",
+		Title:       "Users with effective root SSH access",
+		Severity:    types.Medium,
+		Category:    types.SSHSecurity,
+		Description: "SSH rules expanded against real users and devices show who can log in as root, and where.",
+		Remediation: "Limit root SSH to a small, named group. Require check mode and session recording for every root grant.",
+		Source:      "https://tailscale.com/kb/1193/tailscale-ssh",
+		Pass:        true,
+	}
+}
+
+func (s *SSHAuditor) checkEffectiveRootAccess(policy ACLPolicy, devices []*client.Device) types.Suggestion {
+	finding := effectiveRootFinding()
+
+	report := buildSSHAccessReport(policy, devices)
+
+	var details []string
+	uncontrolled := 0
+	for _, u := range report.Users {
+		var unchecked, checked []string
+		for _, g := range u.Grants {
+			if !g.Root {
+				continue
+			}
+			if g.RequiresCheck && g.Recorded {
+				checked = append(checked, g.Device)
+			} else {
+				uncontrolled++
+				unchecked = append(unchecked, fmt.Sprintf("%s (check=%v, recorded=%v)", g.Device, g.RequiresCheck, g.Recorded))
+			}
+		}
+		if len(unchecked) > 0 {
+			details = append(details, fmt.Sprintf("%s: root on %d device(s) without check mode or recording: %s", u.User, len(unchecked), strings.Join(unchecked, ", ")))
+		}
+		if len(checked) > 0 {
+			details = append(details, fmt.Sprintf("%s: root on %d device(s) with check mode and recording", u.User, len(checked)))
+		}
+	}
+	for _, selector := range report.Unresolved {
+		details = append(details, fmt.Sprintf("Could not expand selector %q; access granted through it is not included", selector))
+	}
+
+	if report.Summary.RootGrants == 0 {
+		return finding
+	}
+
+	finding.Pass = false
+	finding.Details = details
+	if uncontrolled == 0 {
+		finding.Severity = types.Informational
+	}
+	finding.Description = fmt.Sprintf("Found %d user(s) who can SSH as root (%d user/device grant(s), %d without check mode or recording). Run with --ssh-access for the full matrix.",
+		report.Summary.UsersWithRootCount, report.Summary.RootGrants, uncontrolled)
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Narrow root SSH rules to specific groups and tags, and add check mode and a recorder to each",
+		AdminURL:    "https://login.tailscale.com/admin/acls",
+		DocURL:      "https://tailscale.com/kb/1193/tailscale-ssh",
+	}
+
+	return finding
+}
+
+// recorderOfflineThreshold is how long a recorder can go unseen before it is
+// treated as offline. Recorders are servers and should be connected continuously.
+const recorderOfflineThreshold = time.Hour
+
+// recorderStaleDays is how long a recorder can go unseen before it is
+// reported as stale rather than offline
+const recorderStaleDays = 60
+
+// recorderDevices returns the devices matching a recorder selector (tag or IP)
+func recorderDevices(selector string, devices []*client.Device) []*client.Device {
+	var matched []*client.Device
+	prefix, prefixErr := netip.ParsePrefix(selector)
+	if addr, err := netip.ParseAddr(selector); err == nil {
+		prefix, prefixErr = netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	for _, dev := range devices {
+		if dev.IsExternal {
+			continue
+		}
+		if strings.HasPrefix(selector, "tag:") && containsExact(dev.Tags, selector) {
+			matched = append(matched, dev)
+		} else if prefixErr == nil && deviceInPrefix(dev, prefix) {
+			matched = append(matched, dev)
+		}
+	}
+	return matched
+}
+
+// recorderProblem describes why a recorder device can't be relied on, or "" if
+// it is healthy. Whether it is online is judged at now, the current time;
+// whether it has gone stale is judged at asOf, the audit clock's time.
+func recorderProblem(dev *client.Device, now, asOf time.Time) string {
+	if dev.LastSeen == "" {
+		return "never seen"
+	}
+	lastSeen, err := time.Parse(time.RFC3339, dev.LastSeen)
+	if err != nil {
+		return "last seen time unreadable"
+	}
+	if lastSeen.Before(asOf.AddDate(0, 0, -recorderStaleDays)) {
+		return fmt.Sprintf("stale, last seen %d days ago", int(asOf.Sub(lastSeen).Hours()/24))
+	}
+	if now.Sub(lastSeen) > recorderOfflineThreshold {
+		return fmt.Sprintf("offline, last seen %s", lastSeen.Format("2006-01-02 15:04"))
+	}
+	if dev.UpdateAvailable {
+		return fmt.Sprintf("outdated client %s", dev.ClientVersion)
+	}
+	return ""
+}
+
+// recorderHealthFinding is SSH-006 before recorders are checked
+func recorderHealthFinding() types.Suggestion {
+	return types.Suggestion{
+		ID:          "SSH-006",
+		Title:       "SSH session recorders missing or unhealthy",
+		Severity:    types.Medium,
+		Category:    types.SSHSecurity,
+		Description: "SSH rules with a recorder depend on at least one healthy recorder node. Without one, sessions are either unrecorded or, with enforceRecorder, blocked.",
+		Remediation: "Deploy at least two recorder nodes per recorder tag, keep them online and up to date, and alert on recorder downtime.",
+		Source:      "https://tailscale.com/kb/1246/tailscale-ssh-session-recording",
+		Pass:        true,
+	}
+}
+
+func (s *SSHAuditor) checkRecorderHealth(policy ACLPolicy, devices []*client.Device) types.Suggestion {
+	finding := recorderHealthFinding()
+
+	// An --as-of date can't tell whether a recorder will be online then, so
+	// liveness is judged at the current time
+	now, asOf := time.Now(), s.clock.Now()
+	var details []string
+	outage := false
+
+	for i, rule := range policy.SSH {
+		if len(rule.Recorder) == 0 {
+			continue
+		}
+
+		var healthy int
+		var problems []string
+		for _, selector := range rule.Recorder {
+			matched := recorderDevices(selector, devices)
+			if len(matched) == 0 {
+				problems = append(problems, fmt.Sprintf("%s matches no devices", selector))
+				continue
+			}
+			for _, dev := range matched {
+				if problem := recorderProblem(dev, now, asOf); problem != "" {
+					problems = append(problems, fmt.Sprintf("%s (%s): %s", dev.Name, selector, problem))
+				} else {
+					healthy++
+				}
+			}
+		}
+
+		if healthy > 0 {
+			continue
+		}
+
+		impact := "sessions are not being recorded"
+		if rule.EnforceRecorder {
+			impact = "enforceRecorder blocks all matching SSH sessions"
+			outage = true
+		}
+		details = append(details, fmt.Sprintf("SSH Rule %d (dst=%v): no healthy recorder, %s: %s", i+1, rule.Dst, impact, strings.Join(problems, "; ")))
+	}
+
+	if len(details) > 0 {
+		finding.Pass = false
+		finding.Details = details
+		if outage {
+			finding.Severity = types.High
+		}
+		finding.Description = fmt.Sprintf("Found %d SSH rule(s) with no healthy session recorder.", len(details))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Bring recorder nodes back online, update them, or point the rule's recorder at a tag with healthy nodes",
+			AdminURL:    "https://login.tailscale.com/admin/machines",
+			DocURL:      "https://tailscale.com/kb/1246/tailscale-ssh-session-recording",
+		}
+	}
+
+	return finding
+}
+
+const (
+	// maxSensitiveCheckPeriod is the longest acceptable check period for root or sensitive destinations
+	maxSensitiveCheckPeriod = 12 * time.Hour
+	// maxCheckPeriod is the longest acceptable check period for any rule
+	maxCheckPeriod = 24 * time.Hour
+)
+
+// parseCheckPeriod returns the effective re-authentication period of a check
+// mode rule. "always" is reported as zero.
+func parseCheckPeriod(rule SSHRule) (time.Duration, error) {
+	period := checkPeriodOf(rule)
+	if period == "always" {
+		return 0, nil
+	}
+	return time.ParseDuration(period)
+}
+
+func (s *SSHAuditor) checkLongCheckPeriods(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "SSH-007",
+		Title:       "SSH check mode period too long",
+		Severity:    types.Low,
+		Category:    types.SSHSecurity,
+		Description: "Check mode only re-authenticates once per checkPeriod. A long period lets a stolen or unattended session reach sensitive hosts without a fresh IdP login.",
+		Remediation: fmt.Sprintf("Use \"checkPeriod\": \"always\" or at most %s for root and sensitive destinations, and at most %s elsewhere.", maxSensitiveCheckPeriod, maxCheckPeriod),
+		Source:      "https://tailscale.com/kb/1193/tailscale-ssh",
+		Pass:        true,
+	}
+
+	var details []string
+	for i, rule := range policy.SSH {
+		if !hasCheckMode(rule) {
+			continue
+		}
+
+		period, err := parseCheckPeriod(rule)
+		if err != nil {
+			details = append(details, fmt.Sprintf("SSH Rule %d: invalid checkPeriod %q", i+1, rule.CheckPeriod))
+			continue
+		}
+
+		sensitive := contains(rule.Users, "root") || isSensitiveDestination(rule.Dst)
+		limit := maxCheckPeriod
+		if sensitive {
+			limit = maxSensitiveCheckPeriod
+		}
+		if period <= limit {
+			continue
+		}
+
+		if sensitive {
+			finding.Severity = types.Medium
+		}
+		details = append(details, fmt.Sprintf("SSH Rule %d: checkPeriod %s exceeds %s (dst=%v, users=%v)", i+1, checkPeriodOf(rule), limit, rule.Dst, rule.Users))
+	}
+
+	if len(details) > 0 {
+		finding.Pass = false
+		finding.Details = details
+		finding.Description = fmt.Sprintf("Found %d SSH check mode rule(s) with a long or invalid checkPeriod.", len(details))
+		finding.Fix = &types.FixInfo{
+			Type: types.FixTypeManual,
+			Description: `Shorten checkPeriod on high-risk rules. Example:
+  {"action": "check", "src": ["group:sre"], "dst": ["tag:prod"],
+   "users": ["root"], "checkPeriod": "always"}`,
+			AdminURL: "https://login.tailscale.com/admin/acls",
+			DocURL:   "https://tailscale.com/kb/1193/tailscale-ssh",
+		}
+	}
+
+	return finding
+}
+
+// dangerousEnvVars can change what runs in a session (library injection,
+// command lookup, interpreter start-up hooks)
+var dangerousEnvVars = []string{
+	"LD_PRELOAD", "LD_LIBRARY_PATH", "LD_AUDIT",
+	"DYLD_INSERT_LIBRARIES", "DYLD_LIBRARY_PATH",
+	"PATH", "IFS", "BASH_ENV", "ENV", "PROMPT_COMMAND", "SHELLOPTS",
+	"PYTHONPATH", "PYTHONSTARTUP", "PERL5OPT", "PERL5LIB", "RUBYOPT", "NODE_OPTIONS",
+}
+
+// dangerousEnvMatches returns the dangerous variables an acceptEnv pattern allows.
+// Patterns support * and ? wildcards.
+func dangerousEnvMatches(pattern string) []string {
+	var matched []string
+	for _, name := range dangerousEnvVars {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			matched = append(matched, name)
+		}
+	}
+	return matched
+}
+
+func (s *SSHAuditor) checkAcceptEnv(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "SSH-008",
+		Title:       "SSH acceptEnv allows dangerous environment variables",
+		Severity:    types.Low,
+		Category:    types.SSHSecurity,
+		Description: "acceptEnv lets clients forward environment variables into SSH sessions. Variables like LD_PRELOAD or PATH can change what code runs on the host.",
+		Remediation: "List only the specific variables sessions need (e.g. GIT_*, LANG). Avoid \"*\" and patterns matching LD_*, DYLD_* or PATH.",
+		Source:      "https://tailscale.com/kb/1193/tailscale-ssh",
+		Pass:        true,
+	}
+
+	var details []string
+	for i, rule := range policy.SSH {
+		var allowed []string
+		for _, pattern := range rule.AcceptEnv {
+			for _, name := range dangerousEnvMatches(pattern) {
+				if !containsExact(allowed, name) {
+					allowed = append(allowed, name)
+				}
+			}
+		}
+		if len(allowed) == 0 {
+			continue
+		}
+
+		if isSensitiveDestination(rule.Dst) || isBroadDestination(rule.Dst) {
+			finding.Severity = types.Medium
+		}
+		details = append(details, fmt.Sprintf("SSH Rule %d: acceptEnv=%v allows %s (dst=%v)", i+1, rule.AcceptEnv, strings.Join(allowed, ", "), rule.Dst))
+	}
+
+	if len(details) > 0 {
+		finding.Pass = false
+		finding.Details = details
+		finding.Description = fmt.Sprintf("Found %d SSH rule(s) whose acceptEnv allows variables that can inject code into sessions.", len(details))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Replace wildcard acceptEnv entries with an explicit list of safe variables",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1193/tailscale-ssh",
+		}
+	}
+
+	return finding
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if strings.EqualFold(s, item) {
@@ -0,0 +1,506 @@
+package auditor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func sshTestDevices() []*client.Device {
+	return []*client.Device{
+		{DeviceID: "1", Name: "alice-laptop", User: "alice@example.com", Addresses: []string{"100.64.0.1"}},
+		{DeviceID: "2", Name: "bob-laptop", User: "bob@example.com", Addresses: []string{"100.64.0.2"}},
+		{DeviceID: "3", Name: "prod-db", User: "alice@example.com", Tags: []string{"tag:prod"}, Addresses: []string{"100.64.0.3"}},
+		{DeviceID: "4", Name: "shared-box", User: "carol@other.com", IsExternal: true, Addresses: []string{"100.64.0.4"}},
+	}
+}
+
+func TestBuildSSHAccessReport(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         ACLPolicy
+		wantUsers      int
+		wantGrants     int
+		wantRootGrants int
+		wantRootNoChk  int
+		wantUnresolved int
+	}{
+		{
+			name:   "no SSH rules",
+			policy: ACLPolicy{},
+		},
+		{
+			name: "autogroup:self nonroot",
+			policy: ACLPolicy{SSH: []SSHRule{
+				{Action: "check", Src: []string{"autogroup:member"}, Dst: []string{"autogroup:self"}, Users: []string{"autogroup:nonroot"}},
+			}},
+			wantUsers:  2,
+			wantGrants: 2,
+		},
+		{
+			name: "group root on tag expands to tagged devices only",
+			policy: ACLPolicy{
+				Groups: map[string][]string{"group:ops": {"bob@example.com"}},
+				SSH: []SSHRule{
+					{Action: "accept", Src: []string{"group:ops"}, Dst: []string{"tag:prod"}, Users: []string{"root", "ubuntu"}},
+				},
+			},
+			wantUsers:      1,
+			wantGrants:     2,
+			wantRootGrants: 1,
+			wantRootNoChk:  1,
+		},
+		{
+			name: "least restrictive rule wins",
+			policy: ACLPolicy{SSH: []SSHRule{
+				{Action: "check", Src: []string{"bob@example.com"}, Dst: []string{"tag:prod"}, Users: []string{"root"}, Recorder: []string{"tag:recorder"}},
+				{Action: "accept", Src: []string{"bob@example.com"}, Dst: []string{"100.64.0.3"}, Users: []string{"root"}},
+			}},
+			wantUsers:      1,
+			wantGrants:     1,
+			wantRootGrants: 1,
+			wantRootNoChk:  1,
+		},
+		{
+			name: "localpart and unresolved autogroup",
+			policy: ACLPolicy{SSH: []SSHRule{
+				{Action: "accept", Src: []string{"autogroup:admin"}, Dst: []string{"tag:prod"}, Users: []string{"root"}},
+				{Action: "accept", Src: []string{"*"}, Dst: []string{"tag:prod"}, Users: []string{"localpart:*@example.com"}},
+			}},
+			wantUsers:      2,
+			wantGrants:     2,
+			wantUnresolved: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := buildSSHAccessReport(tt.policy, sshTestDevices())
+			if report.Summary.Users != tt.wantUsers {
+				t.Errorf("Users = %d, want %d", report.Summary.Users, tt.wantUsers)
+			}
+			if report.Summary.Grants != tt.wantGrants {
+				t.Errorf("Grants = %d, want %d", report.Summary.Grants, tt.wantGrants)
+			}
+			if report.Summary.RootGrants != tt.wantRootGrants {
+				t.Errorf("RootGrants = %d, want %d", report.Summary.RootGrants, tt.wantRootGrants)
+			}
+			if report.Summary.RootWithoutCheck != tt.wantRootNoChk {
+				t.Errorf("RootWithoutCheck = %d, want %d", report.Summary.RootWithoutCheck, tt.wantRootNoChk)
+			}
+			if len(report.Unresolved) != tt.wantUnresolved {
+				t.Errorf("Unresolved = %v, want %d entries", report.Unresolved, tt.wantUnresolved)
+			}
+		})
+	}
+}
+
+func TestResolveLocalUsers(t *testing.T) {
+	got := resolveLocalUsers([]string{"root", "localpart:*@example.com"}, "alice@example.com")
+	if len(got) != 2 || got[0] != "root" || got[1] != "alice" {
+		t.Errorf("resolveLocalUsers() = %v, want [root alice]", got)
+	}
+
+	got = resolveLocalUsers([]string{"localpart:*@example.com"}, "carol@other.com")
+	if len(got) != 0 {
+		t.Errorf("resolveLocalUsers() = %v, want none for other domain", got)
+	}
+}
+
+func TestSSHAuditWithoutDevices(t *testing.T) {
+	s := &SSHAuditor{}
+	policy := ACLPolicy{SSH: []SSHRule{
+		{Action: "accept", Src: []string{"autogroup:member"}, Dst: []string{"autogroup:self"}, Users: []string{"root"}, Recorder: []string{"tag:recorder"}},
+	}}
+	findings, err := s.Audit(context.Background(), policy, &TailnetData{DevicesErr: errors.New("403 forbidden")})
+	if err != nil {
+		t.Fatalf("Audit() error: %v", err)
+	}
+
+	// The policy-only checks still run
+	byID := make(map[string]types.Suggestion)
+	for _, f := range findings {
+		byID[f.ID] = f
+	}
+	for _, id := range []string{"SSH-001", "SSH-002", "SSH-003", "SSH-004", "SSH-007", "SSH-008", "SSH-009", "SSH-010"} {
+		if _, ok := byID[id]; !ok {
+			t.Errorf("%s missing without devices", id)
+		}
+	}
+	for _, id := range []string{"SSH-005", "SSH-006"} {
+		f := byID[id]
+		details, _ := f.Details.(string)
+		if f.Pass || f.Severity != types.Informational || !strings.Contains(f.Description, "403 forbidden") || !strings.HasPrefix(details, "MANUAL CHECK REQUIRED") {
+			t.Errorf("%s without devices = %+v, want informational manual check", id, f)
+		}
+	}
+}
+
+func TestCheckEffectiveRootAccess(t *testing.T) {
+	s := &SSHAuditor{}
+
+	tests := []struct {
+		name         string
+		policy       ACLPolicy
+		wantPass     bool
+		wantSeverity types.Severity
+		wantDesc     string
+	}{
+		{
+			name: "no root access - pass",
+			policy: ACLPolicy{SSH: []SSHRule{
+				{Action: "accept", Src: []string{"autogroup:member"}, Dst: []string{"autogroup:self"}, Users: []string{"autogroup:nonroot"}},
+			}},
+			wantPass: true,
+		},
+		{
+			name: "root without check - medium",
+			policy: ACLPolicy{SSH: []SSHRule{
+				{Action: "accept", Src: []string{"alice@example.com"}, Dst: []string{"tag:prod"}, Users: []string{"root"}},
+			}},
+			wantPass:     false,
+			wantSeverity: types.Medium,
+		},
+		{
+			name: "root with check and recording - informational",
+			policy: ACLPolicy{SSH: []SSHRule{
+				{Action: "check", Src: []string{"alice@example.com"}, Dst: []string{"tag:prod"}, Users: []string{"root"}, Recorder: []string{"tag:recorder"}},
+			}},
+			wantPass:     false,
+			wantSeverity: types.Informational,
+			wantDesc:     "0 without check mode or recording",
+		},
+		{
+			name: "root with check but no recording - medium",
+			policy: ACLPolicy{SSH: []SSHRule{
+				{Action: "check", Src: []string{"alice@example.com"}, Dst: []string{"tag:prod"}, Users: []string{"root"}},
+			}},
+			wantPass:     false,
+			wantSeverity: types.Medium,
+			wantDesc:     "1 without check mode or recording",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := s.checkEffectiveRootAccess(tt.policy, sshTestDevices())
+			if tt.wantDesc != "" && !strings.Contains(finding.Description, tt.wantDesc) {
+				t.Errorf("Description = %q, want it to contain %q", finding.Description, tt.wantDesc)
+			}
+			if finding.ID != "SSH-005" {
+				t.Errorf("ID = %s, want SSH-005", finding.ID)
+			}
+			if finding.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v", finding.Pass, tt.wantPass)
+			}
+			if !tt.wantPass && finding.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %v, want %v", finding.Severity, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestCheckRecorderHealth(t *testing.T) {
+	s := &SSHAuditor{}
+	now := time.Now().UTC()
+	recent := now.Add(-5 * time.Minute).Format(time.RFC3339)
+	offline := now.Add(-3 * time.Hour).Format(time.RFC3339)
+
+	recorderRule := func(enforce bool) ACLPolicy {
+		return ACLPolicy{SSH: []SSHRule{
+			{Action: "accept", Src: []string{"group:ops"}, Dst: []string{"tag:prod"}, Users: []string{"root"}, Recorder: []string{"tag:recorder"}, EnforceRecorder: enforce},
+		}}
+	}
+
+	tests := []struct {
+		name         string
+		policy       ACLPolicy
+		devices      []*client.Device
+		wantPass     bool
+		wantSeverity types.Severity
+	}{
+		{
+			name:     "no recorders configured - pass",
+			policy:   ACLPolicy{SSH: []SSHRule{{Action: "accept", Src: []string{"*"}, Dst: []string{"*"}, Users: []string{"root"}}}},
+			wantPass: true,
+		},
+		{
+			name:   "healthy recorder - pass",
+			policy: recorderRule(true),
+			devices: []*client.Device{
+				{DeviceID: "1", Name: "rec1", Tags: []string{"tag:recorder"}, LastSeen: recent},
+			},
+			wantPass: true,
+		},
+		{
+			name:   "one of two recorders healthy - pass",
+			policy: recorderRule(true),
+			devices: []*client.Device{
+				{DeviceID: "1", Name: "rec1", Tags: []string{"tag:recorder"}, LastSeen: offline},
+				{DeviceID: "2", Name: "rec2", Tags: []string{"tag:recorder"}, LastSeen: recent},
+			},
+			wantPass: true,
+		},
+		{
+			name:         "recorder tag matches nothing - medium",
+			policy:       recorderRule(false),
+			wantPass:     false,
+			wantSeverity: types.Medium,
+		},
+		{
+			name:   "only recorder offline with enforceRecorder - high",
+			policy: recorderRule(true),
+			devices: []*client.Device{
+				{DeviceID: "1", Name: "rec1", Tags: []string{"tag:recorder"}, LastSeen: offline},
+			},
+			wantPass:     false,
+			wantSeverity: types.High,
+		},
+		{
+			name:   "only recorder outdated - medium",
+			policy: recorderRule(false),
+			devices: []*client.Device{
+				{DeviceID: "1", Name: "rec1", Tags: []string{"tag:recorder"}, LastSeen: recent, UpdateAvailable: true},
+			},
+			wantPass:     false,
+			wantSeverity: types.Medium,
+		},
+		{
+			name:   "only recorder last seen unreadable - medium",
+			policy: recorderRule(false),
+			devices: []*client.Device{
+				{DeviceID: "1", Name: "rec1", Tags: []string{"tag:recorder"}, LastSeen: "yesterday"},
+			},
+			wantPass:     false,
+			wantSeverity: types.Medium,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := s.checkRecorderHealth(tt.policy, tt.devices)
+			if finding.ID != "SSH-006" {
+				t.Errorf("ID = %s, want SSH-006", finding.ID)
+			}
+			if finding.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (details: %v)", finding.Pass, tt.wantPass, finding.Details)
+			}
+			if !tt.wantPass && finding.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %v, want %v", finding.Severity, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestCheckLongCheckPeriods(t *testing.T) {
+	s := &SSHAuditor{}
+
+	tests := []struct {
+		name         string
+		rules        []SSHRule
+		wantPass     bool
+		wantSeverity types.Severity
+	}{
+		{
+			name:     "accept without check mode - skipped",
+			rules:    []SSHRule{{Action: "accept", Dst: []string{"tag:prod"}, Users: []string{"root"}}},
+			wantPass: true,
+		},
+		{
+			name:     "default 12h period on root - pass",
+			rules:    []SSHRule{{Action: "check", Dst: []string{"tag:prod"}, Users: []string{"root"}}},
+			wantPass: true,
+		},
+		{
+			name:     "always - pass",
+			rules:    []SSHRule{{Action: "check", Dst: []string{"tag:prod"}, Users: []string{"root"}, CheckPeriod: "always"}},
+			wantPass: true,
+		},
+		{
+			name:         "20h on root - medium",
+			rules:        []SSHRule{{Action: "check", Dst: []string{"tag:prod"}, Users: []string{"root"}, CheckPeriod: "20h"}},
+			wantPass:     false,
+			wantSeverity: types.Medium,
+		},
+		{
+			name:     "20h on nonroot workstation - pass",
+			rules:    []SSHRule{{Action: "check", Dst: []string{"autogroup:self"}, Users: []string{"autogroup:nonroot"}, CheckPeriod: "20h"}},
+			wantPass: true,
+		},
+		{
+			name:         "168h on nonroot - low",
+			rules:        []SSHRule{{Action: "check", Dst: []string{"autogroup:self"}, Users: []string{"autogroup:nonroot"}, CheckPeriod: "168h"}},
+			wantPass:     false,
+			wantSeverity: types.Low,
+		},
+		{
+			name:         "invalid period - low",
+			rules:        []SSHRule{{Action: "check", Dst: []string{"autogroup:self"}, Users: []string{"autogroup:nonroot"}, CheckPeriod: "1 week"}},
+			wantPass:     false,
+			wantSeverity: types.Low,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := s.checkLongCheckPeriods(ACLPolicy{SSH: tt.rules})
+			if finding.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (details: %v)", finding.Pass, tt.wantPass, finding.Details)
+			}
+			if !tt.wantPass && finding.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %v, want %v", finding.Severity, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestCheckAcceptEnv(t *testing.T) {
+	s := &SSHAuditor{}
+
+	tests := []struct {
+		name         string
+		rules        []SSHRule
+		wantPass     bool
+		wantSeverity types.Severity
+	}{
+		{
+			name:     "no acceptEnv - pass",
+			rules:    []SSHRule{{Action: "accept", Dst: []string{"tag:prod"}}},
+			wantPass: true,
+		},
+		{
+			name:     "safe explicit list - pass",
+			rules:    []SSHRule{{Action: "accept", Dst: []string{"tag:prod"}, AcceptEnv: []string{"GIT_*", "LANG"}}},
+			wantPass: true,
+		},
+		{
+			name:         "wildcard on sensitive host - medium",
+			rules:        []SSHRule{{Action: "accept", Dst: []string{"tag:prod"}, AcceptEnv: []string{"*"}}},
+			wantPass:     false,
+			wantSeverity: types.Medium,
+		},
+		{
+			name:         "LD_* on workstation - low",
+			rules:        []SSHRule{{Action: "accept", Dst: []string{"autogroup:self"}, AcceptEnv: []string{"LD_*"}}},
+			wantPass:     false,
+			wantSeverity: types.Low,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := s.checkAcceptEnv(ACLPolicy{SSH: tt.rules})
+			if finding.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (details: %v)", finding.Pass, tt.wantPass, finding.Details)
+			}
+			if !tt.wantPass && finding.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %v, want %v", finding.Severity, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestEvaluateSSH(t *testing.T) {
+	policy := ACLPolicy{
+		Groups: map[string][]string{"group:sre": {"alice@example.com"}},
+		SSH: []SSHRule{
+			{Action: "check", Src: []string{"group:sre"}, Dst: []string{"tag:prod"}, Users: []string{"root"}},
+			{Action: "accept", Src: []string{"autogroup:member"}, Dst: []string{"autogroup:self"}, Users: []string{"autogroup:nonroot"}},
+			{Action: "accept", Src: []string{"group:sre"}, Dst: []string{"tag:prod"}, Users: []string{"localpart:*@example.com"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		src       string
+		dst       string
+		localUser string
+		want      string
+		wantRule  int
+	}{
+		{"root on prod via check", "alice@example.com", "tag:prod", "root", sshOutcomeCheck, 0},
+		{"non-member denied root", "bob@example.com", "tag:prod", "root", sshOutcomeDeny, -1},
+		{"self nonroot", "bob@example.com", "bob@example.com", "bob", sshOutcomeAccept, 1},
+		{"self root denied", "bob@example.com", "bob@example.com", "root", sshOutcomeDeny, -1},
+		{"localpart on prod", "alice@example.com", "tag:prod", "alice", sshOutcomeAccept, 2},
+		{"wrong localpart on prod", "alice@example.com", "tag:prod", "ubuntu", sshOutcomeDeny, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rule := evaluateSSH(policy, tt.src, tt.dst, tt.localUser)
+			if got != tt.want || rule != tt.wantRule {
+				t.Errorf("evaluateSSH() = %s (rule %d), want %s (rule %d)", got, rule, tt.want, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestCheckSSHTests(t *testing.T) {
+	s := &SSHAuditor{}
+	rules := []SSHRule{
+		{Action: "check", Src: []string{"alice@example.com"}, Dst: []string{"tag:prod"}, Users: []string{"root"}},
+		{Action: "accept", Src: []string{"autogroup:member"}, Dst: []string{"autogroup:self"}, Users: []string{"autogroup:nonroot"}},
+	}
+
+	tests := []struct {
+		name         string
+		sshTests     []SSHTest
+		wantCovered  bool
+		wantFailures int
+	}{
+		{
+			name:        "no tests",
+			wantCovered: false,
+		},
+		{
+			name: "passing tests cover root rule",
+			sshTests: []SSHTest{
+				{Src: "alice@example.com", Dst: []string{"tag:prod"}, Check: []string{"root"}, Deny: []string{"admin"}},
+			},
+			wantCovered: true,
+		},
+		{
+			name: "failing assertions are each reported",
+			sshTests: []SSHTest{
+				{Src: "alice@example.com", Dst: []string{"tag:prod"}, Accept: []string{"root"}, Deny: []string{"root"}},
+			},
+			wantCovered:  true,
+			wantFailures: 2,
+		},
+		{
+			name: "tests that only exercise the self rule",
+			sshTests: []SSHTest{
+				{Src: "bob@example.com", Dst: []string{"bob@example.com"}, Accept: []string{"bob"}, Deny: []string{"root"}},
+			},
+			wantCovered: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := ACLPolicy{SSH: rules, SSHTests: tt.sshTests}
+
+			coverage := s.checkSSHTestCoverage(policy)
+			if coverage.Pass != tt.wantCovered {
+				t.Errorf("SSH-009 Pass = %v, want %v (details: %v)", coverage.Pass, tt.wantCovered, coverage.Details)
+			}
+
+			results := s.checkSSHTestResults(policy)
+			if results.Pass != (tt.wantFailures == 0) {
+				t.Errorf("SSH-010 Pass = %v, want %v", results.Pass, tt.wantFailures == 0)
+			}
+			if tt.wantFailures > 0 {
+				details, ok := results.Details.([]string)
+				if !ok || len(details) != tt.wantFailures {
+					t.Errorf("SSH-010 Details = %v, want %d failures", results.Details, tt.wantFailures)
+				}
+			}
+		})
+	}
+}
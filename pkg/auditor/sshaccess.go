@@ -0,0 +1,397 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tailscale/hujson"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// defaultCheckPeriod is the re-authentication period Tailscale applies to
+// "action": "check" rules that don't set checkPeriod
+const defaultCheckPeriod = "12h"
+
+// SSHAccessCollector builds the effective SSH access matrix for a tailnet
+type SSHAccessCollector struct {
+	client *client.Client
+}
+
+// NewSSHAccessCollector creates a new SSH access matrix collector
+func NewSSHAccessCollector(c *client.Client) *SSHAccessCollector {
+	return &SSHAccessCollector{client: c}
+}
+
+// Collect fetches the policy and devices and expands SSH rules into per-user access
+func (c *SSHAccessCollector) Collect(ctx context.Context) (*types.SSHAccessReport, error) {
+	aclHuJSON, err := c.client.GetACLHuJSON(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ACL: %w", err)
+	}
+
+	policy, err := parseACLPolicy(aclHuJSON.ACL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ACL: %w", err)
+	}
+
+	devices, err := c.client.GetDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	report := buildSSHAccessReport(policy, devices)
+	report.Tailnet = c.client.Tailnet()
+	report.GeneratedAt = time.Now()
+	return report, nil
+}
+
+// parseACLPolicy standardizes a HuJSON policy and decodes it into an ACLPolicy
+func parseACLPolicy(raw string) (ACLPolicy, error) {
+	var policy ACLPolicy
+	standardized, err := hujson.Standardize([]byte(raw))
+	if err != nil {
+		return policy, err
+	}
+	if err := json.Unmarshal(standardized, &policy); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+// sshUniverse holds the users, groups and devices that SSH rule selectors
+// are expanded against
+type sshUniverse struct {
+	policy     ACLPolicy
+	devices    []*client.Device
+	users      []string
+	external   map[string]bool
+	unresolved map[string]bool
+}
+
+func newSSHUniverse(policy ACLPolicy, devices []*client.Device) *sshUniverse {
+	u := &sshUniverse{
+		policy:     policy,
+		devices:    devices,
+		external:   make(map[string]bool),
+		unresolved: make(map[string]bool),
+	}
+
+	known := make(map[string]bool)
+	ownsInternal := make(map[string]bool)
+	addUser := func(user string) {
+		if strings.Contains(user, "@") && !known[user] {
+			known[user] = true
+			u.users = append(u.users, user)
+		}
+	}
+
+	// Device owners are the primary source of human identities. Tagged
+	// devices are owned by their tags, not by the user who created them.
+	for _, dev := range devices {
+		if len(dev.Tags) > 0 || dev.User == "" {
+			continue
+		}
+		addUser(dev.User)
+		if !dev.IsExternal {
+			ownsInternal[dev.User] = true
+		}
+	}
+	for _, members := range policy.Groups {
+		for _, m := range members {
+			addUser(m)
+			ownsInternal[m] = true
+		}
+	}
+	for _, rule := range policy.SSH {
+		for _, src := range rule.Src {
+			addUser(src)
+			ownsInternal[src] = true
+		}
+	}
+
+	// A user is external if every device they own is shared in from elsewhere
+	for _, user := range u.users {
+		if !ownsInternal[user] {
+			u.external[user] = true
+		}
+	}
+
+	sort.Strings(u.users)
+	return u
+}
+
+// resolveSource expands an SSH src selector into the human users it matches.
+// Selectors that only match machines (tags, IPs) resolve to no users.
+func (u *sshUniverse) resolveSource(selector string) []string {
+	switch {
+	case selector == "*":
+		return u.users
+	case selector == "autogroup:member":
+		var members []string
+		for _, user := range u.users {
+			if !u.external[user] {
+				members = append(members, user)
+			}
+		}
+		return members
+	case strings.HasPrefix(selector, "group:"):
+		members, ok := u.policy.Groups[selector]
+		if !ok {
+			u.unresolved[selector] = true
+		}
+		return members
+	case strings.HasPrefix(selector, "tag:"), selector == "autogroup:tagged":
+		return nil
+	case strings.HasPrefix(selector, "autogroup:"):
+		// Role-based autogroups (admin, owner, ...) need the users API
+		u.unresolved[selector] = true
+		return nil
+	case strings.Contains(selector, "@"):
+		return []string{selector}
+	default:
+		return nil
+	}
+}
+
+// resolveDestination expands an SSH dst selector into devices for a given source user
+func (u *sshUniverse) resolveDestination(selector, user string) []*client.Device {
+	var matched []*client.Device
+	match := func(fn func(dev *client.Device) bool) []*client.Device {
+		for _, dev := range u.devices {
+			// Shared-in devices are governed by their home tailnet's SSH policy
+			if dev.IsExternal {
+				continue
+			}
+			if fn(dev) {
+				matched = append(matched, dev)
+			}
+		}
+		return matched
+	}
+
+	switch {
+	case selector == "*":
+		return match(func(dev *client.Device) bool { return true })
+	case selector == "autogroup:self":
+		return match(func(dev *client.Device) bool { return len(dev.Tags) == 0 && dev.User == user })
+	case selector == "autogroup:tagged":
+		return match(func(dev *client.Device) bool { return len(dev.Tags) > 0 })
+	case selector == "autogroup:member":
+		return match(func(dev *client.Device) bool { return len(dev.Tags) == 0 && !u.external[dev.User] })
+	case strings.HasPrefix(selector, "tag:"):
+		return match(func(dev *client.Device) bool { return containsExact(dev.Tags, selector) })
+	case strings.HasPrefix(selector, "group:"):
+		members, ok := u.policy.Groups[selector]
+		if !ok {
+			u.unresolved[selector] = true
+			return nil
+		}
+		return match(func(dev *client.Device) bool { return len(dev.Tags) == 0 && containsExact(members, dev.User) })
+	case strings.HasPrefix(selector, "autogroup:"):
+		u.unresolved[selector] = true
+		return nil
+	case strings.Contains(selector, "@"):
+		return match(func(dev *client.Device) bool { return len(dev.Tags) == 0 && dev.User == selector })
+	}
+
+	// Hosts aliases and raw IPs
+	target := selector
+	if aliased, ok := u.policy.Hosts[selector]; ok {
+		target = aliased
+	}
+	if prefix, err := netip.ParsePrefix(target); err == nil {
+		return match(func(dev *client.Device) bool { return deviceInPrefix(dev, prefix) })
+	}
+	if addr, err := netip.ParseAddr(target); err == nil {
+		return match(func(dev *client.Device) bool { return deviceInPrefix(dev, netip.PrefixFrom(addr, addr.BitLen())) })
+	}
+
+	u.unresolved[selector] = true
+	return nil
+}
+
+// resolveLocalUsers expands the SSH rule users list for a given source user
+func resolveLocalUsers(users []string, sourceUser string) []string {
+	var local []string
+	for _, name := range users {
+		switch {
+		case strings.HasPrefix(name, "localpart:"):
+			// localpart:*@example.com maps alice@example.com to "alice"
+			domain := strings.TrimPrefix(name, "localpart:*")
+			if at := strings.LastIndex(sourceUser, "@"); at > 0 && strings.EqualFold(sourceUser[at:], domain) {
+				local = append(local, sourceUser[:at])
+			}
+		default:
+			local = append(local, name)
+		}
+	}
+	return local
+}
+
+// deviceInPrefix returns true if any of the device's Tailscale addresses fall within prefix
+func deviceInPrefix(dev *client.Device, prefix netip.Prefix) bool {
+	for _, a := range dev.Addresses {
+		if addr, err := netip.ParseAddr(a); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsExact is a case-sensitive membership test
+func containsExact(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPeriodOf returns the effective check period of a rule ("" when the rule doesn't use check mode)
+func checkPeriodOf(rule SSHRule) string {
+	if !hasCheckMode(rule) {
+		return ""
+	}
+	if rule.CheckPeriod == "" {
+		return defaultCheckPeriod
+	}
+	return rule.CheckPeriod
+}
+
+// longerCheckPeriod returns whichever check period allows the longer gap between re-authentication
+func longerCheckPeriod(a, b string) string {
+	if a == "" || b == "" {
+		return ""
+	}
+	if a == "always" {
+		return b
+	}
+	if b == "always" {
+		return a
+	}
+	da, errA := time.ParseDuration(a)
+	db, errB := time.ParseDuration(b)
+	if errA != nil || errB != nil {
+		return a
+	}
+	if db > da {
+		return b
+	}
+	return a
+}
+
+// buildSSHAccessReport expands every SSH rule against the known users and
+// devices. When several rules grant the same user/device/local-user
+// combination, the least restrictive controls win: access only counts as
+// requiring check mode or recording if every granting rule does.
+func buildSSHAccessReport(policy ACLPolicy, devices []*client.Device) *types.SSHAccessReport {
+	u := newSSHUniverse(policy, devices)
+
+	type grantKey struct {
+		user, deviceID, localUser string
+	}
+	grants := make(map[grantKey]*types.SSHAccessGrant)
+	byUser := make(map[string][]grantKey)
+
+	for i, rule := range policy.SSH {
+		if rule.Action != "accept" && rule.Action != "check" {
+			continue
+		}
+
+		var sources []string
+		for _, src := range rule.Src {
+			sources = append(sources, u.resolveSource(src)...)
+		}
+
+		for _, user := range sources {
+			localUsers := resolveLocalUsers(rule.Users, user)
+			if len(localUsers) == 0 {
+				continue
+			}
+
+			for _, dst := range rule.Dst {
+				for _, dev := range u.resolveDestination(dst, user) {
+					for _, localUser := range localUsers {
+						key := grantKey{user: user, deviceID: dev.DeviceID, localUser: localUser}
+						g, ok := grants[key]
+						if !ok {
+							name := dev.Name
+							if name == "" {
+								name = dev.Hostname
+							}
+							g = &types.SSHAccessGrant{
+								DeviceID:        dev.DeviceID,
+								Device:          name,
+								DeviceTags:      dev.Tags,
+								LocalUser:       localUser,
+								Root:            localUser == "root",
+								RequiresCheck:   true,
+								CheckPeriod:     checkPeriodOf(rule),
+								Recorded:        true,
+								EnforceRecorder: true,
+							}
+							grants[key] = g
+							byUser[user] = append(byUser[user], key)
+						} else {
+							g.CheckPeriod = longerCheckPeriod(g.CheckPeriod, checkPeriodOf(rule))
+						}
+
+						if !hasCheckMode(rule) {
+							g.RequiresCheck = false
+							g.CheckPeriod = ""
+						}
+						if len(rule.Recorder) == 0 {
+							g.Recorded = false
+						}
+						if !rule.EnforceRecorder {
+							g.EnforceRecorder = false
+						}
+						if !containsInt(g.Rules, i+1) {
+							g.Rules = append(g.Rules, i+1)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	report := &types.SSHAccessReport{}
+	for _, user := range u.users {
+		keys := byUser[user]
+		if len(keys) == 0 {
+			continue
+		}
+		access := types.SSHUserAccess{User: user}
+		for _, key := range keys {
+			access.Grants = append(access.Grants, *grants[key])
+		}
+		report.Users = append(report.Users, access)
+	}
+
+	// Users only named in SSH src/groups are already in u.users, so every
+	// granted key has been emitted above.
+	for selector := range u.unresolved {
+		report.Unresolved = append(report.Unresolved, selector)
+	}
+	sort.Strings(report.Unresolved)
+
+	report.CalculateSummary()
+	return report
+}
+
+func containsInt(slice []int, item int) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package auditor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// SSH test outcomes, matching the sshTests assertion names
+const (
+	sshOutcomeAccept = "accept"
+	sshOutcomeCheck  = "check"
+	sshOutcomeDeny   = "deny"
+)
+
+// sshSourceMatches reports whether an SSH rule src selector matches a test source.
+// Tests name a single user or tag, so selectors are matched symbolically.
+func sshSourceMatches(policy ACLPolicy, selector, src string) bool {
+	switch {
+	case selector == "*":
+		return true
+	case strings.EqualFold(selector, src):
+		return true
+	case selector == "autogroup:member":
+		return strings.Contains(src, "@")
+	case selector == "autogroup:tagged":
+		return strings.HasPrefix(src, "tag:")
+	case strings.HasPrefix(selector, "group:"):
+		return contains(policy.Groups[selector], src)
+	}
+	return false
+}
+
+// sshDestinationMatches reports whether an SSH rule dst selector matches a test destination
+func sshDestinationMatches(policy ACLPolicy, selector, dst, src string) bool {
+	switch {
+	case selector == "*":
+		return true
+	case strings.EqualFold(selector, dst):
+		return true
+	case selector == "autogroup:self":
+		return strings.EqualFold(dst, src)
+	case selector == "autogroup:tagged":
+		return strings.HasPrefix(dst, "tag:")
+	case selector == "autogroup:member":
+		return strings.Contains(dst, "@")
+	case strings.HasPrefix(selector, "group:"):
+		return contains(policy.Groups[selector], dst)
+	}
+
+	// Hosts aliases can appear on either side
+	if ip, ok := policy.Hosts[selector]; ok && ip == dst {
+		return true
+	}
+	if ip, ok := policy.Hosts[dst]; ok && ip == selector {
+		return true
+	}
+	return false
+}
+
+// sshLocalUserMatches reports whether an SSH rule users list allows a local user
+func sshLocalUserMatches(users []string, localUser, src string) bool {
+	for _, u := range users {
+		switch {
+		case u == localUser:
+			return true
+		case u == "autogroup:nonroot" && localUser != "root":
+			return true
+		case strings.HasPrefix(u, "localpart:"):
+			if containsExact(resolveLocalUsers([]string{u}, src), localUser) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evaluateSSH returns the outcome of an SSH connection attempt and the index of
+// the rule that decided it (-1 when no rule matches). The first matching rule wins.
+func evaluateSSH(policy ACLPolicy, src, dst, localUser string) (string, int) {
+	for i, rule := range policy.SSH {
+		if rule.Action != "accept" && rule.Action != "check" {
+			continue
+		}
+
+		srcMatch := false
+		for _, sel := range rule.Src {
+			if sshSourceMatches(policy, sel, src) {
+				srcMatch = true
+				break
+			}
+		}
+		if !srcMatch {
+			continue
+		}
+
+		dstMatch := false
+		for _, sel := range rule.Dst {
+			if sshDestinationMatches(policy, sel, dst, src) {
+				dstMatch = true
+				break
+			}
+		}
+		if !dstMatch || !sshLocalUserMatches(rule.Users, localUser, src) {
+			continue
+		}
+
+		if hasCheckMode(rule) {
+			return sshOutcomeCheck, i
+		}
+		return sshOutcomeAccept, i
+	}
+	return sshOutcomeDeny, -1
+}
+
+// runSSHTests evaluates every sshTests assertion. It returns the failures and
+// the set of rule indexes exercised by at least one assertion.
+func runSSHTests(policy ACLPolicy) ([]string, map[int]bool) {
+	var failures []string
+	covered := make(map[int]bool)
+
+	for i, test := range policy.SSHTests {
+		assertions := []struct {
+			want  string
+			users []string
+		}{
+			{sshOutcomeAccept, test.Accept},
+			{sshOutcomeCheck, test.Check},
+			{sshOutcomeDeny, test.Deny},
+		}
+
+		for _, dst := range test.Dst {
+			for _, a := range assertions {
+				for _, localUser := range a.users {
+					got, ruleIdx := evaluateSSH(policy, test.Src, dst, localUser)
+					if ruleIdx >= 0 {
+						covered[ruleIdx] = true
+					}
+					if got == a.want {
+						continue
+					}
+					decidedBy := "no matching rule"
+					if ruleIdx >= 0 {
+						decidedBy = fmt.Sprintf("SSH Rule %d", ruleIdx+1)
+					}
+					failures = append(failures, fmt.Sprintf("sshTest %d: %s -> %s as %q: expected %s, got %s (%s)",
+						i+1, test.Src, dst, localUser, a.want, got, decidedBy))
+				}
+			}
+		}
+	}
+
+	return failures, covered
+}
+
+// isSensitiveSSHRule returns true for rules worth pinning down with sshTests
+func isSensitiveSSHRule(rule SSHRule) bool {
+	return contains(rule.Users, "root") || isSensitiveDestination(rule.Dst) || isBroadDestination(rule.Dst)
+}
+
+func (s *SSHAuditor) checkSSHTestCoverage(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "SSH-009",
+		Title:       "Sensitive SSH rules have no sshTests",
+		Severity:    types.Low,
+		Category:    types.SSHSecurity,
+		Description: "sshTests assert who can SSH where, and as which user. Tailscale rejects policy changes that break them, preventing accidental root or production access.",
+		Remediation: "Add an 'sshTests' section covering root access and sensitive destinations with accept, check and deny assertions.",
+		Source:      "https://tailscale.com/kb/1337/policy-syntax#sshtests",
+		Pass:        true,
+	}
+
+	_, covered := runSSHTests(policy)
+
+	var untested []string
+	for i, rule := range policy.SSH {
+		if rule.Action != "accept" && rule.Action != "check" {
+			continue
+		}
+		if isSensitiveSSHRule(rule) && !covered[i] {
+			untested = append(untested, fmt.Sprintf("SSH Rule %d: src=%v dst=%v users=%v", i+1, rule.Src, rule.Dst, rule.Users))
+		}
+	}
+
+	if len(untested) > 0 {
+		finding.Pass = false
+		finding.Details = untested
+		if len(policy.SSHTests) == 0 {
+			finding.Description = fmt.Sprintf("No sshTests are defined, and %d SSH rule(s) grant root or sensitive access.", len(untested))
+		} else {
+			finding.Description = fmt.Sprintf("Found %d sensitive SSH rule(s) not exercised by any sshTests assertion.", len(untested))
+		}
+		finding.Fix = &types.FixInfo{
+			Type: types.FixTypeManual,
+			Description: `Add an "sshTests" section to your policy. Example:
+  "sshTests": [
+    {"src": "alice@example.com", "dst": ["tag:prod"],
+     "check": ["root"], "deny": ["admin"]}
+  ]`,
+			AdminURL: "https://login.tailscale.com/admin/acls",
+			DocURL:   "https://tailscale.com/kb/1337/policy-syntax#sshtests",
+		}
+	}
+
+	return finding
+}
+
+func (s *SSHAuditor) checkSSHTestResults(policy ACLPolicy) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "SSH-010",
+		Title:       "sshTests assertions fail against current SSH rules",
+		Severity:    types.Medium,
+		Category:    types.SSHSecurity,
+		Description: "Each sshTests assertion is evaluated locally against the parsed SSH rules. A failing assertion means access differs from what the policy author intended.",
+		Remediation: "Fix the SSH rules or update the assertion so it reflects intended access.",
+		Source:      "https://tailscale.com/kb/1337/policy-syntax#sshtests",
+		Pass:        true,
+	}
+
+	failures, _ := runSSHTests(policy)
+	if len(failures) > 0 {
+		finding.Pass = false
+		finding.Details = failures
+		finding.Description = fmt.Sprintf("Found %d failing sshTests assertion(s).", len(failures))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Review the listed assertions against the SSH rules that decided them",
+			AdminURL:    "https://login.tailscale.com/admin/acls",
+			DocURL:      "https://tailscale.com/kb/1337/policy-syntax#sshtests",
+		}
+	}
+
+	return finding
+}
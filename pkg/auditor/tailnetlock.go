@@ -0,0 +1,197 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"tailscale.com/client/tailscale"
+	"tailscale.com/ipn/ipnstate"
+	"tailscale.com/tka"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+)
+
+// lockLogLimit bounds how far back the lock log is searched for the most
+// recent checkpoint, which records the disablement values
+const lockLogLimit = 500
+
+// localLock is the local node's view of Tailnet Lock as reported by tailscaled
+type localLock struct {
+	Status *ipnstate.NetworkLockStatus
+
+	// DisablementValues is the number of disablement values in the most
+	// recent checkpoint, or -1 if no checkpoint could be read
+	DisablementValues int
+
+	// Source names where the status was read from
+	Source string
+}
+
+// readLocalLock reads Tailnet Lock state from the tailscale CLI's JSON output.
+// It falls back to the tailscaled LocalAPI when the CLI is missing or fails,
+// e.g. because it is too old to support --json.
+func readLocalLock(ctx context.Context) (*localLock, error) {
+	bin, cliErr := findTailscaleBinary()
+	if cliErr == nil {
+		lock, err := readLockCLI(ctx, bin)
+		if err == nil {
+			return lock, nil
+		}
+		cliErr = err
+	}
+
+	lock, err := readLockLocalAPI(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%v; LocalAPI: %v", cliErr, err)
+	}
+	return lock, nil
+}
+
+func readLockCLI(ctx context.Context, bin string) (*localLock, error) {
+	lock := &localLock{Source: "tailscale CLI (" + bin + ")", DisablementValues: -1}
+	if err := runTailscaleJSON(ctx, bin, &lock.Status, "lock", "status", "--json"); err != nil {
+		return nil, err
+	}
+	if lock.Status == nil {
+		return nil, errors.New("tailscale lock status: empty output")
+	}
+
+	if lock.Status.Enabled {
+		var updates []ipnstate.NetworkLockUpdate
+		if err := runTailscaleJSON(ctx, bin, &updates, "lock", "log", "--json", "--limit", strconv.Itoa(lockLogLimit)); err == nil {
+			lock.DisablementValues = disablementValues(updates)
+		}
+	}
+	return lock, nil
+}
+
+func readLockLocalAPI(ctx context.Context) (*localLock, error) {
+	var lc tailscale.LocalClient
+	st, err := lc.NetworkLockStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &localLock{Status: st, Source: "tailscaled LocalAPI", DisablementValues: -1}
+	if st.Enabled {
+		if updates, err := lc.NetworkLockLog(ctx, lockLogLimit); err == nil {
+			lock.DisablementValues = disablementValues(updates)
+		}
+	}
+	return lock, nil
+}
+
+// runTailscaleJSON runs the tailscale CLI and decodes its JSON output into v
+func runTailscaleJSON(ctx context.Context, bin string, v any, args ...string) error {
+	name := "tailscale " + strings.Join(args, " ")
+	output, err := exec.CommandContext(ctx, bin, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return fmt.Errorf("%s: %s", name, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := json.Unmarshal(output, v); err != nil {
+		return fmt.Errorf("%s: unexpected output: %w", name, err)
+	}
+	return nil
+}
+
+// disablementValues returns the number of disablement values recorded in the
+// most recent checkpoint of the lock log (newest first), or -1 if the log
+// holds no readable checkpoint
+func disablementValues(updates []ipnstate.NetworkLockUpdate) int {
+	for _, u := range updates {
+		if u.Change != tka.AUMCheckpoint.String() {
+			continue
+		}
+		var aum tka.AUM
+		if err := aum.Unserialize(u.Raw); err != nil || aum.State == nil {
+			continue
+		}
+		return len(aum.State.DisablementSecrets)
+	}
+	return -1
+}
+
+// signingKeys returns the trusted keys that belong to nodes, leaving out
+// keys created for wrapped pre-auth keys
+func signingKeys(st *ipnstate.NetworkLockStatus) (nodeKeys, preauthKeys []ipnstate.TKAKey) {
+	for _, k := range st.TrustedKeys {
+		if k.Metadata["purpose"] == "pre-auth key" {
+			preauthKeys = append(preauthKeys, k)
+			continue
+		}
+		nodeKeys = append(nodeKeys, k)
+	}
+	return nodeKeys, preauthKeys
+}
+
+// localDevice returns the audited tailnet's device for the local node, or
+// nil if the local node is logged out or belongs to a different tailnet
+func localDevice(st *ipnstate.NetworkLockStatus, devices []*client.Device) *client.Device {
+	if st == nil || st.NodeKey == nil {
+		return nil
+	}
+	nodeKey := st.NodeKey.String()
+	for _, dev := range devices {
+		if dev.NodeKey == nodeKey {
+			return dev
+		}
+	}
+	return nil
+}
+
+// lockIdentityDetails describes the local node and its signing key
+func lockIdentityDetails(lock *localLock, self *client.Device) []string {
+	st := lock.Status
+	details := []string{
+		"Read from: " + lock.Source,
+		fmt.Sprintf("Local node: %s (%s), a member of the audited tailnet", self.Name, self.NodeKey),
+	}
+	if st.Enabled {
+		if st.NodeKeySigned {
+			details = append(details, "Local node key: signed")
+		} else {
+			details = append(details, "Local node key: NOT signed (this node is locked out)")
+		}
+	}
+	if !st.PublicKey.IsZero() {
+		trusted := "not a trusted signing key"
+		for _, k := range st.TrustedKeys {
+			if k.Key == st.PublicKey {
+				trusted = "trusted signing key"
+				break
+			}
+		}
+		if !st.Enabled {
+			trusted = "not in use"
+		}
+		details = append(details, fmt.Sprintf("Local Tailnet Lock key: %s (%s)", st.PublicKey.CLIString(), trusted))
+	}
+	return details
+}
+
+// foreignNodeDetails explains why local Tailnet Lock status does not apply
+// to the audited tailnet
+func foreignNodeDetails(lock *localLock) []string {
+	details := []string{"Read from: " + lock.Source}
+	if lock.Status.NodeKey == nil {
+		details = append(details, "The local node is not logged in to any tailnet.")
+	} else {
+		details = append(details,
+			fmt.Sprintf("The local node key %s is not a device in the audited tailnet.", lock.Status.NodeKey),
+		)
+	}
+	return append(details,
+		"",
+		"Tailnet Lock status is only visible to nodes in the tailnet.",
+		"Run tailsnitch on a node in the audited tailnet, or run 'tailscale lock status' there.",
+	)
+}
@@ -0,0 +1,372 @@
+package auditor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+const (
+	// maxOwners and maxAdmins are the most owner and admin accounts a
+	// tailnet should need; more spread full control without need
+	maxOwners = 2
+	maxAdmins = 4
+
+	// adminInactiveDays is how long a privileged account may go unused
+	adminInactiveDays = 90
+)
+
+// privilegedRoles can change the tailnet's policy, devices or settings
+var privilegedRoles = map[string]bool{
+	"owner":         true,
+	"admin":         true,
+	"it-admin":      true,
+	"network-admin": true,
+}
+
+// UserAuditor checks users and their roles
+type UserAuditor struct {
+	client *client.Client
+	clock  Clock
+}
+
+// NewUserAuditor creates a new user auditor
+func NewUserAuditor(c *client.Client, clock Clock) *UserAuditor {
+	return &UserAuditor{client: c, clock: clock}
+}
+
+// Audit performs user and role checks
+func (u *UserAuditor) Audit(ctx context.Context, data *TailnetData) ([]types.Suggestion, error) {
+	var findings []types.Suggestion
+
+	users := data.Users
+	if data.UsersErr != nil {
+		// Without the users API, every check falls back to manual review
+		return []types.Suggestion{u.manualRoleReview(data.UsersErr)}, nil
+	}
+
+	// Device ownership for USER-003 and USER-005; on error the users API's
+	// device counts are used instead
+	devices, devErr := data.Devices, data.DevicesErr
+	owned := devicesByOwner(devices)
+	haveDevices := devErr == nil
+
+	// USER-001: Too many owners and admins
+	findings = append(findings, u.checkAdminCount(users))
+
+	// USER-002: Inactive privileged accounts
+	findings = append(findings, u.checkInactiveAdmins(users, u.clock.Now()))
+
+	// USER-003: Suspended users who still own devices
+	findings = append(findings, u.checkSuspendedOwners(users, owned, haveDevices))
+
+	// USER-004: External users with privileged roles
+	findings = append(findings, u.checkExternalAdmins(users, u.orgDomains(users)))
+
+	// USER-005: Privileged accounts with no devices
+	findings = append(findings, u.checkDevicelessAdmins(users, owned, haveDevices))
+
+	return findings, nil
+}
+
+// devicesByOwner groups device names by owner login name. Shared-in devices
+// are skipped since their owners belong to another tailnet.
+func devicesByOwner(devices []*client.Device) map[string][]string {
+	owned := make(map[string][]string)
+	for _, dev := range devices {
+		if !dev.IsExternal && len(dev.Tags) == 0 {
+			owner := strings.ToLower(dev.User)
+			owned[owner] = append(owned[owner], dev.Name)
+		}
+	}
+	return owned
+}
+
+// ownedDevices returns the number of devices a user owns, from the device
+// list if it was fetched or the users API's count otherwise
+func ownedDevices(user client.User, owned map[string][]string, haveDevices bool) int {
+	if haveDevices {
+		return len(owned[strings.ToLower(user.LoginName)])
+	}
+	return user.DeviceCount
+}
+
+// orgDomains returns the configured trusted domains, or infers them from the
+// tailnet name and the email domains of owners
+func (u *UserAuditor) orgDomains(users []client.User) []string {
+	var tailnet string
+	if u.client != nil {
+		tailnet = u.client.Tailnet()
+	}
+	var owners []string
+	for _, user := range users {
+		if user.Role == "owner" && user.Type != "shared" {
+			owners = append(owners, user.LoginName)
+		}
+	}
+	return inferOrgDomains(tailnet, owners)
+}
+
+// userLine describes a user for finding details
+func userLine(user client.User) string {
+	line := fmt.Sprintf("%s (%s)", user.LoginName, user.Role)
+	if user.DisplayName != "" && user.DisplayName != user.LoginName {
+		line = fmt.Sprintf("%s <%s> (%s)", user.DisplayName, user.LoginName, user.Role)
+	}
+	return line
+}
+
+func lastSeenText(user client.User) string {
+	if user.CurrentlyConnected {
+		return "connected now"
+	}
+	if user.LastSeen.IsZero() {
+		return "never seen"
+	}
+	return "last seen " + user.LastSeen.Format("2006-01-02")
+}
+
+// manualRoleReview is reported when the users API can't be read
+func (u *UserAuditor) manualRoleReview(err error) types.Suggestion {
+	return types.Suggestion{
+		ID:          "USER-001",
+		Title:       "Too many owners and admins",
+		Severity:    types.Informational,
+		Category:    types.LoggingAdmin,
+		Description: fmt.Sprintf("Cannot read users: %v. User checks USER-001 to USER-005 were skipped.", err),
+		Remediation: "Grant the API key or OAuth client the users:read scope, or review user roles in the admin console.",
+		Source:      "https://tailscale.com/kb/1352/roles",
+		Pass:        false,
+		Details: []string{
+			"MANUAL CHECK REQUIRED: Review user roles in admin console.",
+			"",
+			"Role hierarchy (highest to lowest privilege):",
+			"  - Owner: Full control, cannot be removed, irrevocable",
+			"  - Admin: Full control, can manage users/ACLs/devices",
+			"  - IT Admin: Device management only",
+			"  - Network Admin: ACL and network settings",
+			"  - Auditor: Read-only access to logs and settings",
+			"  - Billing Admin: Billing and subscription only",
+			"  - Member: Regular user, no admin access",
+			"",
+			"Security recommendations:",
+			"  - Minimize Owner accounts (ideally 1-2)",
+			"  - Review Admin accounts quarterly",
+			"  - Use specific roles (IT Admin, Network Admin) instead of full Admin",
+		},
+		Fix: &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Review and adjust user roles in admin console",
+			AdminURL:    "https://login.tailscale.com/admin/users",
+			DocURL:      "https://tailscale.com/kb/1352/roles",
+		},
+	}
+}
+
+func (u *UserAuditor) checkAdminCount(users []client.User) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "USER-001",
+		Title:       "Too many owners and admins",
+		Severity:    types.Medium,
+		Category:    types.LoggingAdmin,
+		Description: "Owners and admins have full control of the tailnet: policy, devices, keys and users. Every extra account is another credential that can take over the network.",
+		Remediation: "Keep owners to one or two and admins to a few named people. Move helpdesk and network staff to IT Admin or Network Admin roles.",
+		Source:      "https://tailscale.com/kb/1352/roles",
+		Pass:        true,
+	}
+
+	var owners, admins []string
+	for _, user := range users {
+		switch user.Role {
+		case "owner":
+			owners = append(owners, fmt.Sprintf("%s - %s", userLine(user), lastSeenText(user)))
+		case "admin":
+			admins = append(admins, fmt.Sprintf("%s - %s", userLine(user), lastSeenText(user)))
+		}
+	}
+
+	var problems []string
+	if len(owners) > maxOwners {
+		problems = append(problems, fmt.Sprintf("%d owners (at most %d recommended)", len(owners), maxOwners))
+	}
+	if len(admins) > maxAdmins {
+		problems = append(problems, fmt.Sprintf("%d admins (at most %d recommended)", len(admins), maxAdmins))
+	}
+
+	if len(problems) > 0 {
+		finding.Pass = false
+		finding.Description = fmt.Sprintf("Found %s. Full control of the tailnet is spread across more accounts than needed.", strings.Join(problems, " and "))
+		finding.Details = append(append(owners, admins...), "", fmt.Sprintf("%d users in total", len(users)))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Demote owners and admins who don't need full control",
+			AdminURL:    "https://login.tailscale.com/admin/users",
+			DocURL:      "https://tailscale.com/kb/1352/roles",
+		}
+	}
+
+	return finding
+}
+
+func (u *UserAuditor) checkInactiveAdmins(users []client.User, now time.Time) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "USER-002",
+		Title:       "Inactive privileged accounts",
+		Severity:    types.Medium,
+		Category:    types.LoggingAdmin,
+		Description: "Privileged accounts nobody uses keep their access. They often belong to people who changed roles, and their compromise would go unnoticed.",
+		Remediation: "Demote or remove privileged accounts that are no longer used. Review role assignments quarterly.",
+		Source:      "https://tailscale.com/kb/1352/roles",
+		Pass:        true,
+	}
+
+	cutoff := now.AddDate(0, 0, -adminInactiveDays)
+	var inactive []string
+	for _, user := range users {
+		if !privilegedRoles[user.Role] || user.CurrentlyConnected || user.Status == "suspended" {
+			continue
+		}
+		if user.LastSeen.Before(cutoff) {
+			inactive = append(inactive, fmt.Sprintf("%s - %s", userLine(user), lastSeenText(user)))
+		}
+	}
+
+	if len(inactive) > 0 {
+		finding.Pass = false
+		finding.Details = inactive
+		finding.Description = fmt.Sprintf("Found %d privileged account(s) not seen in over %d days.", len(inactive), adminInactiveDays)
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Demote or remove inactive privileged accounts",
+			AdminURL:    "https://login.tailscale.com/admin/users",
+			DocURL:      "https://tailscale.com/kb/1352/roles",
+		}
+	}
+
+	return finding
+}
+
+func (u *UserAuditor) checkSuspendedOwners(users []client.User, owned map[string][]string, haveDevices bool) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "USER-003",
+		Title:       "Suspended users still own devices",
+		Severity:    types.High,
+		Category:    types.LoggingAdmin,
+		Description: "Suspending or deprovisioning a user blocks their login but leaves their devices in the tailnet. The devices keep their node keys and whatever the user's tags and groups allowed.",
+		Remediation: "Remove or reassign the devices of suspended and deprovisioned users. Make device removal part of offboarding.",
+		Source:      "https://tailscale.com/kb/1178/user-suspension",
+		Pass:        true,
+	}
+
+	// SCIM deprovisioning also suspends the user
+	var suspended []string
+	for _, user := range users {
+		if user.Status != "suspended" {
+			continue
+		}
+		count := ownedDevices(user, owned, haveDevices)
+		if count == 0 {
+			continue
+		}
+		line := fmt.Sprintf("%s - %d device(s)", userLine(user), count)
+		if names := owned[strings.ToLower(user.LoginName)]; len(names) > 0 {
+			line += ": " + strings.Join(names, ", ")
+		}
+		suspended = append(suspended, line)
+	}
+
+	if len(suspended) > 0 {
+		finding.Pass = false
+		finding.Details = suspended
+		finding.Description = fmt.Sprintf("Found %d suspended user(s) who still own devices.", len(suspended))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Remove the devices of suspended users",
+			AdminURL:    "https://login.tailscale.com/admin/machines",
+			DocURL:      "https://tailscale.com/kb/1178/user-suspension",
+		}
+	}
+
+	return finding
+}
+
+func (u *UserAuditor) checkExternalAdmins(users []client.User, domains []string) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "USER-004",
+		Title:       "External users with privileged roles",
+		Severity:    types.High,
+		Category:    types.LoggingAdmin,
+		Description: "Users from outside the organization's domains with privileged roles can change the tailnet's policy and devices, but their accounts are governed by someone else's identity provider.",
+		Remediation: "Give contractors and partners the Member role, or accounts in your own identity provider. Set --domains to the domains your organization controls.",
+		Source:      "https://tailscale.com/kb/1352/roles",
+		Pass:        true,
+	}
+
+	var external []string
+	for _, user := range users {
+		if !privilegedRoles[user.Role] {
+			continue
+		}
+		switch {
+		case user.Type == "shared":
+			external = append(external, fmt.Sprintf("%s - shared-in user", userLine(user)))
+		case len(domains) > 0 && !inDomain(user.LoginName, domains):
+			external = append(external, fmt.Sprintf("%s - outside %s", userLine(user), strings.Join(domains, ", ")))
+		}
+	}
+
+	if len(external) > 0 {
+		finding.Pass = false
+		finding.Details = external
+		finding.Description = fmt.Sprintf("Found %d privileged user(s) from outside the organization's domains.", len(external))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Demote external users to Member",
+			AdminURL:    "https://login.tailscale.com/admin/users",
+			DocURL:      "https://tailscale.com/kb/1352/roles",
+		}
+	}
+
+	return finding
+}
+
+func (u *UserAuditor) checkDevicelessAdmins(users []client.User, owned map[string][]string, haveDevices bool) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "USER-005",
+		Title:       "Privileged accounts with no devices",
+		Severity:    types.Low,
+		Category:    types.LoggingAdmin,
+		Description: "A privileged account that owns no devices is either used only for the admin console or no longer used at all. Orphaned admin accounts are easy to overlook in offboarding.",
+		Remediation: "Confirm each account belongs to a current administrator. Remove orphaned accounts and use OAuth clients instead of shared admin accounts for automation.",
+		Source:      "https://tailscale.com/kb/1352/roles",
+		Pass:        true,
+	}
+
+	var deviceless []string
+	for _, user := range users {
+		if !privilegedRoles[user.Role] || user.Type == "shared" || user.Status == "suspended" {
+			continue
+		}
+		if ownedDevices(user, owned, haveDevices) == 0 {
+			deviceless = append(deviceless, fmt.Sprintf("%s - %s", userLine(user), lastSeenText(user)))
+		}
+	}
+
+	if len(deviceless) > 0 {
+		finding.Pass = false
+		finding.Details = deviceless
+		finding.Description = fmt.Sprintf("Found %d privileged account(s) that own no devices and may be orphaned.", len(deviceless))
+		finding.Fix = &types.FixInfo{
+			Type:        types.FixTypeManual,
+			Description: "Confirm ownership of these accounts or remove them",
+			AdminURL:    "https://login.tailscale.com/admin/users",
+			DocURL:      "https://tailscale.com/kb/1352/roles",
+		}
+	}
+
+	return finding
+}
@@ -0,0 +1,92 @@
+package auditor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func usersFixture(now time.Time) ([]client.User, []*client.Device) {
+	recent := now.AddDate(0, 0, -3)
+	users := []client.User{
+		{LoginName: "alice@example.com", Role: "owner", Status: "active", LastSeen: recent},
+		{LoginName: "bob@example.com", Role: "admin", Status: "idle", LastSeen: now.AddDate(0, 0, -200)},
+		{LoginName: "carol@example.com", Role: "member", Status: "suspended", LastSeen: now.AddDate(0, 0, -30)},
+		{LoginName: "dave@contractor.io", Role: "network-admin", Status: "active", CurrentlyConnected: true},
+		{LoginName: "erin@partner.com", Role: "it-admin", Type: "shared", Status: "active", LastSeen: recent},
+		{LoginName: "frank@example.com", Role: "member", Status: "suspended"},
+	}
+	devices := []*client.Device{
+		{Name: "alice-mbp.example.ts.net", User: "alice@example.com"},
+		{Name: "carol-laptop.example.ts.net", User: "carol@example.com"},
+		{Name: "ci-runner.example.ts.net", User: "carol@example.com", Tags: []string{"tag:ci"}},
+		{Name: "dave-pc.example.ts.net", User: "Dave@contractor.io"},
+	}
+	return users, devices
+}
+
+func TestUserChecks(t *testing.T) {
+	u := &UserAuditor{}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	users, devices := usersFixture(now)
+	owned := devicesByOwner(devices)
+
+	if result := u.checkAdminCount(users); !result.Pass {
+		t.Errorf("USER-001 should pass with one owner and one admin: %s", result.Description)
+	}
+
+	result := u.checkInactiveAdmins(users, now)
+	details, _ := result.Details.([]string)
+	if result.Pass || len(details) != 1 || !strings.HasPrefix(details[0], "bob@example.com (admin)") {
+		t.Errorf("USER-002 Details = %v", details)
+	}
+
+	result = u.checkSuspendedOwners(users, owned, true)
+	details, _ = result.Details.([]string)
+	if result.Pass || result.Severity != types.High || len(details) != 1 || details[0] != "carol@example.com (member) - 1 device(s): carol-laptop.example.ts.net" {
+		t.Errorf("USER-003 Details = %v", details)
+	}
+
+	result = u.checkExternalAdmins(users, u.orgDomains(users))
+	joined := strings.Join(result.Details.([]string), "\n")
+	if result.Pass || !strings.Contains(joined, "dave@contractor.io (network-admin) - outside example.com") || !strings.Contains(joined, "erin@partner.com (it-admin) - shared-in user") {
+		t.Errorf("USER-004 Details =\n%s", joined)
+	}
+
+	result = u.checkDevicelessAdmins(users, owned, true)
+	details, _ = result.Details.([]string)
+	if result.Pass || len(details) != 1 || !strings.HasPrefix(details[0], "bob@example.com") {
+		t.Errorf("USER-005 Details = %v", details)
+	}
+}
+
+func TestUserChecksFallbacks(t *testing.T) {
+	u := &UserAuditor{}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	users, _ := usersFixture(now)
+
+	// Without the device list, the users API's device counts apply
+	users[5].DeviceCount = 2
+	result := u.checkSuspendedOwners(users, nil, false)
+	details, _ := result.Details.([]string)
+	if len(details) != 1 || details[0] != "frank@example.com (member) - 2 device(s)" {
+		t.Errorf("USER-003 with device counts = %v", details)
+	}
+
+	SetTrustedDomains([]string{"example.com", "contractor.io"})
+	defer SetTrustedDomains(nil)
+	result = u.checkExternalAdmins(users, u.orgDomains(users))
+	if details, _ := result.Details.([]string); len(details) != 1 || !strings.Contains(details[0], "shared-in user") {
+		t.Errorf("USER-004 with --domains = %v", details)
+	}
+
+	for i := 0; i < 3; i++ {
+		users = append(users, client.User{LoginName: "owner@example.com", Role: "owner"})
+	}
+	if result := u.checkAdminCount(users); result.Pass || !strings.Contains(result.Description, "4 owners") {
+		t.Errorf("USER-001 with 4 owners: %s", result.Description)
+	}
+}
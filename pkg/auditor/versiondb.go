@@ -0,0 +1,286 @@
+package auditor
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// embeddedVersionDB is the release and security bulletin snapshot shipped
+// with the binary, so DEV-003 works without outbound internet access
+//
+//go:embed data/tailscale-versions.json
+var embeddedVersionDB []byte
+
+// autoUpdateGracePeriod is how long Tailscale takes to roll out auto-updates;
+// releases younger than this aren't expected on devices yet
+const autoUpdateGracePeriod = 7 * 24 * time.Hour
+
+// versionDB holds known Tailscale releases and security bulletins
+type versionDB struct {
+	Updated   string             `json:"updated"`
+	Source    string             `json:"source"`
+	Releases  []versionRelease   `json:"releases"`
+	Bulletins []securityBulletin `json:"bulletins"`
+}
+
+// versionRelease is a stable Tailscale release and its publication date (YYYY-MM-DD)
+type versionRelease struct {
+	Version string `json:"version"`
+	Date    string `json:"date"`
+}
+
+// securityBulletin is a Tailscale security bulletin (TS-YYYY-NNN). A client
+// is affected when its version is >= Introduced (if set) and < Fixed, and
+// its OS is listed in OS (an empty list means every platform). Published is
+// the bulletin date (YYYY-MM-DD).
+type securityBulletin struct {
+	ID         string         `json:"id"`
+	Title      string         `json:"title"`
+	Severity   types.Severity `json:"severity"`
+	Published  string         `json:"published,omitempty"`
+	CVEs       []string       `json:"cves,omitempty"`
+	OS         []string       `json:"os,omitempty"`
+	Introduced string         `json:"introduced,omitempty"`
+	Fixed      string         `json:"fixed"`
+	URL        string         `json:"url"`
+}
+
+var (
+	embeddedDBOnce sync.Once
+	embeddedDB     *versionDB
+)
+
+// versionDBOverride is set via CLI flag --version-db
+var versionDBOverride *versionDB
+
+// SetVersionDBPath loads a release and bulletin data file to use in place of
+// the embedded snapshot. The file uses the same format as
+// pkg/auditor/data/tailscale-versions.json.
+func SetVersionDBPath(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read version data %q: %w", path, err)
+	}
+
+	db, err := parseVersionDB(data)
+	if err != nil {
+		return fmt.Errorf("invalid version data %q: %w", path, err)
+	}
+
+	versionDBOverride = db
+	return nil
+}
+
+// loadVersionDB returns the configured version data, falling back to the embedded snapshot
+func loadVersionDB() *versionDB {
+	if versionDBOverride != nil {
+		return versionDBOverride
+	}
+	embeddedDBOnce.Do(func() {
+		db, err := parseVersionDB(embeddedVersionDB)
+		if err != nil {
+			// The embedded file is validated by tests; treat a bad build as "no data"
+			db = &versionDB{}
+		}
+		embeddedDB = db
+	})
+	return embeddedDB
+}
+
+// parseVersionDB decodes and validates a version data file
+func parseVersionDB(data []byte) (*versionDB, error) {
+	var db versionDB
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	for _, r := range db.Releases {
+		if _, ok := parseSemver(r.Version); !ok {
+			return nil, fmt.Errorf("release %q: invalid version", r.Version)
+		}
+		if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+			return nil, fmt.Errorf("release %q: invalid date %q", r.Version, r.Date)
+		}
+	}
+	for _, b := range db.Bulletins {
+		if _, ok := parseSemver(b.Fixed); !ok {
+			return nil, fmt.Errorf("bulletin %s: invalid fixed version %q", b.ID, b.Fixed)
+		}
+		if b.Introduced != "" {
+			if _, ok := parseSemver(b.Introduced); !ok {
+				return nil, fmt.Errorf("bulletin %s: invalid introduced version %q", b.ID, b.Introduced)
+			}
+		}
+		if b.Published != "" {
+			if _, err := time.Parse("2006-01-02", b.Published); err != nil {
+				return nil, fmt.Errorf("bulletin %s: invalid published date %q", b.ID, b.Published)
+			}
+		}
+	}
+
+	return &db, nil
+}
+
+// semver is a parsed major.minor.patch version
+type semver [3]int
+
+var semverRegex = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseSemver extracts major.minor.patch from a version string such as
+// "v1.76.6" or a client version like "1.76.6-t1234abcd-g5678ef". A missing
+// patch component is treated as 0.
+func parseSemver(versionStr string) (semver, bool) {
+	var v semver
+	matches := semverRegex.FindStringSubmatch(versionStr)
+	if matches == nil {
+		return v, false
+	}
+	for i := 0; i < 3; i++ {
+		if matches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 as v is older than, equal to or newer than other
+func (v semver) compare(other semver) int {
+	for i := 0; i < 3; i++ {
+		if v[i] < other[i] {
+			return -1
+		}
+		if v[i] > other[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// expectedRelease returns the newest release in the data that is past the
+// auto-update grace period at now
+func (db *versionDB) expectedRelease(now time.Time) (versionRelease, semver, bool) {
+	type candidate struct {
+		release versionRelease
+		version semver
+	}
+	var candidates []candidate
+	for _, r := range db.Releases {
+		date, err := time.Parse("2006-01-02", r.Date)
+		if err != nil || now.Sub(date) < autoUpdateGracePeriod {
+			continue
+		}
+		if v, ok := parseSemver(r.Version); ok {
+			candidates = append(candidates, candidate{r, v})
+		}
+	}
+	if len(candidates) == 0 {
+		return versionRelease{}, semver{}, false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].version.compare(candidates[j].version) > 0
+	})
+	return candidates[0].release, candidates[0].version, true
+}
+
+// bulletinCoverage returns the date of the newest bulletin in the data and
+// whether the data lists a release newer than it, in which case bulletins
+// published since may be missing. Dates are YYYY-MM-DD, so they compare as
+// strings.
+func (db *versionDB) bulletinCoverage() (through string, stale bool) {
+	for _, b := range db.Bulletins {
+		if b.Published > through {
+			through = b.Published
+		}
+	}
+	for _, r := range db.Releases {
+		if r.Date > through {
+			stale = true
+		}
+	}
+	return through, stale
+}
+
+// affects reports whether a device's OS and client version fall in the bulletin's affected range
+func (b securityBulletin) affects(dev *client.Device) bool {
+	if dev.ClientVersion == "" {
+		return false
+	}
+	if len(b.OS) > 0 && !contains(b.OS, dev.OS) {
+		return false
+	}
+
+	version, ok := parseSemver(dev.ClientVersion)
+	if !ok {
+		return false
+	}
+	fixed, _ := parseSemver(b.Fixed)
+	if version.compare(fixed) >= 0 {
+		return false
+	}
+	if b.Introduced != "" {
+		introduced, _ := parseSemver(b.Introduced)
+		if version.compare(introduced) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// deviceBulletins returns the bulletins a device is vulnerable to
+func deviceBulletins(dev *client.Device) []securityBulletin {
+	var matched []securityBulletin
+	for _, b := range loadVersionDB().Bulletins {
+		if b.affects(dev) {
+			matched = append(matched, b)
+		}
+	}
+	return matched
+}
+
+// bulletinHits returns the bulletins affecting any device, sorted by ID, and the affected devices for each bulletin ID
+func bulletinHits(devices []*client.Device) ([]securityBulletin, map[string][]string) {
+	var hits []securityBulletin
+	affected := make(map[string][]string)
+	for _, dev := range devices {
+		for _, b := range deviceBulletins(dev) {
+			if _, seen := affected[b.ID]; !seen {
+				hits = append(hits, b)
+			}
+			affected[b.ID] = append(affected[b.ID], fmt.Sprintf("%s (%s)", dev.Name, dev.ClientVersion))
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].ID < hits[j].ID })
+	return hits, affected
+}
+
+// bulletinSeverity returns the finding severity for a set of bulletin hits:
+// High if any bulletin is high or critical, otherwise Medium
+func bulletinSeverity(bulletins []securityBulletin) types.Severity {
+	for _, b := range bulletins {
+		if strings.EqualFold(string(b.Severity), string(types.Critical)) || strings.EqualFold(string(b.Severity), string(types.High)) {
+			return types.High
+		}
+	}
+	return types.Medium
+}
@@ -0,0 +1,208 @@
+package auditor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestEmbeddedVersionDB(t *testing.T) {
+	db, err := parseVersionDB(embeddedVersionDB)
+	if err != nil {
+		t.Fatalf("embedded version data is invalid: %v", err)
+	}
+	if len(db.Releases) == 0 {
+		t.Error("embedded version data has no releases")
+	}
+	if len(db.Bulletins) == 0 {
+		t.Error("embedded version data has no bulletins")
+	}
+	if _, err := time.Parse("2006-01-02", db.Updated); err != nil {
+		t.Errorf("embedded version data has invalid updated date %q", db.Updated)
+	}
+}
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   semver
+		wantOK bool
+	}{
+		{"v1.76.6", semver{1, 76, 6}, true},
+		{"1.58.2-t1234abcd-g5678efgh", semver{1, 58, 2}, true},
+		{"1.62", semver{1, 62, 0}, true},
+		{"unknown", semver{}, false},
+		{"", semver{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseSemver(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseSemver(%q) = %v, %v; want %v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSecurityBulletinAffects(t *testing.T) {
+	windowsOnly := securityBulletin{ID: "TS-0000-001", OS: []string{"windows"}, Fixed: "1.32.3"}
+	ranged := securityBulletin{ID: "TS-0000-002", Introduced: "1.40.0", Fixed: "1.42.1"}
+
+	tests := []struct {
+		name     string
+		bulletin securityBulletin
+		dev      *client.Device
+		want     bool
+	}{
+		{"matching OS below fix", windowsOnly, &client.Device{OS: "windows", ClientVersion: "1.32.2-t1"}, true},
+		{"OS match is case-insensitive", windowsOnly, &client.Device{OS: "Windows", ClientVersion: "1.30.0"}, true},
+		{"at fixed version", windowsOnly, &client.Device{OS: "windows", ClientVersion: "1.32.3"}, false},
+		{"other OS", windowsOnly, &client.Device{OS: "linux", ClientVersion: "1.30.0"}, false},
+		{"no client version", windowsOnly, &client.Device{OS: "windows"}, false},
+		{"inside range", ranged, &client.Device{OS: "linux", ClientVersion: "1.42.0"}, true},
+		{"before introduced", ranged, &client.Device{OS: "linux", ClientVersion: "1.38.4"}, false},
+		{"after fix", ranged, &client.Device{OS: "macOS", ClientVersion: "1.44.0"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bulletin.affects(tt.dev); got != tt.want {
+				t.Errorf("affects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedRelease(t *testing.T) {
+	db := &versionDB{
+		Releases: []versionRelease{
+			{Version: "1.78.1", Date: "2024-12-01"},
+			{Version: "1.80.0", Date: "2025-01-20"},
+			{Version: "1.76.6", Date: "2024-11-06"},
+		},
+	}
+
+	// 1.80.0 is still inside the auto-update grace period
+	release, v, ok := db.expectedRelease(time.Date(2025, 1, 22, 0, 0, 0, 0, time.UTC))
+	if !ok || release.Version != "1.78.1" || v != (semver{1, 78, 1}) {
+		t.Errorf("expectedRelease() = %v, %v, %v; want 1.78.1", release, v, ok)
+	}
+
+	if _, _, ok := db.expectedRelease(time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("expectedRelease() should find nothing before the first release")
+	}
+}
+
+func TestBulletinHits(t *testing.T) {
+	orig := versionDBOverride
+	defer func() { versionDBOverride = orig }()
+	versionDBOverride = &versionDB{
+		Bulletins: []securityBulletin{
+			{ID: "TS-0000-001", Title: "Critical bug", Severity: types.Critical, OS: []string{"windows"}, Fixed: "1.32.3"},
+		},
+	}
+
+	devices := []*client.Device{
+		{Name: "win", OS: "windows", ClientVersion: "1.32.2"},
+		{Name: "linux", OS: "linux", ClientVersion: "1.32.2"},
+	}
+	hits, affected := bulletinHits(devices)
+	if len(hits) != 1 || len(affected["TS-0000-001"]) != 1 {
+		t.Fatalf("expected 1 bulletin affecting 1 device, got %v %v", hits, affected)
+	}
+
+	// A critical bulletin raises the device's DEV-003 failure to High even
+	// when the device isn't behind the expected version
+	inventory := buildDeviceInventory(context.Background(), devices[:1], nil, nil, 1, 32, Clock{})
+	failed := inventory.Devices[0].FailedChecks
+	found := false
+	for _, f := range failed {
+		if f.CheckID == "DEV-003" && f.Severity == types.High {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected DEV-003 High failure in inventory, got %v", failed)
+	}
+}
+
+func TestSetVersionDBPath(t *testing.T) {
+	orig := versionDBOverride
+	defer func() { versionDBOverride = orig }()
+
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"updated":"2030-01-01","releases":[{"version":"9.0.0","date":"2030-01-01"}],"bulletins":[]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetVersionDBPath(valid); err != nil {
+		t.Fatalf("SetVersionDBPath(valid) error: %v", err)
+	}
+	if got := loadVersionDB().Updated; got != "2030-01-01" {
+		t.Errorf("loadVersionDB().Updated = %q, want override", got)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"bulletins":[{"id":"TS-X","fixed":"latest"}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetVersionDBPath(invalid); err == nil {
+		t.Error("SetVersionDBPath(invalid) should fail on a bad fixed version")
+	}
+
+	if err := SetVersionDBPath(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("SetVersionDBPath(missing) should fail")
+	}
+}
+
+func TestBulletinCoverage(t *testing.T) {
+	db := &versionDB{
+		Releases:  []versionRelease{{Version: "1.80.0", Date: "2025-01-20"}},
+		Bulletins: []securityBulletin{{ID: "TS-0000-001", Published: "2025-02-01"}, {ID: "TS-0000-002", Published: "2024-06-01"}},
+	}
+	if through, stale := db.bulletinCoverage(); through != "2025-02-01" || stale {
+		t.Errorf("bulletinCoverage() = %q, %v; want 2025-02-01, false", through, stale)
+	}
+
+	db.Releases = append(db.Releases, versionRelease{Version: "1.82.0", Date: "2025-03-10"})
+	if _, stale := db.bulletinCoverage(); !stale {
+		t.Error("bulletinCoverage() should be stale when a release is newer than the newest bulletin")
+	}
+}
+
+func TestEmbeddedBulletinCoverageReported(t *testing.T) {
+	db, err := parseVersionDB(embeddedVersionDB)
+	if err != nil {
+		t.Fatalf("embedded version data is invalid: %v", err)
+	}
+	through, stale := db.bulletinCoverage()
+	if through == "" {
+		t.Fatal("embedded bulletins have no published dates")
+	}
+
+	// When the bundled bulletins are older than the newest bundled release,
+	// DEV-003 has to say so rather than passing silently
+	orig := versionDBOverride
+	defer func() { versionDBOverride = orig }()
+	versionDBOverride = db
+
+	// A cancelled context keeps the GitHub release lookup offline
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d := &DeviceAuditor{}
+	finding := d.checkOutdatedClients(ctx, nil)
+	if !strings.Contains(finding.Description, "published through "+through) {
+		t.Errorf("DEV-003 description doesn't state bulletin coverage: %q", finding.Description)
+	}
+	if stale != strings.Contains(finding.Description, "newer bulletins may be missing") {
+		t.Errorf("DEV-003 description staleness note = %v, want %v: %q", !stale, stale, finding.Description)
+	}
+}
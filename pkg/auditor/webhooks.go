@@ -0,0 +1,189 @@
+package auditor
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// webhookSecretMaxAgeDays is how long a webhook secret may go unrotated
+const webhookSecretMaxAgeDays = 90
+
+// criticalWebhookEvents are the events a tailnet should send to an alerting
+// system, in the order they are reported
+var criticalWebhookEvents = []struct {
+	Event  string
+	Reason string
+}{
+	{"policyUpdate", "tailnet policy file changed"},
+	{"nodeNeedsApproval", "device waiting for approval"},
+	{"nodeApproved", "device approved"},
+	{"userRoleUpdated", "user role changed"},
+	{"nodeNeedsSignature", "device waiting for a Tailnet Lock signature"},
+	{"nodeSigned", "device signed by Tailnet Lock"},
+}
+
+// webhookLine describes a webhook endpoint for finding details
+func webhookLine(hook client.Webhook) string {
+	line := hook.EndpointURL
+	if hook.ProviderType != "" {
+		line += " (" + hook.ProviderType + ")"
+	}
+	if hook.CreatorLoginName != "" {
+		line += " created by " + hook.CreatorLoginName
+	}
+	return line
+}
+
+// webhookSecretDate returns when a webhook was last changed. The API doesn't
+// report secret rotation, so the last modification (or creation) stands in
+// for it.
+func webhookSecretDate(hook client.Webhook) time.Time {
+	if !hook.LastModified.IsZero() {
+		return hook.LastModified
+	}
+	return hook.Created
+}
+
+// manualWebhookReview is reported in place of a webhook check when the
+// webhooks API can't be read
+func manualWebhookReview(finding types.Suggestion, err error, details ...string) types.Suggestion {
+	finding.Severity = types.Informational
+	finding.Pass = false
+	finding.Description = fmt.Sprintf("Cannot read webhooks: %v.", err)
+	finding.Remediation = "Grant the API key or OAuth client the webhooks:read scope, or review webhooks in the admin console."
+	finding.Details = append([]string{"MANUAL CHECK REQUIRED: Review webhook endpoints in admin console."}, details...)
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Review webhook endpoints in admin console",
+		AdminURL:    "https://login.tailscale.com/admin/settings/webhooks",
+		DocURL:      "https://tailscale.com/kb/1213/webhooks",
+	}
+	return finding
+}
+
+func (l *LoggingAuditor) checkWebhookSecrets(webhooks []client.Webhook, err error, now time.Time) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOG-005",
+		Title:       "Webhook secrets not rotated or sent in plaintext",
+		Severity:    types.Medium,
+		Category:    types.LoggingAdmin,
+		Description: "Webhook endpoint secrets have no automatic expiration. If a secret leaks, anyone can send fake events that look signed by Tailscale, and endpoints without HTTPS expose events and signatures in transit.",
+		Remediation: fmt.Sprintf("Rotate webhook secrets at least every %d days and store them securely. Use HTTPS endpoint URLs only.", webhookSecretMaxAgeDays),
+		Source:      "https://tailscale.com/kb/1213/webhooks",
+		Pass:        true,
+	}
+
+	if err != nil {
+		return manualWebhookReview(finding, err, "Rotate webhook secrets on a schedule and check endpoints use HTTPS.")
+	}
+
+	cutoff := now.AddDate(0, 0, -webhookSecretMaxAgeDays)
+	var plaintext, stale []string
+	for _, hook := range webhooks {
+		if u, perr := url.Parse(hook.EndpointURL); perr != nil || !strings.EqualFold(u.Scheme, "https") {
+			plaintext = append(plaintext, "  - "+webhookLine(hook))
+		}
+		if changed := webhookSecretDate(hook); !changed.IsZero() && changed.Before(cutoff) {
+			stale = append(stale, fmt.Sprintf("  - %s - unchanged since %s", webhookLine(hook), changed.Format("2006-01-02")))
+		}
+	}
+
+	if len(plaintext) == 0 && len(stale) == 0 {
+		return finding
+	}
+
+	finding.Pass = false
+	var problems, details []string
+	if len(plaintext) > 0 {
+		finding.Severity = types.High
+		problems = append(problems, fmt.Sprintf("%d webhook endpoint(s) without HTTPS", len(plaintext)))
+		details = append(details, "Endpoints without HTTPS:")
+		details = append(details, plaintext...)
+	}
+	if len(stale) > 0 {
+		problems = append(problems, fmt.Sprintf("%d webhook secret(s) not rotated in over %d days", len(stale), webhookSecretMaxAgeDays))
+		if len(details) > 0 {
+			details = append(details, "")
+		}
+		details = append(details, "Secrets not rotated (last change to the endpoint, the API doesn't report rotation):")
+		details = append(details, stale...)
+	}
+	finding.Details = details
+	finding.Description = fmt.Sprintf("Found %s.", strings.Join(problems, " and "))
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Rotate webhook secrets and move endpoints to HTTPS in admin console",
+		AdminURL:    "https://login.tailscale.com/admin/settings/webhooks",
+		DocURL:      "https://tailscale.com/kb/1213/webhooks",
+	}
+
+	return finding
+}
+
+func (l *LoggingAuditor) checkWebhookEvents(webhooks []client.Webhook, err error) types.Suggestion {
+	finding := types.Suggestion{
+		ID:          "LOG-012",
+		Title:       "Webhooks for critical events",
+		Severity:    types.Medium,
+		Category:    types.LoggingAdmin,
+		Description: "Webhooks notify external systems about critical tailnet events such as policy changes, device approvals, role changes and Tailnet Lock signing. Without them, these changes are only visible in the audit log.",
+		Remediation: "Configure a webhook to your SIEM or alerting system subscribed to every critical event type.",
+		Source:      "https://tailscale.com/kb/1213/webhooks",
+		Pass:        true,
+	}
+
+	var recommended []string
+	for _, e := range criticalWebhookEvents {
+		recommended = append(recommended, fmt.Sprintf("  - %s: %s", e.Event, e.Reason))
+	}
+
+	if err != nil {
+		return manualWebhookReview(finding, err, append([]string{"", "Recommended webhook events to enable:"}, recommended...)...)
+	}
+
+	if len(webhooks) == 0 {
+		finding.Pass = false
+		finding.Description = "No webhook endpoints are configured. Policy changes, device approvals, role changes and Tailnet Lock events reach no alerting system."
+		finding.Details = append([]string{"Recommended webhook events to enable:"}, recommended...)
+	} else {
+		subscribed := make(map[string]bool)
+		for _, hook := range webhooks {
+			for _, event := range hook.Subscriptions {
+				subscribed[event] = true
+			}
+		}
+
+		var missing []string
+		for _, e := range criticalWebhookEvents {
+			if !subscribed[e.Event] {
+				missing = append(missing, fmt.Sprintf("  - %s: %s", e.Event, e.Reason))
+			}
+		}
+		if len(missing) == 0 {
+			return finding
+		}
+
+		finding.Pass = false
+		finding.Description = fmt.Sprintf("Found %d critical event type(s) no webhook is subscribed to.", len(missing))
+		details := append([]string{"Events without a webhook:"}, missing...)
+		details = append(details, "", "Configured endpoints:")
+		for _, hook := range webhooks {
+			details = append(details, fmt.Sprintf("  - %s: %s", webhookLine(hook), strings.Join(hook.Subscriptions, ", ")))
+		}
+		finding.Details = details
+	}
+
+	finding.Fix = &types.FixInfo{
+		Type:        types.FixTypeManual,
+		Description: "Subscribe a webhook to critical events in admin console",
+		AdminURL:    "https://login.tailscale.com/admin/settings/webhooks",
+		DocURL:      "https://tailscale.com/kb/1213/webhooks",
+	}
+
+	return finding
+}
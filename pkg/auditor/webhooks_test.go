@@ -0,0 +1,77 @@
+package auditor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/client"
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestWebhookSecrets(t *testing.T) {
+	l := &LoggingAuditor{}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	webhooks := []client.Webhook{
+		{EndpointURL: "https://siem.example.com/hook", Created: now.AddDate(0, 0, -10)},
+		{EndpointURL: "https://hooks.slack.com/services/x", ProviderType: "slack", Created: now.AddDate(-1, 0, 0), LastModified: now.AddDate(0, 0, -30)},
+	}
+	if result := l.checkWebhookSecrets(webhooks, nil, now); !result.Pass {
+		t.Errorf("LOG-005 should pass with recent HTTPS webhooks: %s", result.Description)
+	}
+
+	webhooks = append(webhooks,
+		client.Webhook{EndpointURL: "http://alerts.internal:8080/tailscale", CreatorLoginName: "bob@example.com", Created: now.AddDate(0, 0, -5)},
+		client.Webhook{EndpointURL: "https://old.example.com/hook", Created: now.AddDate(0, 0, -200)},
+	)
+	result := l.checkWebhookSecrets(webhooks, nil, now)
+	joined := strings.Join(result.Details.([]string), "\n")
+	if result.Pass || result.Severity != types.High {
+		t.Errorf("LOG-005 Pass = %v, Severity = %v", result.Pass, result.Severity)
+	}
+	if !strings.Contains(joined, "http://alerts.internal:8080/tailscale created by bob@example.com") || !strings.Contains(joined, "https://old.example.com/hook - unchanged since 2026-04-01") {
+		t.Errorf("LOG-005 Details =\n%s", joined)
+	}
+	if strings.Contains(joined, "hooks.slack.com") {
+		t.Errorf("LOG-005 should use lastModified over created:\n%s", joined)
+	}
+
+	result = l.checkWebhookSecrets(nil, errors.New("403 Forbidden"), now)
+	if result.Pass || result.Severity != types.Informational {
+		t.Errorf("LOG-005 should fall back to manual review on API error, got %v", result.Severity)
+	}
+}
+
+func TestWebhookEvents(t *testing.T) {
+	l := &LoggingAuditor{}
+
+	result := l.checkWebhookEvents(nil, nil)
+	if result.Pass || !strings.HasPrefix(result.Description, "No webhook endpoints") {
+		t.Errorf("LOG-012 with no webhooks = %s", result.Description)
+	}
+
+	webhooks := []client.Webhook{
+		{EndpointURL: "https://siem.example.com/hook", Subscriptions: []string{"policyUpdate", "nodeNeedsApproval", "nodeApproved"}},
+		{EndpointURL: "https://hooks.slack.com/services/x", Subscriptions: []string{"userRoleUpdated", "nodeCreated"}},
+	}
+	result = l.checkWebhookEvents(webhooks, nil)
+	joined := strings.Join(result.Details.([]string), "\n")
+	if result.Pass || result.Description != "Found 2 critical event type(s) no webhook is subscribed to." {
+		t.Errorf("LOG-012 Description = %s", result.Description)
+	}
+	if !strings.Contains(joined, "nodeNeedsSignature") || !strings.Contains(joined, "nodeSigned") || strings.Contains(joined, "  - policyUpdate") {
+		t.Errorf("LOG-012 Details =\n%s", joined)
+	}
+
+	webhooks[1].Subscriptions = append(webhooks[1].Subscriptions, "nodeNeedsSignature", "nodeSigned")
+	if result = l.checkWebhookEvents(webhooks, nil); !result.Pass {
+		t.Errorf("LOG-012 should pass when every critical event is covered: %s", result.Description)
+	}
+
+	result = l.checkWebhookEvents(nil, errors.New("403 Forbidden"))
+	if result.Pass || result.Severity != types.Informational || !strings.Contains(strings.Join(result.Details.([]string), "\n"), "policyUpdate") {
+		t.Errorf("LOG-012 should fall back to manual review listing events on API error")
+	}
+}
@@ -2,9 +2,13 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -15,6 +19,9 @@ import (
 )
 
 const (
+	// maxResponseSize caps how much of a raw API response is read (10MB)
+	maxResponseSize = 10 << 20
+
 	// DefaultRateLimit is the default number of requests per second
 	DefaultRateLimit = 10
 	// DefaultBurstSize is the default burst size for rate limiting
@@ -258,7 +265,8 @@ func (c *Client) GetDevices(ctx context.Context) ([]*tailscale.Device, error) {
 	if err := c.wait(ctx); err != nil {
 		return nil, err
 	}
-	devices, err := c.ts.Devices(ctx, nil)
+	// Request all fields: the default set omits advertised/enabled routes
+	devices, err := c.ts.Devices(ctx, tailscale.DeviceAllFields)
 	if err != nil {
 		return nil, classifyError(err, "GetDevices", "devices")
 	}
@@ -301,6 +309,81 @@ func (c *Client) GetKey(ctx context.Context, keyID string) (*tailscale.Key, erro
 	return key, nil
 }
 
+// Key types reported by the keys API
+const (
+	KeyTypeAuth        = "auth"
+	KeyTypeAPI         = "api"
+	KeyTypeOAuthClient = "client"
+	KeyTypeFederated   = "federated"
+)
+
+// KeyEntry is a key of any type as returned by the keys API: an auth key,
+// API access token, OAuth client or federated identity
+type KeyEntry struct {
+	ID           string          `json:"id"`
+	KeyType      string          `json:"keyType"`
+	Description  string          `json:"description"`
+	Created      time.Time       `json:"created"`
+	Expires      time.Time       `json:"expires"`  // Zero for OAuth clients, which don't expire
+	Revoked      time.Time       `json:"revoked"`  // Zero unless revoked
+	LastUsed     time.Time       `json:"lastUsed"` // Zero if the API doesn't report it
+	Invalid      bool            `json:"invalid"`
+	UserID       string          `json:"userId"` // The creator
+	Scopes       []string        `json:"scopes"` // API tokens and OAuth clients
+	Tags         []string        `json:"tags"`
+	Capabilities KeyCapabilities `json:"capabilities"` // Auth keys
+}
+
+// Type returns the key type, treating keys listed without one as auth keys
+func (k KeyEntry) Type() string {
+	if k.KeyType == "" {
+		return KeyTypeAuth
+	}
+	return k.KeyType
+}
+
+// GetAllKeys fetches every key in the tailnet, including API access tokens,
+// OAuth clients and keys created by other users. Entries the list returns
+// without details are fetched individually; a key whose details cannot be
+// fetched is left out of the result and described in skipped instead of
+// failing the whole listing.
+func (c *Client) GetAllKeys(ctx context.Context) (keys []KeyEntry, skipped []string, err error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	var resp struct {
+		Keys []KeyEntry `json:"keys"`
+	}
+	path := fmt.Sprintf("/api/v2/tailnet/%s/keys?all=true", url.PathEscape(c.tailnet))
+	if err := c.getJSON(ctx, path, &resp); err != nil {
+		return nil, nil, classifyError(err, "GetAllKeys", "keys")
+	}
+
+	keys = make([]KeyEntry, 0, len(resp.Keys))
+	for _, key := range resp.Keys {
+		if key.Created.IsZero() {
+			if err := c.fetchKey(ctx, &key); err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s: %v", key.ID, err))
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, skipped, nil
+}
+
+// fetchKey fills in key with the details of the key with its ID
+func (c *Client) fetchKey(ctx context.Context, key *KeyEntry) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/v2/tailnet/%s/keys/%s", url.PathEscape(c.tailnet), url.PathEscape(key.ID))
+	if err := c.getJSON(ctx, path, key); err != nil {
+		return classifyError(err, "GetAllKeys", fmt.Sprintf("key %s", key.ID))
+	}
+	return nil
+}
+
 // GetDNSConfig fetches the DNS configuration
 func (c *Client) GetDNSConfig(ctx context.Context) (*DNSConfig, error) {
 	if err := c.wait(ctx); err != nil {
@@ -448,6 +531,354 @@ func (c *Client) SetACLHuJSONWithCollisionCheck(ctx context.Context, acl *tailsc
 	return result, nil
 }
 
+// apiBaseURL returns the API server the underlying client talks to
+func (c *Client) apiBaseURL() string {
+	if c.ts.BaseURL != "" {
+		return c.ts.BaseURL
+	}
+	return "https://api.tailscale.com"
+}
+
+// getJSON issues a GET for an API path the tailscale client library doesn't
+// wrap and decodes the JSON response into v
+func (c *Client) getJSON(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiBaseURL()+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.ts.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// DevicePosture holds a device's posture attributes, including the built-in
+// node:os and node:osVersion attributes, and when each attribute set with an
+// expiry expires
+type DevicePosture struct {
+	Attributes map[string]interface{} `json:"attributes"`
+	Expiries   map[string]time.Time   `json:"expiries"`
+}
+
+// GetDevicePosture fetches the posture attributes of a device
+func (c *Client) GetDevicePosture(ctx context.Context, deviceID string) (*DevicePosture, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	var posture DevicePosture
+	path := fmt.Sprintf("/api/v2/device/%s/attributes", url.PathEscape(deviceID))
+	if err := c.getJSON(ctx, path, &posture); err != nil {
+		return nil, classifyError(err, "GetDevicePosture", fmt.Sprintf("posture attributes for device %s", deviceID))
+	}
+	return &posture, nil
+}
+
+// User is a tailnet user as returned by the users API
+type User struct {
+	ID                 string    `json:"id"`
+	DisplayName        string    `json:"displayName"`
+	LoginName          string    `json:"loginName"`
+	Created            time.Time `json:"created"`
+	Type               string    `json:"type"`   // "member" or "shared"
+	Role               string    `json:"role"`   // "owner", "admin", "it-admin", "network-admin", "billing-admin", "auditor" or "member"
+	Status             string    `json:"status"` // "active", "idle", "suspended", "needs-approval" or "over-billing-limit"
+	DeviceCount        int       `json:"deviceCount"`
+	LastSeen           time.Time `json:"lastSeen"`
+	CurrentlyConnected bool      `json:"currentlyConnected"`
+}
+
+// GetUsers fetches all users in the tailnet, including users of shared-in devices
+func (c *Client) GetUsers(ctx context.Context) ([]User, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Users []User `json:"users"`
+	}
+	path := fmt.Sprintf("/api/v2/tailnet/%s/users", url.PathEscape(c.tailnet))
+	if err := c.getJSON(ctx, path, &resp); err != nil {
+		return nil, classifyError(err, "GetUsers", "users")
+	}
+	return resp.Users, nil
+}
+
+// Webhook is a webhook endpoint as returned by the webhooks API. The secret
+// is only returned when an endpoint is created or its secret rotated.
+type Webhook struct {
+	EndpointID       string    `json:"endpointId"`
+	EndpointURL      string    `json:"endpointUrl"`
+	ProviderType     string    `json:"providerType"` // "slack", "mattermost", "googlechat", "discord" or empty
+	CreatorLoginName string    `json:"creatorLoginName"`
+	Created          time.Time `json:"created"`
+	LastModified     time.Time `json:"lastModified"`
+	Subscriptions    []string  `json:"subscriptions"`
+}
+
+// GetWebhooks fetches the tailnet's webhook endpoints and their subscribed events
+func (c *Client) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+	path := fmt.Sprintf("/api/v2/tailnet/%s/webhooks", url.PathEscape(c.tailnet))
+	if err := c.getJSON(ctx, path, &resp); err != nil {
+		return nil, classifyError(err, "GetWebhooks", "webhooks")
+	}
+	return resp.Webhooks, nil
+}
+
+// TailnetSettings holds the tailnet-wide settings returned by the settings API
+type TailnetSettings struct {
+	DevicesApprovalOn                      bool   `json:"devicesApprovalOn"`
+	DevicesAutoUpdatesOn                   bool   `json:"devicesAutoUpdatesOn"`
+	DevicesKeyDurationDays                 int    `json:"devicesKeyDurationDays"`
+	UsersApprovalOn                        bool   `json:"usersApprovalOn"`
+	UsersRoleAllowedToJoinExternalTailnets string `json:"usersRoleAllowedToJoinExternalTailnets"` // "none", "admin" or "member"
+	NetworkFlowLoggingOn                   bool   `json:"networkFlowLoggingOn"`
+	RegionalRoutingOn                      bool   `json:"regionalRoutingOn"`
+	PostureIdentityCollectionOn            bool   `json:"postureIdentityCollectionOn"`
+}
+
+// GetSettings fetches the tailnet's settings
+func (c *Client) GetSettings(ctx context.Context) (*TailnetSettings, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	var settings TailnetSettings
+	path := fmt.Sprintf("/api/v2/tailnet/%s/settings", url.PathEscape(c.tailnet))
+	if err := c.getJSON(ctx, path, &settings); err != nil {
+		return nil, classifyError(err, "GetSettings", "tailnet settings")
+	}
+	return &settings, nil
+}
+
+// Log types accepted by GetLogStream
+const (
+	LogTypeConfiguration = "configuration"
+	LogTypeNetwork       = "network"
+)
+
+// LogStream is a log streaming destination as returned by the logging API
+type LogStream struct {
+	LogType             string `json:"logType"`
+	DestinationType     string `json:"destinationType"` // "splunk", "elastic", "panther", "cribl", "datadog", "axiom", "s3" or "gcs"
+	URL                 string `json:"url"`
+	User                string `json:"user"`
+	UploadPeriodMinutes int    `json:"uploadPeriodMinutes"`
+	S3Bucket            string `json:"s3Bucket"`
+	S3Region            string `json:"s3Region"`
+	GCSBucket           string `json:"gcsBucket"`
+}
+
+// Destination describes where the stream sends logs
+func (s *LogStream) Destination() string {
+	switch {
+	case s.S3Bucket != "":
+		return fmt.Sprintf("%s s3://%s", s.DestinationType, s.S3Bucket)
+	case s.GCSBucket != "":
+		return fmt.Sprintf("%s gs://%s", s.DestinationType, s.GCSBucket)
+	case s.URL != "":
+		return fmt.Sprintf("%s %s", s.DestinationType, s.URL)
+	}
+	return s.DestinationType
+}
+
+// GetLogStream fetches the streaming destination for a log type, either
+// LogTypeConfiguration or LogTypeNetwork. Returns nil if streaming isn't
+// configured for that type.
+func (c *Client) GetLogStream(ctx context.Context, logType string) (*LogStream, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	var stream LogStream
+	path := fmt.Sprintf("/api/v2/tailnet/%s/logging/%s/stream", url.PathEscape(c.tailnet), url.PathEscape(logType))
+	if err := c.getJSON(ctx, path, &stream); err != nil {
+		apiErr := classifyError(err, "GetLogStream", logType+" log streaming")
+		if errors.Is(apiErr, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, apiErr
+	}
+	if stream.DestinationType == "" {
+		return nil, nil
+	}
+	return &stream, nil
+}
+
+// ConfigLogActor is who made a configuration change
+type ConfigLogActor struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"` // "USER", "SCIM" or similar
+	LoginName   string `json:"loginName"`
+	DisplayName string `json:"displayName"`
+}
+
+// ConfigLogTarget is what a configuration change applied to
+type ConfigLogTarget struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`     // "TAILNET", "NODE", "USER", "API_KEY" or similar
+	Property string `json:"property"` // The setting changed, if any
+}
+
+// ConfigLogEvent is one entry in the configuration audit log. Field names
+// follow the log format at https://tailscale.com/kb/1203/audit-logging.
+type ConfigLogEvent struct {
+	EventGroupID  string          `json:"eventGroupID"`
+	Origin        string          `json:"origin"`
+	Actor         ConfigLogActor  `json:"actor"`
+	Target        ConfigLogTarget `json:"target"`
+	Type          string          `json:"type"`   // "CREATE", "UPDATE", "DELETE" or similar
+	Action        string          `json:"action"` // Newer log versions; takes precedence over Type
+	ActionDetails string          `json:"actionDetails"`
+	EventTime     time.Time       `json:"eventTime"`
+	Old           json.RawMessage `json:"old,omitempty"`
+	New           json.RawMessage `json:"new,omitempty"`
+}
+
+// Verb returns the event's action
+func (e ConfigLogEvent) Verb() string {
+	if e.Action != "" {
+		return e.Action
+	}
+	return e.Type
+}
+
+// GetConfigurationLog fetches configuration audit log events between start and end
+func (c *Client) GetConfigurationLog(ctx context.Context, start, end time.Time) ([]ConfigLogEvent, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Logs []ConfigLogEvent `json:"logs"`
+	}
+	query := url.Values{}
+	query.Set("start", start.UTC().Format(time.RFC3339))
+	query.Set("end", end.UTC().Format(time.RFC3339))
+	path := fmt.Sprintf("/api/v2/tailnet/%s/logging/configuration?%s", url.PathEscape(c.tailnet), query.Encode())
+	if err := c.getJSON(ctx, path, &resp); err != nil {
+		return nil, classifyError(err, "GetConfigurationLog", "configuration audit logs")
+	}
+	return resp.Logs, nil
+}
+
+// FlowConn is traffic for one connection in a network flow log. Each node
+// logs connections with its own address as Src, so a connection between
+// two nodes appears once from each end.
+type FlowConn struct {
+	Proto   int    `json:"proto"` // IANA protocol number
+	Src     string `json:"src"`   // Address and port
+	Dst     string `json:"dst"`   // Address and port
+	TxPkts  uint64 `json:"txPkts"`
+	TxBytes uint64 `json:"txBytes"`
+	RxPkts  uint64 `json:"rxPkts"`
+	RxBytes uint64 `json:"rxBytes"`
+}
+
+// NetworkFlowLog is one node's traffic over a logging interval
+type NetworkFlowLog struct {
+	Logged         time.Time  `json:"logged"`
+	NodeID         string     `json:"nodeId"`
+	Start          time.Time  `json:"start"`
+	End            time.Time  `json:"end"`
+	VirtualTraffic []FlowConn `json:"virtualTraffic"` // Between tailnet nodes
+	SubnetTraffic  []FlowConn `json:"subnetTraffic"`  // Through a subnet router
+	ExitTraffic    []FlowConn `json:"exitTraffic"`    // Through an exit node
+}
+
+// GetNetworkFlowLogs fetches network flow logs between start and end,
+// following the response cursor until every page is read
+func (c *Client) GetNetworkFlowLogs(ctx context.Context, start, end time.Time) ([]NetworkFlowLog, error) {
+	var logs []NetworkFlowLog
+	seen := make(map[string]bool)
+	cursor := ""
+	for {
+		if err := c.wait(ctx); err != nil {
+			return nil, err
+		}
+		var resp struct {
+			Logs       []NetworkFlowLog `json:"logs"`
+			NextCursor string           `json:"nextCursor"`
+		}
+		query := url.Values{}
+		query.Set("start", start.UTC().Format(time.RFC3339))
+		query.Set("end", end.UTC().Format(time.RFC3339))
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		path := fmt.Sprintf("/api/v2/tailnet/%s/logging/network?%s", url.PathEscape(c.tailnet), query.Encode())
+		if err := c.getJSON(ctx, path, &resp); err != nil {
+			return nil, classifyError(err, "GetNetworkFlowLogs", "network flow logs")
+		}
+		logs = append(logs, resp.Logs...)
+
+		// A repeated cursor would never end, so treat it as the last page
+		if resp.NextCursor == "" || seen[resp.NextCursor] {
+			return logs, nil
+		}
+		seen[resp.NextCursor] = true
+		cursor = resp.NextCursor
+	}
+}
+
+// DecodeNetworkFlowLogs reads exported network flow logs: an API response
+// ({"logs": [...]}), a JSON array of logs, or one log per line as written
+// by log streaming
+func DecodeNetworkFlowLogs(r io.Reader) ([]NetworkFlowLog, error) {
+	var logs []NetworkFlowLog
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return logs, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		trimmed := strings.TrimSpace(string(raw))
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			var batch []NetworkFlowLog
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, err
+			}
+			logs = append(logs, batch...)
+		case strings.HasPrefix(trimmed, "{"):
+			var wrapper struct {
+				Logs []NetworkFlowLog `json:"logs"`
+			}
+			if err := json.Unmarshal(raw, &wrapper); err != nil {
+				return nil, err
+			}
+			if wrapper.Logs != nil {
+				logs = append(logs, wrapper.Logs...)
+				continue
+			}
+			var log NetworkFlowLog
+			if err := json.Unmarshal(raw, &log); err != nil {
+				return nil, err
+			}
+			logs = append(logs, log)
+		default:
+			return nil, fmt.Errorf("unexpected JSON value %.20q", trimmed)
+		}
+	}
+}
+
 // KeyCapabilities is an alias for tailscale.KeyCapabilities
 type KeyCapabilities = tailscale.KeyCapabilities
 
@@ -4,7 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"tailscale.com/client/tailscale"
 )
 
 func TestClassifyError(t *testing.T) {
@@ -201,3 +207,272 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestGetDevicePosture(t *testing.T) {
+	tailscale.I_Acknowledge_This_API_Is_Unstable = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/device/n123/attributes" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"attributes":{"node:os":"macos","custom:mdmEnrolled":true},"expiries":{"custom:mdmEnrolled":"2026-11-01T00:00:00Z"}}`))
+	}))
+	defer server.Close()
+
+	ts := tailscale.NewClient("-", tailscale.APIKey("test"))
+	ts.BaseURL = server.URL
+	c := &Client{ts: ts, tailnet: "-"}
+
+	posture, err := c.GetDevicePosture(context.Background(), "n123")
+	if err != nil {
+		t.Fatalf("GetDevicePosture() error: %v", err)
+	}
+	if posture.Attributes["custom:mdmEnrolled"] != true {
+		t.Errorf("custom:mdmEnrolled = %v, want true", posture.Attributes["custom:mdmEnrolled"])
+	}
+	want := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	if got := posture.Expiries["custom:mdmEnrolled"]; !got.Equal(want) {
+		t.Errorf("expiry = %v, want %v", got, want)
+	}
+
+	_, err = c.GetDevicePosture(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown device, got %v", err)
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	tailscale.I_Acknowledge_This_API_Is_Unstable = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/tailnet/example.com/users" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"users":[{"id":"u1","loginName":"alice@example.com","role":"owner","status":"active","deviceCount":2,"lastSeen":"2026-10-01T12:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	ts := tailscale.NewClient("example.com", tailscale.APIKey("test"))
+	ts.BaseURL = server.URL
+	c := &Client{ts: ts, tailnet: "example.com"}
+
+	users, err := c.GetUsers(context.Background())
+	if err != nil {
+		t.Fatalf("GetUsers() error: %v", err)
+	}
+	if len(users) != 1 || users[0].LoginName != "alice@example.com" || users[0].Role != "owner" || users[0].DeviceCount != 2 {
+		t.Errorf("GetUsers() = %+v", users)
+	}
+	if users[0].LastSeen.IsZero() {
+		t.Error("LastSeen should be parsed")
+	}
+}
+
+func TestGetWebhooks(t *testing.T) {
+	tailscale.I_Acknowledge_This_API_Is_Unstable = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/tailnet/-/webhooks" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"webhooks":[{"endpointId":"w1","endpointUrl":"https://siem.example.com/hook","creatorLoginName":"alice@example.com","created":"2026-01-02T00:00:00Z","subscriptions":["policyUpdate","nodeApproved"]}]}`))
+	}))
+	defer server.Close()
+
+	ts := tailscale.NewClient("-", tailscale.APIKey("test"))
+	ts.BaseURL = server.URL
+	c := &Client{ts: ts, tailnet: "-"}
+
+	webhooks, err := c.GetWebhooks(context.Background())
+	if err != nil {
+		t.Fatalf("GetWebhooks() error: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].EndpointURL != "https://siem.example.com/hook" || len(webhooks[0].Subscriptions) != 2 {
+		t.Errorf("GetWebhooks() = %+v", webhooks)
+	}
+}
+
+func TestGetSettingsAndLogStreams(t *testing.T) {
+	tailscale.I_Acknowledge_This_API_Is_Unstable = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/tailnet/-/settings":
+			w.Write([]byte(`{"devicesApprovalOn":true,"usersApprovalOn":false,"devicesKeyDurationDays":90,"networkFlowLoggingOn":true}`))
+		case "/api/v2/tailnet/-/logging/configuration/stream":
+			w.Write([]byte(`{"logType":"configuration","destinationType":"s3","s3Bucket":"audit-logs","s3Region":"us-east-1"}`))
+		default:
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ts := tailscale.NewClient("-", tailscale.APIKey("test"))
+	ts.BaseURL = server.URL
+	c := &Client{ts: ts, tailnet: "-"}
+	ctx := context.Background()
+
+	settings, err := c.GetSettings(ctx)
+	if err != nil {
+		t.Fatalf("GetSettings() error: %v", err)
+	}
+	if !settings.DevicesApprovalOn || settings.UsersApprovalOn || settings.DevicesKeyDurationDays != 90 || !settings.NetworkFlowLoggingOn {
+		t.Errorf("GetSettings() = %+v", settings)
+	}
+
+	stream, err := c.GetLogStream(ctx, LogTypeConfiguration)
+	if err != nil {
+		t.Fatalf("GetLogStream(configuration) error: %v", err)
+	}
+	if stream == nil || stream.Destination() != "s3 s3://audit-logs" {
+		t.Errorf("GetLogStream(configuration) = %+v", stream)
+	}
+
+	// A 404 means streaming isn't configured for that log type
+	stream, err = c.GetLogStream(ctx, LogTypeNetwork)
+	if err != nil || stream != nil {
+		t.Errorf("GetLogStream(network) = %+v, %v; want nil, nil", stream, err)
+	}
+}
+
+func TestGetAllKeys(t *testing.T) {
+	tailscale.I_Acknowledge_This_API_Is_Unstable = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/tailnet/-/keys":
+			if r.URL.Query().Get("all") != "true" {
+				t.Errorf("GetAllKeys() query = %q, want all=true", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"keys":[
+				{"id":"kAPI","keyType":"api","created":"2026-01-01T00:00:00Z","expires":"2026-04-01T00:00:00Z","userId":"u1"},
+				{"id":"kCLIENT"},
+				{"id":"kGONE"}
+			]}`))
+		case "/api/v2/tailnet/-/keys/kCLIENT":
+			w.Write([]byte(`{"id":"kCLIENT","keyType":"client","created":"2025-06-01T00:00:00Z","userId":"u2","scopes":["all"]}`))
+		default:
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ts := tailscale.NewClient("-", tailscale.APIKey("test"))
+	ts.BaseURL = server.URL
+	c := &Client{ts: ts, tailnet: "-"}
+
+	keys, skipped, err := c.GetAllKeys(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllKeys() error: %v", err)
+	}
+	// A key whose details can't be fetched is skipped, not fatal
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "kGONE: ") {
+		t.Errorf("GetAllKeys() skipped = %v, want kGONE", skipped)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("GetAllKeys() returned %d keys, want 2", len(keys))
+	}
+	if keys[0].Type() != KeyTypeAPI || keys[0].UserID != "u1" {
+		t.Errorf("keys[0] = %+v", keys[0])
+	}
+	// Entries listed without details are fetched individually
+	if keys[1].Type() != KeyTypeOAuthClient || len(keys[1].Scopes) != 1 || keys[1].Created.IsZero() {
+		t.Errorf("keys[1] = %+v", keys[1])
+	}
+}
+
+func TestGetConfigurationLog(t *testing.T) {
+	tailscale.I_Acknowledge_This_API_Is_Unstable = true
+
+	start := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/tailnet/-/logging/configuration" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("start") != "2026-10-11T00:00:00Z" || r.URL.Query().Get("end") != "2026-10-18T00:00:00Z" {
+			t.Errorf("GetConfigurationLog() query = %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"version":"1.1","logs":[{"actor":{"loginName":"alice@example.com","type":"USER"},"target":{"type":"TAILNET","property":"ACL"},"type":"UPDATE","eventTime":"2026-10-12T09:00:00Z","new":"{}"}]}`))
+	}))
+	defer server.Close()
+
+	ts := tailscale.NewClient("-", tailscale.APIKey("test"))
+	ts.BaseURL = server.URL
+	c := &Client{ts: ts, tailnet: "-"}
+
+	events, err := c.GetConfigurationLog(context.Background(), start, end)
+	if err != nil {
+		t.Fatalf("GetConfigurationLog() error: %v", err)
+	}
+	if len(events) != 1 || events[0].Verb() != "UPDATE" || events[0].Target.Property != "ACL" || events[0].Actor.LoginName != "alice@example.com" {
+		t.Errorf("GetConfigurationLog() = %+v", events)
+	}
+}
+
+func TestGetNetworkFlowLogs(t *testing.T) {
+	tailscale.I_Acknowledge_This_API_Is_Unstable = true
+
+	start := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/tailnet/-/logging/network" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("start") != "2026-10-11T00:00:00Z" || r.URL.Query().Get("end") != "2026-10-18T00:00:00Z" {
+			t.Errorf("GetNetworkFlowLogs() query = %q", r.URL.RawQuery)
+		}
+		// The second page is requested with the first page's cursor
+		if r.URL.Query().Get("cursor") == "page2" {
+			w.Write([]byte(`{"logs":[{"nodeId":"n2"}]}`))
+			return
+		}
+		w.Write([]byte(`{"logs":[{"nodeId":"n1","start":"2026-10-12T09:00:00Z","end":"2026-10-12T09:00:05Z","virtualTraffic":[{"proto":6,"src":"100.64.0.1:51000","dst":"100.64.0.2:22","txPkts":10,"txBytes":1500}]}],"nextCursor":"page2"}`))
+	}))
+	defer server.Close()
+
+	ts := tailscale.NewClient("-", tailscale.APIKey("test"))
+	ts.BaseURL = server.URL
+	c := &Client{ts: ts, tailnet: "-"}
+
+	logs, err := c.GetNetworkFlowLogs(context.Background(), start, end)
+	if err != nil {
+		t.Fatalf("GetNetworkFlowLogs() error: %v", err)
+	}
+	if len(logs) != 2 || logs[0].NodeID != "n1" || len(logs[0].VirtualTraffic) != 1 || logs[0].VirtualTraffic[0].Dst != "100.64.0.2:22" || logs[1].NodeID != "n2" {
+		t.Errorf("GetNetworkFlowLogs() = %+v", logs)
+	}
+}
+
+func TestDecodeNetworkFlowLogs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"api response", `{"logs":[{"nodeId":"n1"},{"nodeId":"n2"}]}`, 2},
+		{"array", `[{"nodeId":"n1"},{"nodeId":"n2"},{"nodeId":"n3"}]`, 3},
+		{"one per line", "{\"nodeId\":\"n1\"}\n{\"nodeId\":\"n2\"}\n", 2},
+		{"empty", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := DecodeNetworkFlowLogs(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("DecodeNetworkFlowLogs() error: %v", err)
+			}
+			if len(logs) != tt.want {
+				t.Errorf("DecodeNetworkFlowLogs() = %d logs, want %d", len(logs), tt.want)
+			}
+		})
+	}
+
+	if _, err := DecodeNetworkFlowLogs(strings.NewReader(`"text"`)); err == nil {
+		t.Error("DecodeNetworkFlowLogs() accepted a JSON string")
+	}
+}
@@ -0,0 +1,128 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// Documentation linked from each expiry event
+const (
+	nodeKeyExpiryDocURL = "https://tailscale.com/kb/1028/key-expiry"
+	authKeyDocURL       = "https://tailscale.com/kb/1085/auth-keys"
+)
+
+// icsTimeFormat is the iCalendar UTC date-time format (RFC 5545 3.3.5)
+const icsTimeFormat = "20060102T150405Z"
+
+// ExpiryCalendarICS writes one iCalendar event per node key or auth key
+// expiration. Each event carries a one-day reminder. Event UIDs are stable
+// across exports, so subscribed calendars update events in place.
+func ExpiryCalendarICS(w io.Writer, tailnet string, forecast *types.ExpiryForecast, generatedAt time.Time) error {
+	var b strings.Builder
+	line := func(name, value string) {
+		writeICSLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Adversis//Tailsnitch//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", icsEscape("Tailscale expirations: "+tailnet))
+
+	stamp := generatedAt.UTC().Format(icsTimeFormat)
+	for _, e := range forecast.Events {
+		var summary, docURL string
+		switch e.Kind {
+		case types.ForecastNodeKeyExpiry:
+			summary = fmt.Sprintf("Node key expires: %s", e.Name)
+			docURL = nodeKeyExpiryDocURL
+		case types.ForecastAuthKeyExpiry:
+			summary = fmt.Sprintf("Auth key expires: %s", e.Name)
+			docURL = authKeyDocURL
+		default:
+			continue
+		}
+
+		var desc []string
+		if e.Owner != "" {
+			desc = append(desc, "Owner: "+e.Owner)
+		}
+		if len(e.Tags) > 0 {
+			desc = append(desc, "Tags: "+strings.Join(e.Tags, ", "))
+		}
+		if e.Detail != "" {
+			desc = append(desc, "Details: "+e.Detail)
+		}
+		if e.AdminURL != "" {
+			desc = append(desc, "Renew: "+e.AdminURL)
+		}
+		desc = append(desc, "Docs: "+docURL)
+
+		start := e.Date.UTC()
+		line("BEGIN", "VEVENT")
+		line("UID", fmt.Sprintf("%s-%s-%s@tailsnitch", e.Kind, e.ID, start.Format("20060102")))
+		line("DTSTAMP", stamp)
+		line("DTSTART", start.Format(icsTimeFormat))
+		line("DTEND", start.Add(30*time.Minute).Format(icsTimeFormat))
+		line("SUMMARY", icsEscape(summary))
+		line("DESCRIPTION", icsEscape(strings.Join(desc, "\n")))
+		if e.AdminURL != "" {
+			line("URL", e.AdminURL)
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("BEGIN", "VALARM")
+		line("ACTION", "DISPLAY")
+		line("DESCRIPTION", icsEscape(summary))
+		line("TRIGGER", "-P1D")
+		line("END", "VALARM")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// icsEscape escapes a TEXT value (RFC 5545 3.3.11)
+func icsEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeICSLine writes a content line, folding it at 75 octets without
+// splitting a UTF-8 sequence (RFC 5545 3.1)
+func writeICSLine(b *strings.Builder, s string) {
+	const limit = 75
+	first := true
+	for len(s) > 0 {
+		n := limit
+		if !first {
+			n-- // Continuation lines start with a space
+		}
+		if len(s) <= n {
+			n = len(s)
+		} else {
+			for n > 0 && s[n]&0xC0 == 0x80 {
+				n--
+			}
+		}
+		if !first {
+			b.WriteString(" ")
+		}
+		b.WriteString(s[:n])
+		b.WriteString("\r\n")
+		s = s[n:]
+		first = false
+	}
+}
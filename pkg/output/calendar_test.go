@@ -0,0 +1,103 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestExpiryCalendarICS(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	forecast := &types.ExpiryForecast{
+		From: now,
+		To:   now.AddDate(0, 0, 90),
+		Events: []types.ForecastEvent{
+			{
+				Date:     time.Date(2026, 11, 2, 14, 30, 0, 0, time.UTC),
+				Kind:     types.ForecastNodeKeyExpiry,
+				ID:       "12345",
+				Name:     "subnet-router",
+				Owner:    "alice@example.com",
+				Tags:     []string{"tag:router", "tag:prod"},
+				Detail:   "router-1, tag:router,tag:prod",
+				AdminURL: "https://login.tailscale.com/admin/machines/100.64.0.1",
+			},
+			{
+				Date:     time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+				Kind:     types.ForecastAuthKeyExpiry,
+				ID:       "kAbC123",
+				Name:     "kAbC123",
+				Tags:     []string{"tag:ci"},
+				AdminURL: "https://login.tailscale.com/admin/settings/keys",
+			},
+			{
+				Date: time.Date(2026, 12, 5, 0, 0, 0, 0, time.UTC),
+				Kind: types.ForecastDeviceStale,
+				ID:   "999",
+				Name: "old-laptop",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := ExpiryCalendarICS(&buf, "example.com", forecast, now); err != nil {
+		t.Fatalf("ExpiryCalendarICS() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line exceeds 75 octets: %q", line)
+		}
+		if strings.Contains(line, "\n") {
+			t.Errorf("line contains a bare newline: %q", line)
+		}
+	}
+
+	// Unfold continuation lines before checking content
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+
+	wantContains := []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Tailscale expirations: example.com\r\n",
+		"UID:node_key_expiry-12345-20261102@tailsnitch\r\n",
+		"DTSTART:20261102T143000Z\r\n",
+		"DTEND:20261102T150000Z\r\n",
+		"SUMMARY:Node key expires: subnet-router\r\n",
+		`Owner: alice@example.com\nTags: tag:router\, tag:prod`,
+		"URL:https://login.tailscale.com/admin/machines/100.64.0.1\r\n",
+		"SUMMARY:Auth key expires: kAbC123\r\n",
+		"TRIGGER:-P1D\r\n",
+		"END:VCALENDAR\r\n",
+	}
+	for _, want := range wantContains {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("calendar missing %q", want)
+		}
+	}
+
+	if strings.Contains(unfolded, "old-laptop") {
+		t.Error("stale device events should not be exported")
+	}
+	if n := strings.Count(unfolded, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("got %d events, want 2", n)
+	}
+}
+
+func TestWriteICSLineFolding(t *testing.T) {
+	var b strings.Builder
+	long := "DESCRIPTION:" + strings.Repeat("é", 60)
+	writeICSLine(&b, long)
+
+	if got := strings.ReplaceAll(b.String(), "\r\n ", ""); got != long+"\r\n" {
+		t.Errorf("unfolded line = %q, want %q", got, long+"\r\n")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line exceeds 75 octets: %d", len(line))
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// ConfigChangesJSON outputs the configuration change report as JSON
+func ConfigChangesJSON(w io.Writer, report *types.ConfigChangeReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// ConfigChangesCSV outputs the configuration change report as CSV, one row per risky change
+func ConfigChangesCSV(w io.Writer, report *types.ConfigChangeReport) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	// Write header
+	header := []string{
+		"time",
+		"severity",
+		"check_id",
+		"check_title",
+		"reason",
+		"actor",
+		"actor_type",
+		"origin",
+		"action",
+		"target_type",
+		"target",
+		"property",
+		"old",
+		"new",
+		"cc_codes",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// Write each change as a row
+	for _, c := range report.Changes {
+		row := []string{
+			c.Time.Format(time.RFC3339),
+			string(c.Severity),
+			c.CheckID,
+			c.CheckTitle,
+			c.Reason,
+			c.Actor,
+			c.ActorType,
+			c.Origin,
+			c.Action,
+			c.TargetType,
+			c.Target,
+			c.Property,
+			c.Old,
+			c.New,
+			strings.Join(c.CCCodes, ";"),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// FlowReportJSON outputs the flow log rule usage report as JSON
+func FlowReportJSON(w io.Writer, report *types.FlowReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// FlowReportCSV outputs the flow log rule usage report as CSV, one row per rule
+func FlowReportCSV(w io.Writer, report *types.FlowReport) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	// Write header
+	header := []string{
+		"kind",
+		"number",
+		"unused",
+		"flows",
+		"bytes",
+		"rule",
+		"proposed",
+		"note",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// Write each rule as a row
+	for _, r := range report.Rules {
+		row := []string{
+			r.Kind,
+			strconv.Itoa(r.Number),
+			strconv.FormatBool(r.Unused),
+			strconv.Itoa(len(r.Flows)),
+			strconv.FormatUint(r.Bytes(), 10),
+			r.Rule,
+			r.Proposed,
+			r.Note,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FlowPolicyDiff outputs the proposed policy changes as a unified-style diff:
+// rules with no traffic are removed and broad rules are replaced by their
+// narrowed form. Rules are shown on one line, so comments and formatting in
+// the policy file are not carried over.
+func FlowPolicyDiff(w io.Writer, report *types.FlowReport) error {
+	window := fmt.Sprintf("%s to %s", report.Since.Format("2006-01-02 15:04"), report.Until.Format("2006-01-02 15:04"))
+	if _, err := fmt.Fprintf(w, "--- policy (current)\n+++ policy (proposed from flows %s)\n", window); err != nil {
+		return err
+	}
+
+	for _, r := range report.Rules {
+		if !r.Unused && r.Proposed == "" {
+			continue
+		}
+
+		label := "ACL rule"
+		if r.Kind == types.FlowRuleGrant {
+			label = "Grant"
+		}
+		if r.Unused {
+			fmt.Fprintf(w, "@@ %s %d: no traffic @@\n-%s\n", label, r.Number, r.Rule)
+			continue
+		}
+		fmt.Fprintf(w, "@@ %s %d: %s @@\n-%s\n+%s\n", label, r.Number, r.Note, r.Rule, r.Proposed)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+func TestFlowPolicyDiff(t *testing.T) {
+	report := &types.FlowReport{
+		Since: time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Rules: []types.FlowRuleUsage{
+			{
+				Kind:     types.FlowRuleACL,
+				Number:   1,
+				Rule:     `{"action": "accept", "src": ["group:eng"], "dst": ["*:*"]}`,
+				Proposed: `{"action": "accept", "src": ["group:eng"], "dst": ["tag:web:443"]}`,
+				Note:     "Narrowed to 2 flow(s) to 1 destination(s)",
+				Flows:    []types.ObservedFlow{{Src: "alice", Dst: "web", Port: 443, Proto: "tcp"}},
+			},
+			{
+				Kind:   types.FlowRuleACL,
+				Number: 2,
+				Rule:   `{"action": "accept", "src": ["tag:ci"], "dst": ["tag:web:22"]}`,
+				Flows:  []types.ObservedFlow{{Src: "ci", Dst: "web", Port: 22, Proto: "tcp"}},
+			},
+			{
+				Kind:   types.FlowRuleGrant,
+				Number: 1,
+				Rule:   `{"src": ["bob@example.com"], "dst": ["tag:db"], "ip": ["*"]}`,
+				Unused: true,
+				Flows:  []types.ObservedFlow{},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FlowPolicyDiff(&buf, report); err != nil {
+		t.Fatalf("FlowPolicyDiff() error: %v", err)
+	}
+
+	want := `--- policy (current)
++++ policy (proposed from flows 2026-10-11 00:00 to 2026-10-18 00:00)
+@@ ACL rule 1: Narrowed to 2 flow(s) to 1 destination(s) @@
+-{"action": "accept", "src": ["group:eng"], "dst": ["*:*"]}
++{"action": "accept", "src": ["group:eng"], "dst": ["tag:web:443"]}
+@@ Grant 1: no traffic @@
+-{"src": ["bob@example.com"], "dst": ["tag:db"], "ip": ["*"]}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("FlowPolicyDiff() =\n%s\nwant:\n%s", got, want)
+	}
+	if strings.Contains(buf.String(), "ACL rule 2") {
+		t.Error("rules already as narrow as their traffic should not appear in the diff")
+	}
+}
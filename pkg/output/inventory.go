@@ -0,0 +1,84 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// DeviceInventoryJSON outputs the device inventory as JSON
+func DeviceInventoryJSON(w io.Writer, report *types.DeviceInventoryReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// DeviceInventoryCSV outputs the device inventory as CSV, one row per device
+func DeviceInventoryCSV(w io.Writer, report *types.DeviceInventoryReport) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	// Write header
+	header := []string{
+		"device_id",
+		"name",
+		"hostname",
+		"owner",
+		"tags",
+		"os",
+		"os_version",
+		"client_version",
+		"update_available",
+		"last_seen",
+		"key_expiry",
+		"key_expiry_disabled",
+		"advertised_routes",
+		"enabled_routes",
+		"exit_node",
+		"external",
+		"authorized",
+		"failed_checks",
+		"risk_score",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// Write each device as a row
+	for _, dev := range report.Devices {
+		var failed []string
+		for _, f := range dev.FailedChecks {
+			failed = append(failed, f.CheckID)
+		}
+		row := []string{
+			dev.DeviceID,
+			dev.Name,
+			dev.Hostname,
+			dev.Owner,
+			strings.Join(dev.Tags, ";"),
+			dev.OS,
+			dev.OSVersion,
+			dev.ClientVersion,
+			strconv.FormatBool(dev.UpdateAvailable),
+			dev.LastSeen,
+			dev.KeyExpiry,
+			strconv.FormatBool(dev.KeyExpiryDisabled),
+			strings.Join(dev.AdvertisedRoutes, ";"),
+			strings.Join(dev.EnabledRoutes, ";"),
+			strconv.FormatBool(dev.ExitNode),
+			strconv.FormatBool(dev.External),
+			strconv.FormatBool(dev.Authorized),
+			strings.Join(failed, ";"),
+			strconv.Itoa(dev.RiskScore),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// KeyInventoryJSON outputs the key inventory as JSON
+func KeyInventoryJSON(w io.Writer, report *types.KeyInventoryReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// KeyInventoryCSV outputs the key inventory as CSV, one row per key
+func KeyInventoryCSV(w io.Writer, report *types.KeyInventoryReport) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	// Write header
+	header := []string{
+		"id",
+		"type",
+		"description",
+		"creator",
+		"creator_removed",
+		"scopes",
+		"tags",
+		"capabilities",
+		"created",
+		"expires",
+		"last_used",
+		"failed_checks",
+		"risk_score",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// Write each key as a row
+	for _, key := range report.Keys {
+		var failed []string
+		for _, f := range key.FailedChecks {
+			failed = append(failed, f.CheckID)
+		}
+		row := []string{
+			key.ID,
+			key.Type,
+			key.Description,
+			key.Creator,
+			strconv.FormatBool(key.CreatorRemoved),
+			strings.Join(key.Scopes, ";"),
+			strings.Join(key.Tags, ";"),
+			strings.Join(key.Capabilities, ";"),
+			key.Created,
+			key.Expires,
+			key.LastUsed,
+			strings.Join(failed, ";"),
+			strconv.Itoa(key.RiskScore),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// ServeInventoryJSON outputs the Serve inventory as JSON
+func ServeInventoryJSON(w io.Writer, report *types.ServeInventoryReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// ServeSnapshotJSON outputs a single node's Serve snapshot as JSON
+func ServeSnapshotJSON(w io.Writer, snap *types.ServeSnapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snap)
+}
+
+// ServeInventoryCSV outputs the Serve inventory as CSV, one row per endpoint
+func ServeInventoryCSV(w io.Writer, report *types.ServeInventoryReport) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	// Write header
+	header := []string{
+		"node",
+		"url",
+		"protocol",
+		"port",
+		"path",
+		"handler",
+		"backend",
+		"terminate_tls",
+		"funnel",
+		"plaintext_backend",
+		"admin_service",
+		"foreground",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// Write each endpoint as a row
+	for _, e := range report.Endpoints() {
+		row := []string{
+			e.Node,
+			e.URL(),
+			e.Protocol,
+			strconv.Itoa(int(e.Port)),
+			e.Path,
+			e.Handler,
+			e.Backend,
+			e.TerminateTLS,
+			strconv.FormatBool(e.Funnel),
+			strconv.FormatBool(e.PlaintextBackend),
+			e.AdminService,
+			strconv.FormatBool(e.Foreground),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
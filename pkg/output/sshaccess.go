@@ -0,0 +1,70 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Adversis/tailsnitch/pkg/types"
+)
+
+// SSHAccessJSON outputs the SSH access matrix as JSON, grouped by user
+func SSHAccessJSON(w io.Writer, report *types.SSHAccessReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// SSHAccessCSV outputs the SSH access matrix as CSV, one row per user/device/local user
+func SSHAccessCSV(w io.Writer, report *types.SSHAccessReport) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	// Write header
+	header := []string{
+		"user",
+		"device",
+		"device_id",
+		"device_tags",
+		"local_user",
+		"root",
+		"requires_check",
+		"check_period",
+		"recorded",
+		"enforce_recorder",
+		"rules",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// Write each grant as a row
+	for _, u := range report.Users {
+		for _, g := range u.Grants {
+			rules := make([]string, len(g.Rules))
+			for i, r := range g.Rules {
+				rules[i] = strconv.Itoa(r)
+			}
+			row := []string{
+				u.User,
+				g.Device,
+				g.DeviceID,
+				strings.Join(g.DeviceTags, ";"),
+				g.LocalUser,
+				strconv.FormatBool(g.Root),
+				strconv.FormatBool(g.RequiresCheck),
+				g.CheckPeriod,
+				strconv.FormatBool(g.Recorded),
+				strconv.FormatBool(g.EnforceRecorder),
+				strings.Join(rules, ";"),
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -60,6 +60,9 @@ func PrintBanner(w io.Writer, tailnetName, version, buildID string) {
 func Text(w io.Writer, report *types.AuditReport, showPassing bool) error {
 	// Print completion message (banner already printed)
 	dimColor.Fprintf(w, "Completed at %s\n", report.Timestamp.Format("2006-01-02 15:04:05"))
+	if report.AsOf != nil {
+		dimColor.Fprintf(w, "Checks evaluated as of %s\n", report.AsOf.Format("2006-01-02"))
+	}
 	fmt.Fprintln(w)
 
 	// Group suggestions by category
@@ -74,6 +77,7 @@ func Text(w io.Writer, report *types.AuditReport, showPassing bool) error {
 		types.SSHSecurity,
 		types.LoggingAdmin,
 		types.DNSConfiguration,
+		types.LocalNode,
 	}
 
 	// Print suggestions by category
@@ -86,12 +90,39 @@ func Text(w io.Writer, report *types.AuditReport, showPassing bool) error {
 		printCategory(w, cat, suggestions, showPassing)
 	}
 
+	// Print forecast of events up to the --as-of date
+	if report.Forecast != nil {
+		printForecast(w, report.Forecast)
+	}
+
 	// Print summary
 	printSummary(w, report)
 
 	return nil
 }
 
+func printForecast(w io.Writer, forecast *types.ExpiryForecast) {
+	title := fmt.Sprintf("FORECAST %s TO %s ", forecast.From.Format("2006-01-02"), forecast.To.Format("2006-01-02"))
+	headerColor.Fprintf(w, "━━━ %s", title)
+	headerColor.Fprintln(w, strings.Repeat("━", 64-len(title)))
+	fmt.Fprintln(w)
+
+	if len(forecast.Events) == 0 {
+		dimColor.Fprintln(w, "  No node keys, auth keys or devices expire or go stale in this window.")
+		fmt.Fprintln(w)
+		return
+	}
+
+	for _, e := range forecast.Events {
+		fmt.Fprintf(w, "  %s  %-20s  %s", e.Date.Format("2006-01-02"), e.Kind.Label(), e.Name)
+		if e.Detail != "" {
+			dimColor.Fprintf(w, " (%s)", e.Detail)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
+
 func printCategory(w io.Writer, cat types.Category, suggestions []types.Suggestion, showPassing bool) {
 	// Sort suggestions by severity
 	sort.Slice(suggestions, func(i, j int) bool {
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChangeCCCode is the Common Criteria control that configuration change
+// review provides evidence for
+const ChangeCCCode = "CC7.2"
+
+// ConfigChange is a risky event from the configuration audit log, with the
+// check whose result it affects
+type ConfigChange struct {
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor"` // Login name, or actor ID
+	ActorType  string    `json:"actor_type,omitempty"`
+	Origin     string    `json:"origin,omitempty"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	Target     string    `json:"target,omitempty"`
+	Property   string    `json:"property,omitempty"`
+	Old        string    `json:"old,omitempty"`
+	New        string    `json:"new,omitempty"`
+	Reason     string    `json:"reason"`
+	Severity   Severity  `json:"severity"`
+	CheckID    string    `json:"check_id"`
+	CheckTitle string    `json:"check_title"`
+	CCCodes    []string  `json:"cc_codes"`
+}
+
+// ConfigChangeReport lists risky configuration changes over a period
+type ConfigChangeReport struct {
+	Tailnet     string         `json:"tailnet"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Since       time.Time      `json:"since"`
+	Until       time.Time      `json:"until"`
+	EventCount  int            `json:"event_count"` // All events in the period, risky or not
+	Changes     []ConfigChange `json:"changes"`
+}
+
+// ParseLookback parses a --since value relative to now: a number of days or
+// weeks such as "7d" or "2w", a Go duration such as "36h", or a date
+// (YYYY-MM-DD)
+func ParseLookback(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty value")
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		if t.After(now) {
+			return time.Time{}, fmt.Errorf("%s is in the future", s)
+		}
+		return t, nil
+	}
+
+	if unit := s[len(s)-1]; unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("invalid lookback %q", s)
+		}
+		if unit == 'w' {
+			n *= 7
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid lookback %q (use 7d, 2w, 36h or YYYY-MM-DD)", s)
+	}
+	return now.Add(-d), nil
+}
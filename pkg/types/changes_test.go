@@ -0,0 +1,37 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLookback(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"7d", time.Date(2026, 10, 11, 12, 0, 0, 0, time.UTC), false},
+		{"2w", time.Date(2026, 10, 4, 12, 0, 0, 0, time.UTC), false},
+		{"36h", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), false},
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2026-11-01", time.Time{}, true},
+		{"0d", time.Time{}, true},
+		{"-3h", time.Time{}, true},
+		{"week", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLookback(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLookback(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("ParseLookback(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
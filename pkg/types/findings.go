@@ -31,6 +31,22 @@ func (s Severity) Order() int {
 	}
 }
 
+// Weight returns the contribution of a failed check at this severity to a risk score
+func (s Severity) Weight() int {
+	switch s {
+	case Critical:
+		return 10
+	case High:
+		return 5
+	case Medium:
+		return 3
+	case Low:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // Category represents the category of a security finding
 type Category string
 
@@ -42,6 +58,7 @@ const (
 	LoggingAdmin     Category = "Logging & Admin"
 	DeviceSecurity   Category = "Device Security"
 	DNSConfiguration Category = "DNS Configuration"
+	LocalNode        Category = "Local Node"
 )
 
 // FixType indicates how a finding can be fixed
@@ -100,10 +117,12 @@ type Summary struct {
 
 // AuditReport represents the complete audit report
 type AuditReport struct {
-	Timestamp   time.Time    `json:"timestamp"`
-	Tailnet     string       `json:"tailnet"`
-	Suggestions []Suggestion `json:"suggestions"`
-	Summary     Summary      `json:"summary"`
+	Timestamp   time.Time       `json:"timestamp"`
+	AsOf        *time.Time      `json:"as_of,omitempty"` // Date checks were evaluated at, if not Timestamp
+	Tailnet     string          `json:"tailnet"`
+	Suggestions []Suggestion    `json:"suggestions"`
+	Summary     Summary         `json:"summary"`
+	Forecast    *ExpiryForecast `json:"forecast,omitempty"`
 }
 
 // CalculateSummary computes the summary from suggestions
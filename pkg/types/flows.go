@@ -0,0 +1,74 @@
+package types
+
+import "time"
+
+// Flow rule kinds
+const (
+	FlowRuleACL   = "acl"
+	FlowRuleGrant = "grant"
+)
+
+// ObservedFlow is traffic from one source to one destination port seen in
+// network flow logs
+type ObservedFlow struct {
+	Src     string `json:"src"` // Device name, or the address when no device has it
+	SrcAddr string `json:"src_addr"`
+	Dst     string `json:"dst"`
+	DstAddr string `json:"dst_addr"`
+	Port    int    `json:"port"`
+	Proto   string `json:"proto"`
+	Packets uint64 `json:"packets"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+// FlowRuleUsage is the traffic one ACL rule or grant permitted, with a
+// narrowed rule covering only that traffic
+type FlowRuleUsage struct {
+	Kind     string         `json:"kind"`               // FlowRuleACL or FlowRuleGrant
+	Number   int            `json:"number"`             // Position in the acls or grants section, from 1
+	Rule     string         `json:"rule"`               // The rule as compact JSON
+	Proposed string         `json:"proposed,omitempty"` // Narrowed rule, when observed traffic needs less than the rule allows
+	Unused   bool           `json:"unused"`
+	Note     string         `json:"note,omitempty"`
+	Flows    []ObservedFlow `json:"flows"`
+}
+
+// Bytes returns the total traffic the rule permitted
+func (r FlowRuleUsage) Bytes() uint64 {
+	var total uint64
+	for _, f := range r.Flows {
+		total += f.Bytes
+	}
+	return total
+}
+
+// FlowReport maps network flow log traffic onto the policy's ACL rules and grants
+type FlowReport struct {
+	Tailnet     string          `json:"tailnet"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Since       time.Time       `json:"since"`
+	Until       time.Time       `json:"until"`
+	Source      string          `json:"source"` // "api" or the files read
+	LogCount    int             `json:"log_count"`
+	FlowCount   int             `json:"flow_count"`
+	Rules       []FlowRuleUsage `json:"rules"`
+	Unmatched   []ObservedFlow  `json:"unmatched"`  // Flows no rule permits, such as traffic allowed before a policy change
+	Unresolved  []string        `json:"unresolved"` // Selectors that can't be matched to addresses
+}
+
+// Counts returns how many rules saw traffic, saw none, and have a narrowed
+// proposal. Rules that couldn't be evaluated count as neither used nor unused.
+func (r *FlowReport) Counts() (used, unused, narrowed int) {
+	for _, rule := range r.Rules {
+		switch {
+		case rule.Unused:
+			unused++
+		case len(rule.Flows) > 0:
+			used++
+			if rule.Proposed != "" {
+				narrowed++
+			}
+		}
+	}
+	return used, unused, narrowed
+}
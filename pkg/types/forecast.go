@@ -0,0 +1,73 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// ForecastEventKind identifies what happens in a forecast event
+type ForecastEventKind string
+
+const (
+	ForecastNodeKeyExpiry ForecastEventKind = "node_key_expiry"
+	ForecastAuthKeyExpiry ForecastEventKind = "auth_key_expiry"
+	ForecastDeviceStale   ForecastEventKind = "device_stale"
+)
+
+// Label returns a human-readable description of the event kind
+func (k ForecastEventKind) Label() string {
+	switch k {
+	case ForecastNodeKeyExpiry:
+		return "Node key expires"
+	case ForecastAuthKeyExpiry:
+		return "Auth key expires"
+	case ForecastDeviceStale:
+		return "Device becomes stale"
+	default:
+		return string(k)
+	}
+}
+
+// ForecastEvent is a lifecycle event that happens on a known date
+type ForecastEvent struct {
+	Date     time.Time         `json:"date"`
+	Kind     ForecastEventKind `json:"kind"`
+	ID       string            `json:"id"`   // Device or auth key ID
+	Name     string            `json:"name"` // Device name or auth key ID
+	Owner    string            `json:"owner,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Detail   string            `json:"detail,omitempty"`
+	AdminURL string            `json:"admin_url,omitempty"` // Where to renew or review
+}
+
+// ExpiryForecast lists lifecycle events between From and To, in date order
+type ExpiryForecast struct {
+	From   time.Time       `json:"from"`
+	To     time.Time       `json:"to"`
+	Events []ForecastEvent `json:"events"`
+}
+
+// Expirations returns a forecast with only node key and auth key expiry events
+func (f *ExpiryForecast) Expirations() *ExpiryForecast {
+	out := &ExpiryForecast{From: f.From, To: f.To, Events: []ForecastEvent{}}
+	for _, e := range f.Events {
+		if e.Kind == ForecastNodeKeyExpiry || e.Kind == ForecastAuthKeyExpiry {
+			out.Events = append(out.Events, e)
+		}
+	}
+	return out
+}
+
+// Sort orders events by date, then kind, then name
+func (f *ExpiryForecast) Sort() {
+	sort.Slice(f.Events, func(i, j int) bool {
+		a, b := f.Events[i], f.Events[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// DeviceCheckFailure records a check that failed for a single device or key
+type DeviceCheckFailure struct {
+	CheckID  string   `json:"check_id"`
+	Title    string   `json:"title"`
+	Severity Severity `json:"severity"`
+}
+
+// DeviceRecord is one row of the device inventory
+type DeviceRecord struct {
+	DeviceID          string               `json:"device_id"`
+	Name              string               `json:"name"`
+	Hostname          string               `json:"hostname"`
+	Owner             string               `json:"owner"`
+	Tags              []string             `json:"tags,omitempty"`
+	OS                string               `json:"os"`
+	OSVersion         string               `json:"os_version,omitempty"`
+	ClientVersion     string               `json:"client_version"`
+	UpdateAvailable   bool                 `json:"update_available"`
+	LastSeen          string               `json:"last_seen,omitempty"`
+	KeyExpiry         string               `json:"key_expiry,omitempty"` // RFC3339 timestamp
+	KeyExpiryDisabled bool                 `json:"key_expiry_disabled"`
+	AdvertisedRoutes  []string             `json:"advertised_routes,omitempty"`
+	EnabledRoutes     []string             `json:"enabled_routes,omitempty"`
+	ExitNode          bool                 `json:"exit_node"` // Advertises 0.0.0.0/0 or ::/0
+	External          bool                 `json:"external"`
+	Authorized        bool                 `json:"authorized"`
+	FailedChecks      []DeviceCheckFailure `json:"failed_checks"`
+	RiskScore         int                  `json:"risk_score"`
+}
+
+// DeviceInventoryReport lists every device with its failed checks and risk score
+type DeviceInventoryReport struct {
+	Tailnet     string         `json:"tailnet"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	AsOf        *time.Time     `json:"as_of,omitempty"` // Date checks were evaluated at, if not GeneratedAt
+	Devices     []DeviceRecord `json:"devices"`
+
+	PostureUnavailable []string `json:"posture_unavailable,omitempty"` // Devices DEV-014 to DEV-017 couldn't check
+}
+
+// CalculateRisk computes each device's risk score from its failed checks and
+// sorts devices from highest to lowest risk
+func (r *DeviceInventoryReport) CalculateRisk() {
+	for i := range r.Devices {
+		score := 0
+		for _, f := range r.Devices[i].FailedChecks {
+			score += f.Severity.Weight()
+		}
+		r.Devices[i].RiskScore = score
+	}
+
+	sort.SliceStable(r.Devices, func(i, j int) bool {
+		if r.Devices[i].RiskScore != r.Devices[j].RiskScore {
+			return r.Devices[i].RiskScore > r.Devices[j].RiskScore
+		}
+		return r.Devices[i].Name < r.Devices[j].Name
+	})
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// KeyRecord is one row of the key inventory: an auth key, API access token,
+// OAuth client or federated identity
+type KeyRecord struct {
+	ID             string               `json:"id"`
+	Type           string               `json:"type"` // "auth", "api", "client" or "federated"
+	Description    string               `json:"description,omitempty"`
+	Creator        string               `json:"creator,omitempty"` // Login name, or user ID if it can't be resolved
+	CreatorRemoved bool                 `json:"creator_removed"`
+	Scopes         []string             `json:"scopes,omitempty"`
+	Tags           []string             `json:"tags,omitempty"`
+	Capabilities   []string             `json:"capabilities,omitempty"` // reusable, ephemeral, preauthorized
+	Created        string               `json:"created,omitempty"`      // RFC3339 timestamp
+	Expires        string               `json:"expires,omitempty"`      // RFC3339 timestamp, empty if it never expires
+	LastUsed       string               `json:"last_used,omitempty"`    // RFC3339 timestamp, empty if not reported
+	FailedChecks   []DeviceCheckFailure `json:"failed_checks"`
+	RiskScore      int                  `json:"risk_score"`
+}
+
+// KeyInventoryReport lists every key with its failed checks and risk score
+type KeyInventoryReport struct {
+	Tailnet     string      `json:"tailnet"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	AsOf        *time.Time  `json:"as_of,omitempty"` // Date checks were evaluated at, if not GeneratedAt
+	UsersError  string      `json:"users_error,omitempty"`
+	SkippedKeys []string    `json:"skipped_keys,omitempty"` // Keys whose details couldn't be fetched
+	Keys        []KeyRecord `json:"keys"`
+}
+
+// CalculateRisk computes each key's risk score from its failed checks and
+// sorts keys from highest to lowest risk, then by type and ID
+func (r *KeyInventoryReport) CalculateRisk() {
+	for i := range r.Keys {
+		score := 0
+		for _, f := range r.Keys[i].FailedChecks {
+			score += f.Severity.Weight()
+		}
+		r.Keys[i].RiskScore = score
+	}
+
+	sort.SliceStable(r.Keys, func(i, j int) bool {
+		if r.Keys[i].RiskScore != r.Keys[j].RiskScore {
+			return r.Keys[i].RiskScore > r.Keys[j].RiskScore
+		}
+		if r.Keys[i].Type != r.Keys[j].Type {
+			return r.Keys[i].Type < r.Keys[j].Type
+		}
+		return r.Keys[i].ID < r.Keys[j].ID
+	})
+}
@@ -0,0 +1,182 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// Default lifecycle thresholds, used when no rule sets a value
+const (
+	DefaultStaleDays         = 60
+	DefaultDevKeyExpiryDays  = 90
+	DefaultKeyExpiryDays     = 180
+	DefaultAuthKeyExpiryDays = 90
+	DefaultLifecycleRuleName = "default"
+	defaultLifecycleFileName = ".tailsnitch-lifecycle"
+)
+
+// LifecycleRule sets thresholds for the devices and auth keys it matches.
+// A rule matches when every criterion it sets matches: the device has any
+// of Tags, runs one of OS, and its owner's email is in one of OwnerDomains.
+// Zero thresholds fall back to the defaults.
+type LifecycleRule struct {
+	Name              string   `json:"name"`
+	Tags              []string `json:"tags,omitempty"`
+	OS                []string `json:"os,omitempty"`
+	OwnerDomains      []string `json:"ownerDomains,omitempty"`
+	StaleDays         int      `json:"staleDays,omitempty"`
+	KeyExpiryDays     int      `json:"keyExpiryDays,omitempty"`
+	AuthKeyExpiryDays int      `json:"authKeyExpiryDays,omitempty"`
+}
+
+// LifecyclePolicy is an ordered list of lifecycle rules; the first match wins
+type LifecyclePolicy struct {
+	Rules []LifecycleRule `json:"rules"`
+}
+
+// DefaultLifecycleFiles returns the paths to check for a lifecycle policy, in order of priority
+func DefaultLifecycleFiles() []string {
+	paths := []string{
+		defaultLifecycleFileName, // Current directory
+	}
+
+	// Also check home directory
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, defaultLifecycleFileName))
+	}
+
+	return paths
+}
+
+// LoadLifecycleFile loads a lifecycle policy from the given path.
+// The file is HuJSON (JSON with comments and trailing commas).
+func LoadLifecycleFile(path string) (*LifecyclePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	standardized, err := hujson.Standardize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy LifecyclePolicy
+	if err := json.Unmarshal(standardized, &policy); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range policy.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if strings.EqualFold(rule.Name, DefaultLifecycleRuleName) {
+			return nil, fmt.Errorf("rule %d: name %q is reserved", i+1, DefaultLifecycleRuleName)
+		}
+		if len(rule.Tags) == 0 && len(rule.OS) == 0 && len(rule.OwnerDomains) == 0 {
+			return nil, fmt.Errorf("rule %q: at least one of tags, os or ownerDomains is required", rule.Name)
+		}
+		if rule.StaleDays < 0 || rule.KeyExpiryDays < 0 || rule.AuthKeyExpiryDays < 0 {
+			return nil, fmt.Errorf("rule %q: thresholds must not be negative", rule.Name)
+		}
+	}
+
+	return &policy, nil
+}
+
+// LoadLifecycleFiles tries to load a lifecycle policy from default locations.
+// Returns nil and an empty path if none exists.
+func LoadLifecycleFiles() (*LifecyclePolicy, string, error) {
+	for _, path := range DefaultLifecycleFiles() {
+		if _, err := os.Stat(path); err == nil {
+			policy, err := LoadLifecycleFile(path)
+			if err != nil {
+				return nil, path, err
+			}
+			return policy, path, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// DeviceRule returns the first rule matching a device, or an empty rule
+// named "default" if none match. A nil policy always returns the default.
+func (p *LifecyclePolicy) DeviceRule(tags []string, os, owner string) LifecycleRule {
+	if p != nil {
+		for _, rule := range p.Rules {
+			if rule.matches(tags, os, owner) {
+				return rule
+			}
+		}
+	}
+	return LifecycleRule{Name: DefaultLifecycleRuleName}
+}
+
+// AuthKeyRule returns the first rule matching an auth key's tags. Rules that
+// match on OS or owner domain can't apply to keys and are skipped.
+func (p *LifecyclePolicy) AuthKeyRule(tags []string) LifecycleRule {
+	if p != nil {
+		for _, rule := range p.Rules {
+			if len(rule.OS) > 0 || len(rule.OwnerDomains) > 0 {
+				continue
+			}
+			if rule.matches(tags, "", "") {
+				return rule
+			}
+		}
+	}
+	return LifecycleRule{Name: DefaultLifecycleRuleName}
+}
+
+func (r LifecycleRule) matches(tags []string, os, owner string) bool {
+	if len(r.Tags) > 0 && !anyEqualFold(r.Tags, tags) {
+		return false
+	}
+	if len(r.OS) > 0 && !anyEqualFold(r.OS, []string{os}) {
+		return false
+	}
+	if len(r.OwnerDomains) > 0 {
+		at := strings.LastIndex(owner, "@")
+		if at < 0 || !anyEqualFold(r.OwnerDomains, []string{owner[at+1:]}) {
+			return false
+		}
+	}
+	return true
+}
+
+// StaleThresholdDays returns the rule's stale threshold, or the default
+func (r LifecycleRule) StaleThresholdDays() int {
+	if r.StaleDays > 0 {
+		return r.StaleDays
+	}
+	return DefaultStaleDays
+}
+
+// AuthKeyThresholdDays returns the rule's auth key expiry limit, or the default
+func (r LifecycleRule) AuthKeyThresholdDays() int {
+	if r.AuthKeyExpiryDays > 0 {
+		return r.AuthKeyExpiryDays
+	}
+	return DefaultAuthKeyExpiryDays
+}
+
+// IsDefault returns true if no configured rule applied
+func (r LifecycleRule) IsDefault() bool {
+	return r.Name == DefaultLifecycleRuleName
+}
+
+func anyEqualFold(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(w, h) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLifecycleFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		content   string
+		wantRules int
+		wantErr   bool
+	}{
+		{
+			name: "hujson with comments",
+			content: `{
+  // CI runners are short-lived
+  "rules": [
+    {"name": "ci", "tags": ["tag:ci"], "staleDays": 1, "authKeyExpiryDays": 1},
+    {"name": "laptops", "os": ["macOS", "windows"], "staleDays": 90,},
+  ],
+}`,
+			wantRules: 2,
+		},
+		{
+			name:    "missing name",
+			content: `{"rules": [{"tags": ["tag:ci"], "staleDays": 1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "reserved name",
+			content: `{"rules": [{"name": "default", "tags": ["tag:ci"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "no match criteria",
+			content: `{"rules": [{"name": "all", "staleDays": 1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative threshold",
+			content: `{"rules": [{"name": "ci", "tags": ["tag:ci"], "staleDays": -1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid syntax",
+			content: `{"rules": [`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "lifecycle")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			policy, err := LoadLifecycleFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadLifecycleFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(policy.Rules) != tt.wantRules {
+				t.Errorf("got %d rules, want %d", len(policy.Rules), tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestLifecyclePolicyDeviceRule(t *testing.T) {
+	policy := &LifecyclePolicy{Rules: []LifecycleRule{
+		{Name: "ci", Tags: []string{"tag:ci"}, StaleDays: 1},
+		{Name: "servers", Tags: []string{"tag:server"}, StaleDays: 30},
+		{Name: "corp-laptops", OS: []string{"macOS", "windows"}, OwnerDomains: []string{"example.com"}, StaleDays: 90},
+	}}
+
+	tests := []struct {
+		name  string
+		tags  []string
+		os    string
+		owner string
+		want  string
+	}{
+		{"tag match", []string{"tag:web", "tag:server"}, "linux", "admin@example.com", "servers"},
+		{"first match wins", []string{"tag:ci", "tag:server"}, "linux", "", "ci"},
+		{"OS and domain match", nil, "MacOS", "alice@Example.com", "corp-laptops"},
+		{"domain mismatch", nil, "macOS", "bob@contractor.io", DefaultLifecycleRuleName},
+		{"no match", nil, "linux", "alice@example.com", DefaultLifecycleRuleName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.DeviceRule(tt.tags, tt.os, tt.owner).Name; got != tt.want {
+				t.Errorf("DeviceRule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var nilPolicy *LifecyclePolicy
+	if rule := nilPolicy.DeviceRule([]string{"tag:ci"}, "linux", ""); !rule.IsDefault() || rule.StaleThresholdDays() != DefaultStaleDays {
+		t.Errorf("nil policy should return the default rule, got %+v", rule)
+	}
+}
+
+func TestLifecyclePolicyAuthKeyRule(t *testing.T) {
+	policy := &LifecyclePolicy{Rules: []LifecycleRule{
+		{Name: "linux-ci", Tags: []string{"tag:ci"}, OS: []string{"linux"}, AuthKeyExpiryDays: 7},
+		{Name: "ci", Tags: []string{"tag:ci"}, AuthKeyExpiryDays: 1},
+	}}
+
+	// Rules that need device attributes are skipped for keys
+	rule := policy.AuthKeyRule([]string{"tag:ci"})
+	if rule.Name != "ci" || rule.AuthKeyThresholdDays() != 1 {
+		t.Errorf("AuthKeyRule() = %+v, want ci with 1 day", rule)
+	}
+	if rule := policy.AuthKeyRule(nil); rule.AuthKeyThresholdDays() != DefaultAuthKeyExpiryDays {
+		t.Errorf("untagged key should use the default, got %+v", rule)
+	}
+}
@@ -0,0 +1,219 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+const defaultNamingFileName = ".tailsnitch-naming"
+
+// DefaultDenyNamePatterns flag machine names that look like they carry
+// sensitive information. A naming policy adds to these; use its allow list
+// to exempt names that match them.
+var DefaultDenyNamePatterns = []string{
+	`(?i)(password|passwd|pwd|secret|token|key|cred)`,
+	`(?i)(prod|production|staging|dev)[-_]?(db|database|mysql|postgres|mongo|redis)`,
+	`(?i)(internal|private|confidential)`,
+	`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`, // IP addresses
+	`(?i)(admin|root|superuser)`,
+	`(?i)(api[-_]?key|access[-_]?key|auth[-_]?key)`,
+	`(?i)(ssn|social[-_]?security|credit[-_]?card)`,
+}
+
+var defaultDenyNameRegexps = compileAll(DefaultDenyNamePatterns)
+
+// NamingRule requires devices with any of Tags to have a machine name
+// matching Pattern
+type NamingRule struct {
+	Name    string   `json:"name"`
+	Tags    []string `json:"tags"`
+	Pattern string   `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// NamingPolicy configures which device names are acceptable. Deny patterns
+// are checked against the device name and OS hostname, in addition to
+// DefaultDenyNamePatterns; names matching an allow pattern are exempt from
+// them. Rules are evaluated in order and the first rule with a matching tag
+// applies. UserDevicePatterns add hostname patterns that identify laptops
+// and phones, for checks that treat those differently from servers.
+type NamingPolicy struct {
+	Deny               []string     `json:"deny,omitempty"`
+	Allow              []string     `json:"allow,omitempty"`
+	Rules              []NamingRule `json:"rules,omitempty"`
+	UserDevicePatterns []string     `json:"userDevicePatterns,omitempty"`
+
+	deny       []*regexp.Regexp
+	allow      []*regexp.Regexp
+	userDevice []*regexp.Regexp
+}
+
+// DefaultNamingFiles returns the paths to check for a naming policy, in order of priority
+func DefaultNamingFiles() []string {
+	paths := []string{
+		defaultNamingFileName, // Current directory
+	}
+
+	// Also check home directory
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, defaultNamingFileName))
+	}
+
+	return paths
+}
+
+// LoadNamingFile loads a naming policy from the given path.
+// The file is HuJSON (JSON with comments and trailing commas).
+func LoadNamingFile(path string) (*NamingPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	standardized, err := hujson.Standardize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy NamingPolicy
+	if err := json.Unmarshal(standardized, &policy); err != nil {
+		return nil, err
+	}
+
+	if policy.deny, err = compilePatterns("deny", policy.Deny); err != nil {
+		return nil, err
+	}
+	if policy.allow, err = compilePatterns("allow", policy.Allow); err != nil {
+		return nil, err
+	}
+	if policy.userDevice, err = compilePatterns("userDevicePatterns", policy.UserDevicePatterns); err != nil {
+		return nil, err
+	}
+
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if len(rule.Tags) == 0 {
+			return nil, fmt.Errorf("rule %q: at least one tag is required", rule.Name)
+		}
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("rule %q: pattern is required", rule.Name)
+		}
+		if rule.re, err = regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid pattern: %w", rule.Name, err)
+		}
+	}
+
+	return &policy, nil
+}
+
+// LoadNamingFiles tries to load a naming policy from default locations.
+// Returns nil and an empty path if none exists.
+func LoadNamingFiles() (*NamingPolicy, string, error) {
+	for _, path := range DefaultNamingFiles() {
+		if _, err := os.Stat(path); err == nil {
+			policy, err := LoadNamingFile(path)
+			if err != nil {
+				return nil, path, err
+			}
+			return policy, path, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// Violations returns why a device's names break the policy, one reason per
+// violated rule. Deny and allow patterns match against the device name and
+// OS hostname; rule patterns match against the machine name, the first
+// label of the device name. A nil policy applies only DefaultDenyNamePatterns.
+func (p *NamingPolicy) Violations(name, hostname string, tags []string) []string {
+	var reasons []string
+
+	if !p.allowed(name, hostname) {
+		deny := defaultDenyNameRegexps
+		if p != nil {
+			deny = append(append([]*regexp.Regexp{}, deny...), p.deny...)
+		}
+		for _, re := range deny {
+			if re.MatchString(name) || re.MatchString(hostname) {
+				reasons = append(reasons, fmt.Sprintf("matches deny pattern `%s`", re))
+				break
+			}
+		}
+	}
+
+	machineName, _, _ := strings.Cut(name, ".")
+	if rule, ok := p.TagRule(tags); ok && !rule.re.MatchString(machineName) {
+		reasons = append(reasons, fmt.Sprintf("does not match rule %q pattern `%s`", rule.Name, rule.Pattern))
+	}
+
+	return reasons
+}
+
+// TagRule returns the first rule with one of the given tags
+func (p *NamingPolicy) TagRule(tags []string) (NamingRule, bool) {
+	if p == nil {
+		return NamingRule{}, false
+	}
+	for _, rule := range p.Rules {
+		if anyEqualFold(rule.Tags, tags) {
+			return rule, true
+		}
+	}
+	return NamingRule{}, false
+}
+
+// IsUserDeviceHostname reports whether a hostname matches one of the
+// policy's user device patterns
+func (p *NamingPolicy) IsUserDeviceHostname(hostname string) bool {
+	if p == nil {
+		return false
+	}
+	for _, re := range p.userDevice {
+		if re.MatchString(hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *NamingPolicy) allowed(name, hostname string) bool {
+	if p == nil {
+		return false
+	}
+	for _, re := range p.allow {
+		if re.MatchString(name) || re.MatchString(hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func compilePatterns(field string, patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid pattern %q: %w", field, pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func compileAll(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i] = regexp.MustCompile(pattern)
+	}
+	return compiled
+}
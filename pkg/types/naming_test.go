@@ -0,0 +1,128 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeNamingFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "naming")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadNamingFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "hujson with comments",
+			content: `{
+  // Servers are named by site and number
+  "rules": [{"name": "servers", "tags": ["tag:server"], "pattern": "^srv-[a-z]+-\\d{2}$"},],
+  "deny": ["(?i)customer"],
+  "allow": ["^keycloak-"],
+  "userDevicePatterns": ["^lt-"],
+}`,
+		},
+		{
+			name:    "missing rule name",
+			content: `{"rules": [{"tags": ["tag:server"], "pattern": "^srv-"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing tags",
+			content: `{"rules": [{"name": "servers", "pattern": "^srv-"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing pattern",
+			content: `{"rules": [{"name": "servers", "tags": ["tag:server"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid rule pattern",
+			content: `{"rules": [{"name": "servers", "tags": ["tag:server"], "pattern": "("}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid deny pattern",
+			content: `{"deny": ["["]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadNamingFile(writeNamingFile(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadNamingFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNamingPolicyViolations(t *testing.T) {
+	policy, err := LoadNamingFile(writeNamingFile(t, `{
+  "rules": [{"name": "servers", "tags": ["tag:server"], "pattern": "^srv-[a-z]+-\\d{2}$"}],
+  "deny": ["(?i)customer"],
+  "allow": ["^keycloak-"],
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		policy   *NamingPolicy
+		devName  string
+		hostname string
+		tags     []string
+		want     []string
+	}{
+		{"nil policy clean", nil, "web-1.example.ts.net", "web-1", nil, nil},
+		{"nil policy default deny", nil, "vault-secret.example.ts.net", "vault", nil, []string{"deny pattern"}},
+		{"custom deny", policy, "customer-portal.example.ts.net", "portal", nil, []string{"`(?i)customer`"}},
+		{"allow exempts default deny", policy, "keycloak-admin.example.ts.net", "keycloak", nil, nil},
+		{"matches tag rule", policy, "srv-ams-01.example.ts.net", "srv-ams-01", []string{"tag:server"}, nil},
+		{"violates tag rule", policy, "web7.example.ts.net", "web7", []string{"tag:server"}, []string{`rule "servers"`}},
+		{"rule for other tags", policy, "web7.example.ts.net", "web7", []string{"tag:ci"}, nil},
+		{"deny and rule", policy, "root-box.example.ts.net", "root-box", []string{"tag:server"}, []string{"deny pattern", `rule "servers"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Violations(tt.devName, tt.hostname, tt.tags)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Violations() = %v, want %d reason(s)", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("reason %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNamingPolicyUserDeviceHostname(t *testing.T) {
+	var nilPolicy *NamingPolicy
+	if nilPolicy.IsUserDeviceHostname("lt-alice") {
+		t.Error("nil policy should not match")
+	}
+
+	policy, err := LoadNamingFile(writeNamingFile(t, `{"userDevicePatterns": ["^lt-"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !policy.IsUserDeviceHostname("lt-alice") || policy.IsUserDeviceHostname("srv-ams-01") {
+		t.Error("userDevicePatterns not applied")
+	}
+}
@@ -0,0 +1,325 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+const (
+	// DefaultPostureStaleDays is how old a posture timestamp attribute may be
+	// before the data is considered stale
+	DefaultPostureStaleDays = 7
+	defaultPostureFileName  = ".tailsnitch-posture"
+)
+
+// PostureRule requires the devices it matches to meet each of Conditions,
+// written in the tailnet policy file's posture syntax, for example
+// "falcon:ztaScore >= 50" or "custom:mdmEnrolled IS SET". A rule matches
+// devices with any of Tags or running one of OS; every matching rule applies.
+type PostureRule struct {
+	Name       string   `json:"name"`
+	Tags       []string `json:"tags,omitempty"`
+	OS         []string `json:"os,omitempty"`
+	Conditions []string `json:"conditions"`
+
+	conditions []PostureCondition
+}
+
+// PosturePolicy configures which posture attributes devices must report.
+// TimestampAttributes name attributes holding an RFC 3339 time of the last
+// posture sync or check-in; older than StaleDays means the data is stale.
+type PosturePolicy struct {
+	StaleDays           int           `json:"staleDays,omitempty"`
+	TimestampAttributes []string      `json:"timestampAttributes,omitempty"`
+	Rules               []PostureRule `json:"rules,omitempty"`
+}
+
+// PostureCondition is a single parsed posture condition
+type PostureCondition struct {
+	Attribute string
+	Op        string
+	Values    []string // One value, or the list for IN and NOT IN
+
+	raw string
+}
+
+// postureConditionRe splits "attr op value". Ops are tried longest first so
+// that <= isn't read as <.
+var postureConditionRe = regexp.MustCompile(`^\s*([A-Za-z0-9_.-]+:[A-Za-z0-9_.-]+)\s*(IS SET|NOT SET|NOT IN|IN|==|!=|<=|>=|<|>)\s*(.*?)\s*$`)
+
+// ParsePostureCondition parses a condition in posture syntax
+func ParsePostureCondition(s string) (PostureCondition, error) {
+	m := postureConditionRe.FindStringSubmatch(s)
+	if m == nil {
+		return PostureCondition{}, fmt.Errorf("invalid posture condition %q", s)
+	}
+	c := PostureCondition{Attribute: m[1], Op: m[2], raw: strings.TrimSpace(s)}
+
+	switch c.Op {
+	case "IS SET", "NOT SET":
+		if m[3] != "" {
+			return PostureCondition{}, fmt.Errorf("invalid posture condition %q: %s takes no value", s, c.Op)
+		}
+	case "IN", "NOT IN":
+		list := strings.TrimSpace(m[3])
+		if !strings.HasPrefix(list, "[") || !strings.HasSuffix(list, "]") {
+			return PostureCondition{}, fmt.Errorf("invalid posture condition %q: %s needs a list", s, c.Op)
+		}
+		for _, item := range strings.Split(list[1:len(list)-1], ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				c.Values = append(c.Values, unquotePostureValue(item))
+			}
+		}
+		if len(c.Values) == 0 {
+			return PostureCondition{}, fmt.Errorf("invalid posture condition %q: empty list", s)
+		}
+	default:
+		if m[3] == "" {
+			return PostureCondition{}, fmt.Errorf("invalid posture condition %q: missing value", s)
+		}
+		c.Values = []string{unquotePostureValue(m[3])}
+	}
+	return c, nil
+}
+
+func unquotePostureValue(v string) string {
+	if len(v) >= 2 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// String returns the condition as written
+func (c PostureCondition) String() string {
+	return c.raw
+}
+
+// NeedsAttribute reports whether a device must report the attribute to
+// meet the condition
+func (c PostureCondition) NeedsAttribute() bool {
+	return c.Op != "NOT SET"
+}
+
+// Eval reports whether an attribute value meets the condition. value is
+// the decoded JSON value, and present is false if the device doesn't report
+// the attribute. Numbers compare numerically and dotted versions such as
+// node:tsVersion compare component by component.
+func (c PostureCondition) Eval(value interface{}, present bool) bool {
+	switch c.Op {
+	case "IS SET":
+		return present
+	case "NOT SET":
+		return !present
+	}
+	if !present {
+		return false
+	}
+
+	have := fmt.Sprint(value)
+	switch c.Op {
+	case "==":
+		return have == c.Values[0]
+	case "!=":
+		return have != c.Values[0]
+	case "IN", "NOT IN":
+		found := false
+		for _, v := range c.Values {
+			if have == v {
+				found = true
+				break
+			}
+		}
+		return found == (c.Op == "IN")
+	}
+
+	cmp, ok := comparePostureValues(have, c.Values[0])
+	if !ok {
+		return false
+	}
+	switch c.Op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default: // >=
+		return cmp >= 0
+	}
+}
+
+// comparePostureValues orders two values as numbers or dotted versions.
+// Returns false if either is neither.
+func comparePostureValues(a, b string) (int, bool) {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+
+	av, aok := versionParts(a)
+	bv, bok := versionParts(b)
+	if !aok || !bok {
+		return 0, false
+	}
+	for i := 0; i < len(av) || i < len(bv); i++ {
+		var x, y int
+		if i < len(av) {
+			x = av[i]
+		}
+		if i < len(bv) {
+			y = bv[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// versionParts parses the leading dotted numbers of a version such as
+// "1.76.6-t1234" or "14.4.1"
+func versionParts(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+ "); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, len(parts) > 0
+}
+
+// DefaultPostureFiles returns the paths to check for a posture policy, in order of priority
+func DefaultPostureFiles() []string {
+	paths := []string{
+		defaultPostureFileName, // Current directory
+	}
+
+	// Also check home directory
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, defaultPostureFileName))
+	}
+
+	return paths
+}
+
+// LoadPostureFile loads a posture policy from the given path.
+// The file is HuJSON (JSON with comments and trailing commas).
+func LoadPostureFile(path string) (*PosturePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	standardized, err := hujson.Standardize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy PosturePolicy
+	if err := json.Unmarshal(standardized, &policy); err != nil {
+		return nil, err
+	}
+
+	if policy.StaleDays < 0 {
+		return nil, fmt.Errorf("staleDays must not be negative")
+	}
+
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if len(rule.Tags) == 0 && len(rule.OS) == 0 {
+			return nil, fmt.Errorf("rule %q: at least one of tags or os is required", rule.Name)
+		}
+		if len(rule.Conditions) == 0 {
+			return nil, fmt.Errorf("rule %q: at least one condition is required", rule.Name)
+		}
+		for _, s := range rule.Conditions {
+			c, err := ParsePostureCondition(s)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+			}
+			rule.conditions = append(rule.conditions, c)
+		}
+	}
+
+	return &policy, nil
+}
+
+// LoadPostureFiles tries to load a posture policy from default locations.
+// Returns nil and an empty path if none exists.
+func LoadPostureFiles() (*PosturePolicy, string, error) {
+	for _, path := range DefaultPostureFiles() {
+		if _, err := os.Stat(path); err == nil {
+			policy, err := LoadPostureFile(path)
+			if err != nil {
+				return nil, path, err
+			}
+			return policy, path, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// DeviceRules returns every rule matching a device's tags or OS. A nil
+// policy matches nothing.
+func (p *PosturePolicy) DeviceRules(tags []string, os string) []PostureRule {
+	if p == nil {
+		return nil
+	}
+	var rules []PostureRule
+	for _, rule := range p.Rules {
+		if anyEqualFold(rule.Tags, tags) || anyEqualFold(rule.OS, []string{os}) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// StaleThresholdDays returns the policy's stale threshold, or the default
+func (p *PosturePolicy) StaleThresholdDays() int {
+	if p != nil && p.StaleDays > 0 {
+		return p.StaleDays
+	}
+	return DefaultPostureStaleDays
+}
+
+// IsTimestampAttribute reports whether an attribute holds the time posture
+// data was last synced. Without configured TimestampAttributes, integration
+// and custom attributes named like "lastSeen" or "lastSyncDateTime" are used.
+func (p *PosturePolicy) IsTimestampAttribute(attr string) bool {
+	if p != nil && len(p.TimestampAttributes) > 0 {
+		return anyEqualFold(p.TimestampAttributes, []string{attr})
+	}
+	namespace, name, ok := strings.Cut(attr, ":")
+	return ok && namespace != "node" && strings.HasPrefix(strings.ToLower(name), "last")
+}
+
+// ParsedConditions returns the rule's conditions
+func (r PostureRule) ParsedConditions() []PostureCondition {
+	return r.conditions
+}
@@ -0,0 +1,143 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPostureConditionEval(t *testing.T) {
+	tests := []struct {
+		condition string
+		value     interface{}
+		present   bool
+		want      bool
+	}{
+		{"falcon:ztaScore >= 50", float64(72), true, true},
+		{"falcon:ztaScore >= 50", float64(12), true, false},
+		{"falcon:ztaScore>=50", float64(50), true, true},
+		{"intune:complianceState == 'compliant'", "compliant", true, true},
+		{"intune:complianceState == 'compliant'", "noncompliant", true, false},
+		{"sentinelOne:infected == false", false, true, true},
+		{"sentinelOne:infected == false", true, true, false},
+		{"node:os IN ['macos', 'windows']", "linux", true, false},
+		{"node:os NOT IN ['macos', 'windows']", "linux", true, true},
+		{"node:tsVersion >= '1.60'", "1.76.6-t1234", true, true},
+		{"node:tsVersion >= '1.60'", "1.58.2", true, false},
+		{"custom:mdmEnrolled IS SET", nil, false, false},
+		{"custom:quarantined NOT SET", nil, false, true},
+		{"falcon:ztaScore >= 50", nil, false, false},
+		{"kolide:authState > 5", "Good", true, false},
+	}
+	for _, tt := range tests {
+		c, err := ParsePostureCondition(tt.condition)
+		if err != nil {
+			t.Fatalf("ParsePostureCondition(%q) error = %v", tt.condition, err)
+		}
+		if got := c.Eval(tt.value, tt.present); got != tt.want {
+			t.Errorf("%q.Eval(%v, %v) = %v, want %v", tt.condition, tt.value, tt.present, got, tt.want)
+		}
+	}
+}
+
+func TestParsePostureConditionErrors(t *testing.T) {
+	for _, s := range []string{
+		"ztaScore >= 50",
+		"falcon:ztaScore >=",
+		"custom:x IS SET true",
+		"node:os IN 'macos'",
+		"node:os IN []",
+	} {
+		if _, err := ParsePostureCondition(s); err == nil {
+			t.Errorf("ParsePostureCondition(%q) should fail", s)
+		}
+	}
+}
+
+func TestLoadPostureFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "hujson with comments",
+			content: `{
+  // EDR must be healthy on production
+  "staleDays": 3,
+  "rules": [
+    {"name": "prod", "tags": ["tag:prod"], "conditions": ["falcon:ztaScore >= 50",]},
+    {"name": "laptops", "os": ["macOS"], "conditions": ["custom:mdmEnrolled IS SET"]},
+  ],
+}`,
+		},
+		{
+			name:    "missing name",
+			content: `{"rules": [{"tags": ["tag:prod"], "conditions": ["falcon:ztaScore >= 50"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "no tags or os",
+			content: `{"rules": [{"name": "prod", "conditions": ["falcon:ztaScore >= 50"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "no conditions",
+			content: `{"rules": [{"name": "prod", "tags": ["tag:prod"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid condition",
+			content: `{"rules": [{"name": "prod", "tags": ["tag:prod"], "conditions": ["ztaScore"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative staleDays",
+			content: `{"staleDays": -1}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "posture")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			policy, err := LoadPostureFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPostureFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := policy.StaleThresholdDays(); got != 3 {
+				t.Errorf("StaleThresholdDays() = %d, want 3", got)
+			}
+			if rules := policy.DeviceRules([]string{"tag:prod"}, "linux"); len(rules) != 1 || len(rules[0].ParsedConditions()) != 1 {
+				t.Errorf("DeviceRules(tag:prod) = %+v", rules)
+			}
+			if rules := policy.DeviceRules(nil, "macOS"); len(rules) != 1 || rules[0].Name != "laptops" {
+				t.Errorf("DeviceRules(macOS) = %+v", rules)
+			}
+		})
+	}
+}
+
+func TestPostureTimestampAttributes(t *testing.T) {
+	var p *PosturePolicy
+	if !p.IsTimestampAttribute("intune:lastSyncDateTime") {
+		t.Error("integration last* attributes should be timestamps by default")
+	}
+	if p.IsTimestampAttribute("node:lastSeen") || p.IsTimestampAttribute("falcon:ztaScore") {
+		t.Error("node attributes and scores should not be timestamps")
+	}
+	if p.StaleThresholdDays() != DefaultPostureStaleDays {
+		t.Errorf("nil policy StaleThresholdDays() = %d", p.StaleThresholdDays())
+	}
+
+	p = &PosturePolicy{TimestampAttributes: []string{"custom:checkedIn"}}
+	if !p.IsTimestampAttribute("custom:checkedIn") || p.IsTimestampAttribute("intune:lastSyncDateTime") {
+		t.Error("configured timestamp attributes should replace the defaults")
+	}
+}
@@ -59,12 +59,16 @@ func NewCheckRegistry() *CheckRegistry {
 		{ID: "ACL-008", Title: "No groups defined in ACL policy", Category: AccessControl, CCMappings: []string{"CC6.1", "CC6.2"}},
 		{ID: "ACL-009", Title: "Using legacy ACLs instead of grants", Category: AccessControl, CCMappings: []string{"CC6.1", "CC6.2"}},
 		{ID: "ACL-010", Title: "Taildrop file sharing configuration", Category: AccessControl, CCMappings: []string{"CC6.1", "CC6.2", "C1.1"}},
+		{ID: "ACL-011", Title: "Tailnet-wide network settings changed", Category: AccessControl, CCMappings: []string{"CC6.6"}},
+		{ID: "ACL-012", Title: "IP sets contain broad ranges or undefined references", Category: AccessControl, CCMappings: []string{"CC6.1", "CC6.6"}},
 
 		// Auth checks - CC6.1 (Logical Access), CC6.2 (Access Control), CC6.3 (Access Removal)
 		{ID: "AUTH-001", Title: "Reusable auth keys exist", Category: Authentication, CCMappings: []string{"CC6.1", "CC6.2", "CC6.3"}},
 		{ID: "AUTH-002", Title: "Auth keys with long expiry period", Category: Authentication, CCMappings: []string{"CC6.1", "CC6.2", "CC6.3"}},
 		{ID: "AUTH-003", Title: "Pre-authorized auth keys bypass device approval", Category: Authentication, CCMappings: []string{"CC6.1", "CC6.2"}},
 		{ID: "AUTH-004", Title: "Non-ephemeral keys may be used for CI/CD", Category: Authentication, CCMappings: []string{"CC6.1", "CC6.2", "CC6.3"}},
+		{ID: "AUTH-005", Title: "Devices enrolled by auth keys, or their tags, are untraceable", Category: Authentication, CCMappings: []string{"CC6.1", "CC6.2"}},
+		{ID: "AUTH-006", Title: "API access tokens with long lifetimes or removed creators", Category: Authentication, CCMappings: []string{"CC6.1", "CC6.2", "CC6.3"}},
 
 		// Device checks - CC6.1 (Logical Access), CC6.3 (Access Removal), CC7.1 (System Operations)
 		{ID: "DEV-001", Title: "Tagged devices with key expiry disabled", Category: DeviceSecurity, CCMappings: []string{"CC6.1", "CC6.3"}},
@@ -80,6 +84,10 @@ func NewCheckRegistry() *CheckRegistry {
 		{ID: "DEV-011", Title: "Unique users in tailnet", Category: DeviceSecurity, CCMappings: []string{"CC6.1"}},
 		{ID: "DEV-012", Title: "Nodes awaiting Tailnet Lock signature", Category: DeviceSecurity, CCMappings: []string{"CC6.1", "CC7.1"}},
 		{ID: "DEV-013", Title: "User devices with key expiry disabled", Category: DeviceSecurity, CCMappings: []string{"CC6.1", "CC6.3"}},
+		{ID: "DEV-014", Title: "Devices running end-of-life operating systems", Category: DeviceSecurity, CCMappings: []string{"CC6.1", "CC7.1"}},
+		{ID: "DEV-015", Title: "Devices missing expected posture attributes", Category: DeviceSecurity, CCMappings: []string{"CC6.1", "CC6.6"}},
+		{ID: "DEV-016", Title: "Stale device posture data", Category: DeviceSecurity, CCMappings: []string{"CC7.1"}},
+		{ID: "DEV-017", Title: "Devices failing posture conditions", Category: DeviceSecurity, CCMappings: []string{"CC6.1", "CC6.6", "CC7.1"}},
 
 		// Network checks - CC6.6 (Boundary Protection), CC6.7 (Transmission Protection)
 		{ID: "NET-001", Title: "Funnel exposes services to public internet", Category: NetworkExposure, CCMappings: []string{"CC6.6", "CC6.7"}},
@@ -89,30 +97,54 @@ func NewCheckRegistry() *CheckRegistry {
 		{ID: "NET-005", Title: "Exit nodes can see all internet traffic", Category: NetworkExposure, CCMappings: []string{"CC6.6", "CC6.7", "C1.1"}},
 		{ID: "NET-006", Title: "Tailscale Serve exposes services on tailnet", Category: NetworkExposure, CCMappings: []string{"CC6.6"}},
 		{ID: "NET-007", Title: "App connectors provide SaaS access", Category: NetworkExposure, CCMappings: []string{"CC6.6", "CC6.7"}},
+		{ID: "NET-008", Title: "Custom DERP nodes skip TLS verification", Category: NetworkExposure, CCMappings: []string{"CC6.7"}},
+		{ID: "NET-009", Title: "Custom DERP map lacks redundancy", Category: NetworkExposure, CCMappings: []string{"CC7.1"}},
+		{ID: "NET-010", Title: "Custom DERP regions with STUN-only nodes", Category: NetworkExposure, CCMappings: []string{"CC7.1"}},
+		{ID: "NET-011", Title: "Custom DERP hostnames outside trusted domains", Category: NetworkExposure, CCMappings: []string{"CC6.6", "CC6.7"}},
+		{ID: "NET-012", Title: "Infrastructure devices fail role hygiene", Category: NetworkExposure, CCMappings: []string{"CC6.1", "CC6.6"}},
 
 		// SSH checks - CC6.1 (Logical Access), CC6.6 (Boundary), CC7.2 (Monitoring)
 		{ID: "SSH-001", Title: "SSH session recording not enforced", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC7.2"}},
 		{ID: "SSH-002", Title: "High-risk SSH access without check mode", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC6.6", "CC7.2"}},
 		{ID: "SSH-003", Title: "Session recorder UI may be exposed", Category: SSHSecurity, CCMappings: []string{"CC6.6", "CC7.2"}},
 		{ID: "SSH-004", Title: "Tailscale SSH configuration", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC6.6"}},
+		{ID: "SSH-005", Title: "Users with effective root SSH access", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC6.2"}},
+		{ID: "SSH-006", Title: "SSH session recorders missing or unhealthy", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC7.2"}},
+		{ID: "SSH-007", Title: "SSH check mode period too long", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC6.2"}},
+		{ID: "SSH-008", Title: "SSH acceptEnv allows dangerous environment variables", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC6.8"}},
+		{ID: "SSH-009", Title: "Sensitive SSH rules have no sshTests", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC6.2"}},
+		{ID: "SSH-010", Title: "sshTests assertions fail against current SSH rules", Category: SSHSecurity, CCMappings: []string{"CC6.1", "CC6.2"}},
 
 		// Logging/Admin checks - CC7.1 (System Operations), CC7.2 (Monitoring), CC7.3 (Evaluation)
 		{ID: "LOG-001", Title: "Network flow logs configuration", Category: LoggingAdmin, CCMappings: []string{"CC7.1", "CC7.2"}},
 		{ID: "LOG-002", Title: "Log streaming for long-term retention", Category: LoggingAdmin, CCMappings: []string{"CC7.1", "CC7.2", "CC7.3"}},
 		{ID: "LOG-003", Title: "Audit log limitations", Category: LoggingAdmin, CCMappings: []string{"CC7.1", "CC7.2"}},
 		{ID: "LOG-004", Title: "Failed login monitoring via IdP", Category: LoggingAdmin, CCMappings: []string{"CC7.1", "CC7.2"}},
-		{ID: "LOG-005", Title: "Webhook secrets never expire", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.3"}},
-		{ID: "LOG-006", Title: "OAuth clients persist after user removal", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.3"}},
+		{ID: "LOG-005", Title: "Webhook secrets not rotated or sent in plaintext", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.3"}},
+		{ID: "LOG-006", Title: "OAuth clients with full write scope or removed creators", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.3"}},
 		{ID: "LOG-007", Title: "SCIM API keys never expire", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.3"}},
 		{ID: "LOG-008", Title: "Passkey-authenticated backup admin", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.2"}},
 		{ID: "LOG-009", Title: "MFA enforcement in identity provider", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.2"}},
 		{ID: "LOG-010", Title: "DNS rebinding attack protection", Category: LoggingAdmin, CCMappings: []string{"CC6.6", "CC7.1"}},
 		{ID: "LOG-011", Title: "Security contact email configuration", Category: LoggingAdmin, CCMappings: []string{"CC7.1", "CC7.3"}},
 		{ID: "LOG-012", Title: "Webhooks for critical events", Category: LoggingAdmin, CCMappings: []string{"CC7.1", "CC7.2"}},
-		{ID: "USER-001", Title: "Review user roles and ownership", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.2", "CC6.3"}},
+		{ID: "USER-001", Title: "Too many owners and admins", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.2", "CC6.3"}},
+		{ID: "USER-002", Title: "Inactive privileged accounts", Category: LoggingAdmin, CCMappings: []string{"CC6.2", "CC6.3"}},
+		{ID: "USER-003", Title: "Suspended users still own devices", Category: LoggingAdmin, CCMappings: []string{"CC6.2", "CC6.3"}},
+		{ID: "USER-004", Title: "External users with privileged roles", Category: LoggingAdmin, CCMappings: []string{"CC6.1", "CC6.2"}},
+		{ID: "USER-005", Title: "Privileged accounts with no devices", Category: LoggingAdmin, CCMappings: []string{"CC6.2", "CC6.3"}},
 
 		// DNS checks - CC6.6 (Boundary Protection)
 		{ID: "DNS-001", Title: "MagicDNS configuration", Category: DNSConfiguration, CCMappings: []string{"CC6.6"}},
+
+		// Local node checks (tailsnitch local) - CC6.1 (Logical Access), CC6.6 (Boundary Protection), CC7.1 (System Operations)
+		{ID: "LOCAL-001", Title: "Subnet routes accepted on a server", Category: LocalNode, CCMappings: []string{"CC6.6"}},
+		{ID: "LOCAL-002", Title: "Shields up disabled on a user device", Category: LocalNode, CCMappings: []string{"CC6.6"}},
+		{ID: "LOCAL-003", Title: "Tailscale SSH server enabled", Category: LocalNode, CCMappings: []string{"CC6.1"}},
+		{ID: "LOCAL-004", Title: "Automatic updates disabled", Category: LocalNode, CCMappings: []string{"CC7.1"}},
+		{ID: "LOCAL-005", Title: "Exit node in use on a server", Category: LocalNode, CCMappings: []string{"CC6.6"}},
+		{ID: "LOCAL-006", Title: "Non-root operator can control tailscaled", Category: LocalNode, CCMappings: []string{"CC6.1", "CC6.3"}},
+		{ID: "LOCAL-007", Title: "Services exposed with Serve or Funnel", Category: LocalNode, CCMappings: []string{"CC6.6", "CC6.7"}},
 	}
 
 	// Generate slugs and build lookup maps
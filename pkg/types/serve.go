@@ -0,0 +1,133 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ServeSnapshot is one node's Serve configuration as written by
+// `tailsnitch serve --snapshot`, for collecting from a fleet of nodes
+type ServeSnapshot struct {
+	Node        string          `json:"node"`
+	CollectedAt time.Time       `json:"collected_at"`
+	ServeConfig json.RawMessage `json:"serve_config"` // `tailscale serve status --json` format
+}
+
+// ServeEndpoint is one port or path a node exposes with Serve or Funnel
+type ServeEndpoint struct {
+	Node             string `json:"node"`
+	Protocol         string `json:"protocol"` // "https", "http" or "tcp"
+	Host             string `json:"host,omitempty"`
+	Port             uint16 `json:"port"`
+	Path             string `json:"path,omitempty"`    // Mount point for web handlers
+	Handler          string `json:"handler"`           // "proxy", "path", "text" or "tcp"
+	Backend          string `json:"backend,omitempty"` // Proxy target, file path or TCP forward address
+	TerminateTLS     string `json:"terminate_tls,omitempty"`
+	Funnel           bool   `json:"funnel"`
+	PlaintextBackend bool   `json:"plaintext_backend"`       // Forwarded unencrypted to another host
+	AdminService     string `json:"admin_service,omitempty"` // Admin or data service the backend looks like
+	Foreground       bool   `json:"foreground,omitempty"`    // From a foreground `tailscale serve` session
+}
+
+// URL returns where the endpoint listens, e.g. "https://web.example.ts.net:443/app" or "tcp:5432"
+func (e ServeEndpoint) URL() string {
+	if e.Protocol == "tcp" {
+		return fmt.Sprintf("tcp:%d", e.Port)
+	}
+	return fmt.Sprintf("%s://%s:%d%s", e.Protocol, e.Host, e.Port, e.Path)
+}
+
+// String describes the endpoint, its backend and any risk flags
+func (e ServeEndpoint) String() string {
+	s := e.URL() + " -> "
+	switch e.Handler {
+	case "proxy":
+		s += "proxy " + e.Backend
+	case "path":
+		s += "path " + e.Backend
+	case "tcp":
+		s += e.Backend
+		if e.TerminateTLS != "" {
+			s += fmt.Sprintf(" (TLS terminated for %s)", e.TerminateTLS)
+		}
+	default:
+		s += "static text"
+	}
+	if e.Funnel {
+		s += " [Funnel: public internet]"
+	}
+	if e.PlaintextBackend {
+		s += " [plaintext backend]"
+	}
+	if e.AdminService != "" {
+		s += fmt.Sprintf(" [admin: %s]", e.AdminService)
+	}
+	if e.Foreground {
+		s += " (foreground session)"
+	}
+	return s
+}
+
+// ServeNode lists the endpoints exposed by a single node
+type ServeNode struct {
+	Node        string          `json:"node"`
+	Source      string          `json:"source"` // Snapshot file or "LocalAPI"
+	CollectedAt *time.Time      `json:"collected_at,omitempty"`
+	Endpoints   []ServeEndpoint `json:"endpoints"`
+}
+
+// ServeInventorySummary contains aggregate statistics for the Serve inventory
+type ServeInventorySummary struct {
+	Nodes             int `json:"nodes"`              // Nodes reported
+	ExposingNodes     int `json:"exposing_nodes"`     // Nodes with at least one endpoint
+	Endpoints         int `json:"endpoints"`          // Total endpoints
+	Funnel            int `json:"funnel"`             // Endpoints public on the internet
+	PlaintextBackends int `json:"plaintext_backends"` // Endpoints forwarding unencrypted to another host
+	AdminBackends     int `json:"admin_backends"`     // Endpoints in front of admin or data services
+}
+
+// ServeInventoryReport is the Serve and Funnel exposure actually configured on a set of nodes
+type ServeInventoryReport struct {
+	GeneratedAt time.Time             `json:"generated_at"`
+	Summary     ServeInventorySummary `json:"summary"`
+	Nodes       []ServeNode           `json:"nodes"`
+	Errors      []string              `json:"errors,omitempty"` // Snapshots that could not be read
+}
+
+// CalculateSummary computes summary statistics and sorts nodes by name
+func (r *ServeInventoryReport) CalculateSummary() {
+	r.Summary = ServeInventorySummary{Nodes: len(r.Nodes)}
+
+	sort.SliceStable(r.Nodes, func(i, j int) bool {
+		return r.Nodes[i].Node < r.Nodes[j].Node
+	})
+
+	for _, n := range r.Nodes {
+		if len(n.Endpoints) > 0 {
+			r.Summary.ExposingNodes++
+		}
+		for _, e := range n.Endpoints {
+			r.Summary.Endpoints++
+			if e.Funnel {
+				r.Summary.Funnel++
+			}
+			if e.PlaintextBackend {
+				r.Summary.PlaintextBackends++
+			}
+			if e.AdminService != "" {
+				r.Summary.AdminBackends++
+			}
+		}
+	}
+}
+
+// Endpoints returns the endpoints of every node
+func (r *ServeInventoryReport) Endpoints() []ServeEndpoint {
+	var endpoints []ServeEndpoint
+	for _, n := range r.Nodes {
+		endpoints = append(endpoints, n.Endpoints...)
+	}
+	return endpoints
+}
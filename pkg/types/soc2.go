@@ -41,6 +41,7 @@ type SOC2Summary struct {
 type SOC2Report struct {
 	Tailnet     string            `json:"tailnet"`
 	GeneratedAt time.Time         `json:"generated_at"`
+	AsOf        *time.Time        `json:"as_of,omitempty"` // Date tests were evaluated at, if not GeneratedAt
 	Summary     SOC2Summary       `json:"summary"`
 	Tests       []SOC2ControlTest `json:"tests"`
 }
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// SSHAccessGrant describes one local user a person can log in as on one device
+type SSHAccessGrant struct {
+	DeviceID        string   `json:"device_id"`
+	Device          string   `json:"device"`
+	DeviceTags      []string `json:"device_tags,omitempty"`
+	LocalUser       string   `json:"local_user"` // e.g. "root", "ubuntu", "autogroup:nonroot"
+	Root            bool     `json:"root"`
+	RequiresCheck   bool     `json:"requires_check"`         // Every granting rule uses check mode
+	CheckPeriod     string   `json:"check_period,omitempty"` // Longest check period among granting rules
+	Recorded        bool     `json:"recorded"`               // Every granting rule has a recorder
+	EnforceRecorder bool     `json:"enforce_recorder"`       // Every granting rule enforces recording
+	Rules           []int    `json:"rules"`                  // 1-based SSH rule numbers granting access
+}
+
+// SSHUserAccess lists everything a single human user can SSH into
+type SSHUserAccess struct {
+	User   string           `json:"user"`
+	Grants []SSHAccessGrant `json:"grants"`
+}
+
+// SSHAccessSummary contains aggregate statistics for the SSH access report
+type SSHAccessSummary struct {
+	Users              int `json:"users"`              // Users with any SSH access
+	Grants             int `json:"grants"`             // Total user/device/local-user combinations
+	RootGrants         int `json:"root_grants"`        // Grants allowing login as root
+	UnrecordedGrants   int `json:"unrecorded_grants"`  // Grants with no session recording
+	RootWithoutCheck   int `json:"root_without_check"` // Root grants that don't require check mode
+	UsersWithRootCount int `json:"users_with_root"`    // Users who can log in as root anywhere
+}
+
+// SSHAccessReport is the effective SSH access matrix for a tailnet
+type SSHAccessReport struct {
+	Tailnet     string           `json:"tailnet"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Summary     SSHAccessSummary `json:"summary"`
+	Users       []SSHUserAccess  `json:"users"`
+	Unresolved  []string         `json:"unresolved,omitempty"` // Selectors that could not be expanded
+}
+
+// CalculateSummary computes summary statistics and sorts users and grants
+func (r *SSHAccessReport) CalculateSummary() {
+	r.Summary = SSHAccessSummary{}
+
+	sort.Slice(r.Users, func(i, j int) bool {
+		return r.Users[i].User < r.Users[j].User
+	})
+
+	for _, u := range r.Users {
+		sort.Slice(u.Grants, func(i, j int) bool {
+			if u.Grants[i].Device != u.Grants[j].Device {
+				return u.Grants[i].Device < u.Grants[j].Device
+			}
+			return u.Grants[i].LocalUser < u.Grants[j].LocalUser
+		})
+
+		if len(u.Grants) > 0 {
+			r.Summary.Users++
+		}
+
+		hasRoot := false
+		for _, g := range u.Grants {
+			r.Summary.Grants++
+			if !g.Recorded {
+				r.Summary.UnrecordedGrants++
+			}
+			if g.Root {
+				hasRoot = true
+				r.Summary.RootGrants++
+				if !g.RequiresCheck {
+					r.Summary.RootWithoutCheck++
+				}
+			}
+		}
+		if hasRoot {
+			r.Summary.UsersWithRootCount++
+		}
+	}
+}